@@ -0,0 +1,138 @@
+// Package atlas packs many images into a few large pages.
+// It has no GL dependencies, so it's shared by the runtime atlas builder and the asset tools.
+package atlas
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+)
+
+type Sprite struct {
+	Name  string
+	Image image.Image
+}
+
+// Region is the placement of a sprite on a page, without padding and extrusion.
+type Region struct {
+	Name string
+	Page int
+	Rect image.Rectangle
+}
+
+type Options struct {
+	PageWidth  int
+	PageHeight int
+	// Padding is the empty space left between sprites
+	Padding int
+	// Extrude repeats the sprite edge pixels around it, avoiding bleeding when sampling with filtering
+	Extrude int
+}
+
+type Atlas struct {
+	Pages   []*image.RGBA
+	Regions []Region
+}
+
+// Pack places the sprites on as many pages as needed.
+// Regions are returned in the order of the sprites.
+func Pack(sprites []Sprite, options Options) (*Atlas, error) {
+	if options.PageWidth <= 0 || options.PageHeight <= 0 {
+		return nil, fmt.Errorf("invalid atlas page size: %dx%d", options.PageWidth, options.PageHeight)
+	}
+
+	border := options.Extrude*2 + options.Padding
+
+	// packing the tallest sprites first gives a flatter skyline
+	order := make([]int, len(sprites))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := sprites[order[a]].Image.Bounds().Size(), sprites[order[b]].Image.Bounds().Size()
+		if sa.Y != sb.Y {
+			return sa.Y > sb.Y
+		}
+		return sa.X > sb.X
+	})
+
+	result := &Atlas{
+		Pages:   make([]*image.RGBA, 0),
+		Regions: make([]Region, len(sprites)),
+	}
+	packers := make([]*SkylinePacker, 0)
+
+	for _, index := range order {
+		sprite := sprites[index]
+		size := sprite.Image.Bounds().Size()
+		cellWidth, cellHeight := size.X+border, size.Y+border
+
+		if cellWidth > options.PageWidth || cellHeight > options.PageHeight {
+			return nil, fmt.Errorf("sprite %q (%dx%d) does not fit the atlas page", sprite.Name, size.X, size.Y)
+		}
+
+		page, x, y := -1, 0, 0
+		for i, packer := range packers {
+			var ok bool
+			if x, y, ok = packer.Pack(cellWidth, cellHeight); ok {
+				page = i
+				break
+			}
+		}
+
+		if page < 0 {
+			packer := NewSkylinePacker(options.PageWidth, options.PageHeight)
+			x, y, _ = packer.Pack(cellWidth, cellHeight)
+			packers = append(packers, packer)
+			result.Pages = append(result.Pages, image.NewRGBA(image.Rect(0, 0, options.PageWidth, options.PageHeight)))
+			page = len(packers) - 1
+		}
+
+		rect := image.Rectangle{Min: image.Pt(x+options.Extrude, y+options.Extrude)}
+		rect.Max = rect.Min.Add(size)
+
+		Blit(result.Pages[page], rect, sprite.Image, options.Extrude)
+
+		result.Regions[index] = Region{
+			Name: sprite.Name,
+			Page: page,
+			Rect: rect,
+		}
+	}
+
+	return result, nil
+}
+
+// Blit draws the image into rect on the page, repeating its edge pixels extrude pixels outwards.
+func Blit(page *image.RGBA, rect image.Rectangle, img image.Image, extrude int) {
+	bounds := img.Bounds()
+	draw.Draw(page, rect, img, bounds.Min, draw.Src)
+
+	if extrude <= 0 || rect.Empty() {
+		return
+	}
+
+	outer := rect.Inset(-extrude).Intersect(page.Rect)
+	for y := outer.Min.Y; y < outer.Max.Y; y++ {
+		for x := outer.Min.X; x < outer.Max.X; x++ {
+			if (image.Point{X: x, Y: y}).In(rect) {
+				continue
+			}
+
+			sx := clamp(x, rect.Min.X, rect.Max.X-1)
+			sy := clamp(y, rect.Min.Y, rect.Max.Y-1)
+			page.SetRGBA(x, y, page.RGBAAt(sx, sy))
+		}
+	}
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
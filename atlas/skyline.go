@@ -0,0 +1,113 @@
+package atlas
+
+type skylineNode struct {
+	x, y, width int
+}
+
+// SkylinePacker places rectangles on a fixed size page using the skyline bottom-left heuristic.
+type SkylinePacker struct {
+	width   int
+	height  int
+	skyline []skylineNode
+}
+
+func NewSkylinePacker(width, height int) *SkylinePacker {
+	return &SkylinePacker{
+		width:   width,
+		height:  height,
+		skyline: []skylineNode{{x: 0, y: 0, width: width}},
+	}
+}
+
+// Pack reserves a w x h rectangle and returns its top left corner.
+func (p *SkylinePacker) Pack(w, h int) (x, y int, ok bool) {
+	bestIndex := -1
+	bestTop, bestWidth := 0, 0
+
+	for i := range p.skyline {
+		top, fits := p.fit(i, w, h)
+		if !fits {
+			continue
+		}
+
+		if bestIndex < 0 || top < bestTop || (top == bestTop && p.skyline[i].width < bestWidth) {
+			bestIndex = i
+			bestTop = top
+			bestWidth = p.skyline[i].width
+		}
+	}
+
+	if bestIndex < 0 {
+		return 0, 0, false
+	}
+
+	x = p.skyline[bestIndex].x
+	p.insert(bestIndex, skylineNode{x: x, y: bestTop + h, width: w})
+	return x, bestTop, true
+}
+
+// Reset clears the page.
+func (p *SkylinePacker) Reset() {
+	p.skyline = []skylineNode{{x: 0, y: 0, width: p.width}}
+}
+
+// fit returns the lowest y at which a w x h rectangle fits, starting at the given skyline node.
+func (p *SkylinePacker) fit(index, w, h int) (int, bool) {
+	x := p.skyline[index].x
+	if x+w > p.width {
+		return 0, false
+	}
+
+	top := 0
+	for remaining := w; remaining > 0; index++ {
+		if index >= len(p.skyline) {
+			return 0, false
+		}
+
+		if p.skyline[index].y > top {
+			top = p.skyline[index].y
+		}
+
+		if top+h > p.height {
+			return 0, false
+		}
+
+		remaining -= p.skyline[index].width
+	}
+
+	return top, true
+}
+
+func (p *SkylinePacker) insert(index int, node skylineNode) {
+	p.skyline = append(p.skyline, skylineNode{})
+	copy(p.skyline[index+1:], p.skyline[index:])
+	p.skyline[index] = node
+
+	// shrink or drop the nodes covered by the new one
+	for i := index + 1; i < len(p.skyline); {
+		previousEnd := p.skyline[i-1].x + p.skyline[i-1].width
+		if p.skyline[i].x >= previousEnd {
+			break
+		}
+
+		shrink := previousEnd - p.skyline[i].x
+		p.skyline[i].x += shrink
+		p.skyline[i].width -= shrink
+
+		if p.skyline[i].width > 0 {
+			break
+		}
+
+		p.skyline = append(p.skyline[:i], p.skyline[i+1:]...)
+	}
+
+	// merge neighbours at the same height
+	for i := 0; i < len(p.skyline)-1; {
+		if p.skyline[i].y == p.skyline[i+1].y {
+			p.skyline[i].width += p.skyline[i+1].width
+			p.skyline = append(p.skyline[:i+1], p.skyline[i+2:]...)
+		} else {
+			i++
+		}
+	}
+}
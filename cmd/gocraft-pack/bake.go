@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ddomurad/goCraft/atlas"
+	"github.com/ddomurad/goCraft/gctex"
+)
+
+type Baker struct {
+	baseDir string
+	pack    *PackWriter
+}
+
+func (b *Baker) Bake(manifest *Manifest) error {
+	for _, entry := range manifest.Textures {
+		if err := b.bakeTexture(entry); err != nil {
+			return fmt.Errorf("texture %q: %w", entry.Source, err)
+		}
+	}
+
+	for _, entry := range manifest.Shaders {
+		if err := b.bakeShader(entry); err != nil {
+			return fmt.Errorf("shader %q: %w", entry.Source, err)
+		}
+	}
+
+	for _, entry := range manifest.Atlases {
+		if err := b.bakeAtlas(entry); err != nil {
+			return fmt.Errorf("atlas %q: %w", entry.Name, err)
+		}
+	}
+
+	for _, entry := range manifest.Files {
+		data, err := b.readSource(entry.Source)
+		if err != nil {
+			return fmt.Errorf("file %q: %w", entry.Source, err)
+		}
+
+		if err = b.pack.Add(entryName(entry.Name, entry.Source), "file", data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Baker) bakeTexture(entry TextureEntry) error {
+	format, err := parseFormat(entry.Format)
+	if err != nil {
+		return err
+	}
+
+	img, err := b.readImage(entry.Source)
+	if err != nil {
+		return err
+	}
+
+	data, err := encodeTexture(img, format, entry.Mipmaps)
+	if err != nil {
+		return err
+	}
+
+	name := entry.Name
+	if name == "" {
+		name = strings.TrimSuffix(entryName("", entry.Source), path.Ext(entry.Source)) + gctex.Extension
+	}
+
+	return b.pack.Add(name, "texture", data)
+}
+
+func (b *Baker) bakeShader(entry ShaderEntry) error {
+	data, err := b.readSource(entry.Source)
+	if err != nil {
+		return err
+	}
+
+	src := string(data)
+	if err = LintShader(src); err != nil {
+		return err
+	}
+
+	if entry.Minify {
+		src = MinifyShader(src)
+	}
+
+	return b.pack.Add(entryName(entry.Name, entry.Source), "shader", []byte(src))
+}
+
+func (b *Baker) bakeAtlas(entry AtlasEntry) error {
+	if entry.Name == "" {
+		return fmt.Errorf("atlas has no name")
+	}
+
+	format, err := parseFormat(entry.Format)
+	if err != nil {
+		return err
+	}
+
+	sprites, err := b.readSprites(entry.Sprites)
+	if err != nil {
+		return err
+	}
+
+	packed, err := atlas.Pack(sprites, atlas.Options{
+		PageWidth:  defaultInt(entry.PageWidth, 1024),
+		PageHeight: defaultInt(entry.PageHeight, 1024),
+		Padding:    entry.Padding,
+		Extrude:    entry.Extrude,
+	})
+	if err != nil {
+		return err
+	}
+
+	for pageIndex, page := range packed.Pages {
+		pageName := fmt.Sprintf("%s-%d", entry.Name, pageIndex)
+
+		data, err := encodeTexture(page, format, entry.Mipmaps)
+		if err != nil {
+			return err
+		}
+
+		if err = b.pack.Add(pageName+gctex.Extension, "texture", data); err != nil {
+			return err
+		}
+
+		sheet := newSpriteSheet(path.Base(pageName+gctex.Extension), page.Rect.Size(), format)
+		for _, region := range packed.Regions {
+			if region.Page == pageIndex {
+				sheet.addFrame(region)
+			}
+		}
+
+		sheetData, err := json.MarshalIndent(sheet, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err = b.pack.Add(pageName+".json", "sprite_sheet", sheetData); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Baker) readSprites(patterns []string) ([]atlas.Sprite, error) {
+	sprites := make([]atlas.Sprite, 0)
+	names := make(map[string]string)
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(b.baseDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no sprites match: %q", pattern)
+		}
+
+		sort.Strings(matches)
+		for _, match := range matches {
+			name := strings.TrimSuffix(filepath.Base(match), filepath.Ext(match))
+			if previous, ok := names[name]; ok {
+				return nil, fmt.Errorf("sprite name %q used by %q and %q", name, previous, match)
+			}
+			names[name] = match
+
+			img, err := decodeImageFile(match)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", match, err)
+			}
+
+			sprites = append(sprites, atlas.Sprite{
+				Name:  name,
+				Image: img,
+			})
+		}
+	}
+
+	return sprites, nil
+}
+
+func (b *Baker) readSource(source string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(b.baseDir, filepath.FromSlash(source)))
+}
+
+func (b *Baker) readImage(source string) (image.Image, error) {
+	return decodeImageFile(filepath.Join(b.baseDir, filepath.FromSlash(source)))
+}
+
+func decodeImageFile(filePath string) (image.Image, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	return img, err
+}
+
+func encodeTexture(img image.Image, format gctex.Format, mipmaps bool) ([]byte, error) {
+	tex, err := gctex.FromImage(img, format, mipmaps)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err = gctex.Encode(&buffer, tex); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func parseFormat(format string) (gctex.Format, error) {
+	switch strings.ToLower(format) {
+	case "", "rgba", "rgba8":
+		return gctex.FORMAT_RGBA8, nil
+	case "bc1", "dxt1":
+		return gctex.FORMAT_BC1, nil
+	case "bc3", "dxt5":
+		return gctex.FORMAT_BC3, nil
+	default:
+		return 0, fmt.Errorf("unsuported texture format: %q", format)
+	}
+}
+
+// entryName returns the archive name of an entry, defaulting to its source path.
+func entryName(name, source string) string {
+	if name != "" {
+		return name
+	}
+
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(source)), "/")
+}
+
+func defaultInt(value, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
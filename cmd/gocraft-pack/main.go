@@ -0,0 +1,64 @@
+// Command gocraft-pack bakes the assets listed in a manifest into a single zip archive,
+// ready to be mounted with core.VFS.MountArchive.
+//
+// Images are converted to gctex containers (raw RGBA8 or BC1/BC3 compressed, optionally with mipmaps),
+// shaders are validated and optionally minified, sprites are packed into atlases described in the
+// TexturePacker JSON hash format, and a content hashed index of every entry is written as "pack-index.json".
+//
+// Usage:
+//
+//	gocraft-pack -manifest assets.json [-o assets.pak] [-v]
+//
+// Paths in the manifest are relative to the manifest file. See Manifest for its format.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	manifestPath := flag.String("manifest", "assets.json", "asset manifest to bake")
+	outputPath := flag.String("o", "", "output archive, overrides the manifest output")
+	verbose := flag.Bool("v", false, "log every baked entry")
+	flag.Parse()
+
+	manifest, err := ReadManifest(*manifestPath)
+	if err != nil {
+		log.Fatalln("failed to read manifest:", err)
+	}
+
+	if *outputPath != "" {
+		manifest.Output = *outputPath
+	}
+
+	if manifest.Output == "" {
+		log.Fatalln("no output archive given")
+	}
+
+	output, err := os.Create(manifest.Output)
+	if err != nil {
+		log.Fatalln("failed to create output archive:", err)
+	}
+	defer output.Close()
+
+	pack := NewPackWriter(output, *verbose)
+	baker := Baker{
+		baseDir: filepath.Dir(*manifestPath),
+		pack:    pack,
+	}
+
+	if err = baker.Bake(manifest); err != nil {
+		os.Remove(manifest.Output)
+		log.Fatalln("failed to bake assets:", err)
+	}
+
+	if err = pack.Close(); err != nil {
+		os.Remove(manifest.Output)
+		log.Fatalln("failed to write output archive:", err)
+	}
+
+	log.Printf("PACKED! %d entries -> %q\n", len(pack.index), manifest.Output)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Manifest lists the assets to bake. Example:
+//
+//	{
+//	    "output": "assets.pak",
+//	    "textures": [{"source": "art/hero.png", "format": "bc3", "mipmaps": true}],
+//	    "shaders": [{"source": "shaders/sprite.glsl", "minify": true}],
+//	    "atlases": [{"name": "atlases/ui", "sprites": ["art/ui/*.png"], "pageWidth": 1024, "pageHeight": 1024, "padding": 2, "extrude": 1}],
+//	    "files": [{"source": "levels/level1.json"}]
+//	}
+type Manifest struct {
+	Output   string         `json:"output"`
+	Textures []TextureEntry `json:"textures"`
+	Shaders  []ShaderEntry  `json:"shaders"`
+	Atlases  []AtlasEntry   `json:"atlases"`
+	Files    []FileEntry    `json:"files"`
+}
+
+// TextureEntry bakes an image into a gctex container.
+// Name defaults to the source path with the ".gctex" extension.
+// Format is one of "rgba" (default), "bc1" or "bc3".
+type TextureEntry struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Format  string `json:"format"`
+	Mipmaps bool   `json:"mipmaps"`
+}
+
+// ShaderEntry validates a shader source. Name defaults to the source path.
+type ShaderEntry struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Minify bool   `json:"minify"`
+}
+
+// AtlasEntry packs the images matched by the Sprites glob patterns.
+// Every page is written as "<name>-<page>.gctex" with its "<name>-<page>.json" description.
+// Sprites are named after their file name, without the extension.
+type AtlasEntry struct {
+	Name       string   `json:"name"`
+	Sprites    []string `json:"sprites"`
+	PageWidth  int      `json:"pageWidth"`
+	PageHeight int      `json:"pageHeight"`
+	Padding    int      `json:"padding"`
+	Extrude    int      `json:"extrude"`
+	Format     string   `json:"format"`
+	Mipmaps    bool     `json:"mipmaps"`
+}
+
+// FileEntry copies a file as it is. Name defaults to the source path.
+type FileEntry struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+func ReadManifest(manifestPath string) (*Manifest, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
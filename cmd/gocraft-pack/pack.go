@@ -0,0 +1,103 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+)
+
+const indexName = "pack-index.json"
+
+type IndexEntry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Hash string `json:"sha256"`
+	Size int    `json:"size"`
+}
+
+type PackIndex struct {
+	Version int          `json:"version"`
+	Entries []IndexEntry `json:"entries"`
+}
+
+// PackWriter writes baked entries into a zip archive and records them in the index.
+type PackWriter struct {
+	zip     *zip.Writer
+	index   []IndexEntry
+	names   map[string]bool
+	verbose bool
+}
+
+func NewPackWriter(w io.Writer, verbose bool) *PackWriter {
+	return &PackWriter{
+		zip:     zip.NewWriter(w),
+		index:   make([]IndexEntry, 0),
+		names:   make(map[string]bool),
+		verbose: verbose,
+	}
+}
+
+func (p *PackWriter) Add(name string, entryType string, data []byte) error {
+	if name == indexName {
+		return fmt.Errorf("entry name is reserved: %q", name)
+	}
+
+	if p.names[name] {
+		return fmt.Errorf("duplicated entry: %q", name)
+	}
+
+	if err := p.write(name, data); err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(data)
+	p.names[name] = true
+	p.index = append(p.index, IndexEntry{
+		Name: name,
+		Type: entryType,
+		Hash: hex.EncodeToString(hash[:]),
+		Size: len(data),
+	})
+
+	if p.verbose {
+		log.Printf("BAKED! %s -> %q (%d bytes)\n", entryType, name, len(data))
+	}
+
+	return nil
+}
+
+// Close writes the index and finishes the archive.
+func (p *PackWriter) Close() error {
+	sort.Slice(p.index, func(i, j int) bool {
+		return p.index[i].Name < p.index[j].Name
+	})
+
+	data, err := json.MarshalIndent(PackIndex{
+		Version: 1,
+		Entries: p.index,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = p.write(indexName, data); err != nil {
+		return err
+	}
+
+	return p.zip.Close()
+}
+
+func (p *PackWriter) write(name string, data []byte) error {
+	w, err := p.zip.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func readPackEntry(t *testing.T, archive *zip.Reader, name string) []byte {
+	t.Helper()
+
+	file, err := archive.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestPackWriter(t *testing.T) {
+	var buffer bytes.Buffer
+	pack := NewPackWriter(&buffer, false)
+
+	entries := map[string][]byte{
+		"shaders/b.glsl": []byte("#version 330 core\n"),
+		"a.txt":          []byte("hello"),
+	}
+	for _, name := range []string{"shaders/b.glsl", "a.txt"} {
+		if err := pack.Add(name, "file", entries[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := pack.Add("a.txt", "file", nil); err == nil {
+		t.Error("expected an error for a duplicated entry")
+	}
+	if err := pack.Add(indexName, "file", nil); err == nil {
+		t.Error("expected an error for the reserved index name")
+	}
+
+	if err := pack.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var index PackIndex
+	if err = json.Unmarshal(readPackEntry(t, archive, indexName), &index); err != nil {
+		t.Fatal(err)
+	}
+
+	if index.Version != 1 || len(index.Entries) != 2 {
+		t.Fatalf("unexpected index: %+v", index)
+	}
+
+	// entries are sorted by name
+	if index.Entries[0].Name != "a.txt" || index.Entries[1].Name != "shaders/b.glsl" {
+		t.Fatalf("unexpected entries order: %+v", index.Entries)
+	}
+
+	for _, entry := range index.Entries {
+		data := readPackEntry(t, archive, entry.Name)
+		if !bytes.Equal(data, entries[entry.Name]) {
+			t.Errorf("%s: content differs", entry.Name)
+		}
+
+		hash := sha256.Sum256(data)
+		if entry.Hash != hex.EncodeToString(hash[:]) || entry.Size != len(data) {
+			t.Errorf("%s: wrong hash or size in the index", entry.Name)
+		}
+	}
+}
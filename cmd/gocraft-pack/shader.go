@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LintShader runs cheap checks possible without a GL context: a "#version" directive,
+// a main function and balanced brackets. It doesn't parse GLSL, syntax errors are
+// still only reported when the shader is compiled.
+func LintShader(src string) error {
+	code := stripComments(src)
+
+	if !strings.Contains(code, "#version") {
+		return errors.New("missing #version directive")
+	}
+
+	if !strings.Contains(code, "main") {
+		return errors.New("missing main function")
+	}
+
+	pairs := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	stack := make([]rune, 0)
+	line := 1
+
+	for _, c := range code {
+		switch c {
+		case '\n':
+			line++
+		case '(', '[', '{':
+			stack = append(stack, c)
+		case ')', ']', '}':
+			if len(stack) == 0 || stack[len(stack)-1] != pairs[c] {
+				return fmt.Errorf("line %d: unbalanced %q", line, c)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if len(stack) > 0 {
+		return fmt.Errorf("unclosed %q", stack[len(stack)-1])
+	}
+
+	return nil
+}
+
+// MinifyShader removes comments and redundant white space.
+// Preprocessor directives, including stage markers, are kept on their own lines.
+func MinifyShader(src string) string {
+	var out strings.Builder
+	var statement strings.Builder
+
+	flush := func() {
+		if statement.Len() > 0 {
+			out.WriteString(statement.String())
+			out.WriteByte('\n')
+			statement.Reset()
+		}
+	}
+
+	for _, line := range strings.Split(stripComments(src), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			flush()
+			out.WriteString(line)
+			out.WriteByte('\n')
+			continue
+		}
+
+		if statement.Len() > 0 {
+			statement.WriteByte(' ')
+		}
+		statement.WriteString(line)
+	}
+	flush()
+
+	return tightenPunctuation(out.String())
+}
+
+// tightenPunctuation drops spaces around punctuation that can't merge neighbouring tokens.
+// Preprocessor lines are kept as they are, "#define F (x)" and "#define F(x)" differ.
+func tightenPunctuation(src string) string {
+	const punctuation = "{}();,"
+
+	lines := strings.Split(src, "\n")
+	for l, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var out strings.Builder
+		runes := []rune(line)
+
+		for i, c := range runes {
+			if c == ' ' {
+				previous := i > 0 && strings.ContainsRune(punctuation, runes[i-1])
+				next := i+1 < len(runes) && strings.ContainsRune(punctuation, runes[i+1])
+				if previous || next {
+					continue
+				}
+			}
+			out.WriteRune(c)
+		}
+
+		lines[l] = out.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func stripComments(src string) string {
+	var out strings.Builder
+
+	for i := 0; i < len(src); i++ {
+		if strings.HasPrefix(src[i:], "//") {
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				break
+			}
+			i += end - 1
+			continue
+		}
+
+		if strings.HasPrefix(src[i:], "/*") {
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				break
+			}
+			// keep the line count for error messages
+			out.WriteString(strings.Repeat("\n", strings.Count(src[i:i+2+end], "\n")))
+			i += end + 3
+			continue
+		}
+
+		out.WriteByte(src[i])
+	}
+
+	return out.String()
+}
@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestMinifyShader(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			"comments and white space",
+			"#version 330 core\n// comment\nvoid main ( ) {\n  /* block\n comment */ gl_Position = vec4 ( 0.0 ) ;\n}\n",
+			"#version 330 core\nvoid main(){gl_Position = vec4(0.0);}\n",
+		},
+		{
+			"object like macro keeps its space",
+			"#version 330 core\n#define SCALE (2.0)\nvoid main() { float s = SCALE; }\n",
+			"#version 330 core\n#define SCALE (2.0)\nvoid main(){float s = SCALE;}\n",
+		},
+		{
+			"directives stay on their own lines",
+			"#version 330 core\nuniform float a;\n#ifdef FOO\nuniform float b;\n#endif\n",
+			"#version 330 core\nuniform float a;\n#ifdef FOO\nuniform float b;\n#endif\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if minified := MinifyShader(test.src); minified != test.expected {
+				t.Fatalf("minified to %q, expected %q", minified, test.expected)
+			}
+		})
+	}
+}
+
+func TestLintShader(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		valid bool
+	}{
+		{"valid", "#version 330 core\nvoid main() { if (true) { a[0] = 1; } }", true},
+		{"missing version", "void main() {}", false},
+		{"version in a comment", "// #version 330\nvoid main() {}", false},
+		{"missing main", "#version 330 core\nvoid foo() {}", false},
+		{"unbalanced", "#version 330 core\nvoid main() { a[0) = 1; }", false},
+		{"unclosed", "#version 330 core\nvoid main() {", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := LintShader(test.src)
+			if test.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
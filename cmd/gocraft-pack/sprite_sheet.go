@@ -0,0 +1,69 @@
+package main
+
+import (
+	"image"
+
+	"github.com/ddomurad/goCraft/atlas"
+	"github.com/ddomurad/goCraft/gctex"
+)
+
+// The atlas pages are described in the TexturePacker JSON hash format,
+// so they are read by the same loaders as sheets exported by the artists.
+
+type sheetRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type sheetSize struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type sheetFrame struct {
+	Frame            sheetRect `json:"frame"`
+	Rotated          bool      `json:"rotated"`
+	Trimmed          bool      `json:"trimmed"`
+	SpriteSourceSize sheetRect `json:"spriteSourceSize"`
+	SourceSize       sheetSize `json:"sourceSize"`
+}
+
+type sheetMeta struct {
+	App     string    `json:"app"`
+	Version string    `json:"version"`
+	Image   string    `json:"image"`
+	Format  string    `json:"format"`
+	Size    sheetSize `json:"size"`
+	Scale   string    `json:"scale"`
+}
+
+type spriteSheet struct {
+	Frames map[string]sheetFrame `json:"frames"`
+	Meta   sheetMeta             `json:"meta"`
+}
+
+func newSpriteSheet(imageName string, size image.Point, format gctex.Format) *spriteSheet {
+	return &spriteSheet{
+		Frames: make(map[string]sheetFrame),
+		Meta: sheetMeta{
+			App:     "gocraft-pack",
+			Version: "1",
+			Image:   imageName,
+			Format:  format.String(),
+			Size:    sheetSize{W: size.X, H: size.Y},
+			Scale:   "1",
+		},
+	}
+}
+
+func (s *spriteSheet) addFrame(region atlas.Region) {
+	w, h := region.Rect.Dx(), region.Rect.Dy()
+
+	s.Frames[region.Name] = sheetFrame{
+		Frame:            sheetRect{X: region.Rect.Min.X, Y: region.Rect.Min.Y, W: w, H: h},
+		SpriteSourceSize: sheetRect{X: 0, Y: 0, W: w, H: h},
+		SourceSize:       sheetSize{W: w, H: h},
+	}
+}
@@ -16,12 +16,15 @@ func init() {
 type Window struct {
 	Width  int
 	Height int
+	// SRGB is set when the framebuffer encodes the written linear colors to sRGB
+	SRGB bool
 
 	glfwWindow *glfw.Window
 }
 
 type App struct {
 	Window          Window
+	VFS             *VFS
 	EventManager    *EventManager
 	ResourceManager *ResourceManager
 	ShouldRun       bool
@@ -42,13 +45,19 @@ func InitApp(title string, width, height int, resizable bool, syncSwap bool) (ap
 	}
 
 	app = &App{}
+	app.VFS = NewVFS()
+	if err = app.VFS.MountDir("", "."); err != nil {
+		log.Println("failed to mount working directory:", err)
+	}
+
 	app.EventManager = NewEventManager(100)
 	app.EventManager.RegisterHandler(app)
-	app.ResourceManager = NewResourceManager()
+	app.ResourceManager = NewResourceManager().SetVFS(app.VFS)
 
 	glfw.WindowHint(glfw.Resizable, IfThenElse(resizable, glfw.True, glfw.False).(int))
 	glfw.WindowHint(glfw.ContextVersionMajor, 2)
 	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+	glfw.WindowHint(glfw.SRGBCapable, glfw.True)
 
 	app.Window = Window{
 		Width:  width,
@@ -96,6 +105,9 @@ func InitApp(title string, width, height int, resizable bool, syncSwap bool) (ap
 		log.Fatalln("failed to initialize GL:", err)
 	}
 
+	// filter across the cube map faces, it's a context wide state so it's set once here
+	gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+
 	glfw.SwapInterval(IfThenElse(syncSwap, 1, 0).(int))
 
 	app.ShouldRun = true
@@ -103,7 +115,19 @@ func InitApp(title string, width, height int, resizable bool, syncSwap bool) (ap
 	return
 }
 
+// SetSRGBFramebuffer enables the conversion of the linear colors written to the framebuffer to sRGB.
+func (a *App) SetSRGBFramebuffer(enabled bool) {
+	if enabled {
+		gl.Enable(gl.FRAMEBUFFER_SRGB)
+	} else {
+		gl.Disable(gl.FRAMEBUFFER_SRGB)
+	}
+
+	a.Window.SRGB = enabled
+}
+
 func (a *App) Close() {
+	a.VFS.Close()
 	glfw.Terminate()
 }
 
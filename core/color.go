@@ -0,0 +1,36 @@
+package core
+
+import "math"
+
+// SRGBToLinear converts an sRGB encoded channel value to linear light.
+func SRGBToLinear(value float32) float32 {
+	if value <= 0.04045 {
+		return value / 12.92
+	}
+
+	return float32(math.Pow((float64(value)+0.055)/1.055, 2.4))
+}
+
+// LinearToSRGB converts a linear light channel value to sRGB encoding.
+func LinearToSRGB(value float32) float32 {
+	if value <= 0.0031308 {
+		return value * 12.92
+	}
+
+	return float32(1.055*math.Pow(float64(value), 1/2.4) - 0.055)
+}
+
+// ToLinear converts the sRGB color to linear light, alpha is left as it is.
+func (c Color) ToLinear() Color {
+	return Color{SRGBToLinear(c[0]), SRGBToLinear(c[1]), SRGBToLinear(c[2]), c[3]}
+}
+
+// ToSRGB converts the linear light color to sRGB, alpha is left as it is.
+func (c Color) ToSRGB() Color {
+	return Color{LinearToSRGB(c[0]), LinearToSRGB(c[1]), LinearToSRGB(c[2]), c[3]}
+}
+
+// Premultiplied returns the color with the rgb channels multiplied by alpha.
+func (c Color) Premultiplied() Color {
+	return Color{c[0] * c[3], c[1] * c[3], c[2] * c[3], c[3]}
+}
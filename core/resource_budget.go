@@ -0,0 +1,158 @@
+package core
+
+import (
+	"fmt"
+	"log"
+)
+
+type ResourceTypeStats struct {
+	Count   int
+	Evicted int
+	Bytes   int64
+}
+
+type ResourceStats struct {
+	Bytes  int64
+	Budget int64
+	ByType map[ResourceType]ResourceTypeStats
+}
+
+// SetBudget limits the estimated memory used by the loaded resources.
+// When the budget is exceeded the least recently used, unpinned resources are evicted.
+// They are reloaded on demand the next time they are requested. Zero disables the budget.
+func (r *ResourceManager) SetBudget(bytes int64) *ResourceManager {
+	r.budget = bytes
+	r.enforceBudget("")
+	return r
+}
+
+// Pin protects the resource from being evicted.
+func (r *ResourceManager) Pin(uri string) *ResourceManager {
+	return r.setPinned(uri, true)
+}
+
+func (r *ResourceManager) Unpin(uri string) *ResourceManager {
+	return r.setPinned(uri, false)
+}
+
+func (r *ResourceManager) Stats() ResourceStats {
+	stats := ResourceStats{
+		Bytes:  r.usedBytes,
+		Budget: r.budget,
+		ByType: make(map[ResourceType]ResourceTypeStats),
+	}
+
+	for _, entry := range r.resources {
+		typeStats := stats.ByType[entry.resource.Type]
+		typeStats.Count++
+
+		if entry.evicted {
+			typeStats.Evicted++
+		} else {
+			typeStats.Bytes += entry.resource.Size
+		}
+
+		stats.ByType[entry.resource.Type] = typeStats
+	}
+
+	return stats
+}
+
+// Evict unloads the resource, keeping it registered so it's reloaded on the next request.
+func (r *ResourceManager) Evict(uri string) error {
+	entry, ok := r.resources[uri]
+	if !ok {
+		return fmt.Errorf("resource not found: %q", uri)
+	}
+
+	if !entry.evictable() {
+		return fmt.Errorf("resource can't be evicted: %q", uri)
+	}
+
+	r.evict(uri, entry)
+	return nil
+}
+
+// UpdateSize changes the estimated size of a resource whose storage changed after it was loaded,
+// like a grown dynamic mesh, and enforces the budget with the new size.
+func (r *ResourceManager) UpdateSize(uri string, size int64) error {
+	entry, ok := r.resources[uri]
+	if !ok {
+		return fmt.Errorf("resource not found: %q", uri)
+	}
+
+	if !entry.evicted {
+		r.usedBytes += size - entry.resource.Size
+	}
+
+	entry.resource.Size = size
+	r.enforceBudget(uri)
+	return nil
+}
+
+func (r *ResourceManager) setPinned(uri string, pinned bool) *ResourceManager {
+	entry, ok := r.resources[uri]
+	if !ok {
+		log.Printf("FAILED! can't change pinning of a missing resource: %q\n", uri)
+		return r
+	}
+
+	entry.pinned = pinned
+	return r
+}
+
+func (r *ResourceManager) enforceBudget(keepUri string) {
+	for r.budget > 0 && r.usedBytes > r.budget {
+		var lruUri string
+		var lruEntry *resourceEntry
+
+		for uri, entry := range r.resources {
+			if uri == keepUri || !entry.evictable() {
+				continue
+			}
+
+			if lruEntry == nil || entry.lastUsed < lruEntry.lastUsed {
+				lruUri = uri
+				lruEntry = entry
+			}
+		}
+
+		if lruEntry == nil {
+			return
+		}
+
+		r.evict(lruUri, lruEntry)
+	}
+}
+
+func (r *ResourceManager) evict(uri string, entry *resourceEntry) {
+	if entry.resource.Unload != nil {
+		entry.resource.Unload()
+	}
+
+	entry.evicted = true
+	r.usedBytes -= entry.resource.Size
+	log.Printf("EVICTED! resource evicted: %s -> %q\n", entry.resource.Type, uri)
+}
+
+func (r *ResourceManager) restore(uri string, entry *resourceEntry) {
+	for _, dependency := range entry.dependencies {
+		r.GetResource(dependency)
+	}
+
+	rsc, err := entry.loader.Load(uri, entry.param)
+	if err != nil {
+		log.Printf("FAILED! reloading of evicted resource failed: %s(%v). %q\n", entry.resource.Type, entry.param, err)
+		rsc = r.substitute(entry.resource.Type, uri, rsc)
+	}
+
+	entry.resource = rsc
+	entry.evicted = false
+	r.usedBytes += rsc.Size
+	r.enforceBudget(uri)
+}
+
+func (e *resourceEntry) evictable() bool {
+	return !e.pinned && !e.evicted && e.loader != nil && !e.resource.Empty && !e.resource.Volatile &&
+		e.resource.Size > 0 && len(e.dependents) == 0
+}
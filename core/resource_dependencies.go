@@ -0,0 +1,243 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Dependency describes a resource required by another resource.
+// If the dependency isn't loaded yet it's loaded using Type and Param.
+// A nil Param means the dependency must already be registered.
+type Dependency struct {
+	Type  ResourceType
+	Uri   string
+	Param LoaderParam
+}
+
+// DependentLoader is implemented by loaders of resources built on top of other resources,
+// like materials, sprite sheets or fonts. Dependencies are loaded before the resource itself.
+type DependentLoader interface {
+	ResourceLoader
+	Dependencies(uri string, param LoaderParam) []Dependency
+}
+
+func (r *ResourceManager) Dependencies(uri string) []string {
+	entry, ok := r.resources[uri]
+	if !ok {
+		return nil
+	}
+
+	dependencies := make([]string, len(entry.dependencies))
+	copy(dependencies, entry.dependencies)
+	return dependencies
+}
+
+func (r *ResourceManager) Dependents(uri string) []string {
+	entry, ok := r.resources[uri]
+	if !ok {
+		return nil
+	}
+
+	dependents := make([]string, 0, len(entry.dependents))
+	for dependent := range entry.dependents {
+		dependents = append(dependents, dependent)
+	}
+
+	sort.Strings(dependents)
+	return dependents
+}
+
+// UnloadResource unloads a single resource.
+// It fails if the resource is still used by other resources.
+func (r *ResourceManager) UnloadResource(uri string) error {
+	entry, ok := r.resources[uri]
+	if !ok {
+		return fmt.Errorf("resource not found: %q", uri)
+	}
+
+	if len(entry.dependents) > 0 {
+		return fmt.Errorf("resource %q is still used by: %s", uri, strings.Join(r.Dependents(uri), ", "))
+	}
+
+	if !entry.evicted {
+		if entry.resource.Unload != nil {
+			entry.resource.Unload()
+		}
+		r.usedBytes -= entry.resource.Size
+	}
+
+	r.unlink(uri, entry)
+	r.releaseSlot(entry.handle)
+	delete(r.resources, uri)
+	return nil
+}
+
+// Reload loads the resource again with its original loader and params,
+// then reloads all resources depending on it, dependencies first.
+// If the resource fails to load the previous version is kept.
+func (r *ResourceManager) Reload(uri string) error {
+	entry, ok := r.resources[uri]
+	if !ok {
+		return fmt.Errorf("resource not found: %q", uri)
+	}
+
+	if err := r.reloadEntry(uri, entry); err != nil {
+		return err
+	}
+
+	r.reloadDependents(uri)
+	return nil
+}
+
+func (r *ResourceManager) reloadDependents(uri string) {
+	for _, dependent := range r.dependentsOrder(uri) {
+		if err := r.reloadEntry(dependent, r.resources[dependent]); err != nil {
+			log.Printf("FAILED! reloading of dependent resource failed: %q. %q\n", dependent, err)
+		}
+	}
+}
+
+func (r *ResourceManager) reloadEntry(uri string, entry *resourceEntry) error {
+	if entry.loader == nil {
+		return fmt.Errorf("resource has no loader: %q", uri)
+	}
+
+	dependencies, err := r.loadDependencies(entry.scope, entry.loader, uri, entry.param, map[string]bool{uri: true})
+	if err != nil {
+		return err
+	}
+
+	rsc, err := entry.loader.Load(uri, entry.param)
+	if err != nil {
+		return err
+	}
+
+	if !entry.evicted {
+		if entry.resource.Unload != nil {
+			entry.resource.Unload()
+		}
+		r.usedBytes -= entry.resource.Size
+	}
+
+	entry.resource = rsc
+	entry.evicted = false
+	r.usedBytes += rsc.Size
+
+	r.unlink(uri, entry)
+	r.link(uri, dependencies)
+
+	log.Printf("RELOADED! resource reloaded: %s(%v) -> %q\n", rsc.Type, entry.param, uri)
+	return nil
+}
+
+func (r *ResourceManager) loadDependencies(scope *ResourceScope, loader ResourceLoader, uri string, param LoaderParam, loading map[string]bool) ([]string, error) {
+	dependentLoader, ok := loader.(DependentLoader)
+	if !ok {
+		return nil, nil
+	}
+
+	dependencies := dependentLoader.Dependencies(uri, param)
+	uris := make([]string, 0, len(dependencies))
+
+	for _, dependency := range dependencies {
+		dependencyUri, found := r.lookup(scope, dependency.Uri)
+
+		if loading[dependencyUri] {
+			return nil, fmt.Errorf("dependency cycle detected: %q -> %q", uri, dependency.Uri)
+		}
+
+		if found {
+			// make sure evicted dependencies are back before the dependent gets loaded
+			r.GetResource(dependencyUri)
+		} else {
+			if dependency.Param == nil {
+				return nil, fmt.Errorf("dependency %q of %q is not loaded", dependency.Uri, uri)
+			}
+
+			if scope != nil {
+				scope.uris[dependency.Uri] = struct{}{}
+			}
+
+			if err := r.preload(scope, dependency.Type, dependencyUri, dependency.Param, loading); err != nil {
+				return nil, fmt.Errorf("dependency %q of %q failed to load: %w", dependency.Uri, uri, err)
+			}
+		}
+
+		uris = append(uris, dependencyUri)
+	}
+
+	return uris, nil
+}
+
+func (r *ResourceManager) link(uri string, dependencies []string) {
+	entry := r.resources[uri]
+	entry.dependencies = dependencies
+
+	for _, dependency := range dependencies {
+		if dependencyEntry, ok := r.resources[dependency]; ok {
+			dependencyEntry.dependents[uri] = struct{}{}
+		}
+	}
+}
+
+func (r *ResourceManager) unlink(uri string, entry *resourceEntry) {
+	for _, dependency := range entry.dependencies {
+		if dependencyEntry, ok := r.resources[dependency]; ok {
+			delete(dependencyEntry.dependents, uri)
+		}
+	}
+
+	entry.dependencies = nil
+}
+
+// dependentsOrder returns all direct and indirect dependents of the resource,
+// ordered so every resource comes after the resources it depends on.
+func (r *ResourceManager) dependentsOrder(uri string) []string {
+	visited := map[string]bool{uri: true}
+	order := make([]string, 0)
+
+	var visit func(uri string)
+	visit = func(uri string) {
+		for dependent := range r.resources[uri].dependents {
+			if !visited[dependent] {
+				visited[dependent] = true
+				visit(dependent)
+				order = append(order, dependent)
+			}
+		}
+	}
+	visit(uri)
+
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+
+	return order
+}
+
+// unloadOrder returns all resources ordered so dependents come before their dependencies.
+func (r *ResourceManager) unloadOrder() []string {
+	visited := make(map[string]bool)
+	order := make([]string, 0, len(r.resources))
+
+	var visit func(uri string)
+	visit = func(uri string) {
+		visited[uri] = true
+		for dependent := range r.resources[uri].dependents {
+			if !visited[dependent] {
+				visit(dependent)
+			}
+		}
+		order = append(order, uri)
+	}
+
+	for uri := range r.resources {
+		if !visited[uri] {
+			visit(uri)
+		}
+	}
+
+	return order
+}
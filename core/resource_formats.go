@@ -0,0 +1,137 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// FormatHandler builds the loader param for a file handled by the resource manager's Load.
+// The options are the per call options, or the default options of the resource type (possibly nil).
+type FormatHandler func(filePath string, options LoaderParam) (LoaderParam, error)
+
+type resourceFormat struct {
+	resourceType ResourceType
+	handler      FormatHandler
+}
+
+const sniffLength = 512
+
+func (r *ResourceManager) SetVFS(vfs *VFS) *ResourceManager {
+	r.vfs = vfs
+	return r
+}
+
+func (r *ResourceManager) VFS() *VFS {
+	return r.vfs
+}
+
+// RegisterExtension registers a format handler for file extensions, like ".png".
+func (r *ResourceManager) RegisterExtension(resourceType ResourceType, handler FormatHandler, extensions ...string) *ResourceManager {
+	for _, extension := range extensions {
+		r.extensionFormats[strings.ToLower(extension)] = resourceFormat{resourceType, handler}
+	}
+
+	return r
+}
+
+// RegisterMimeType registers a format handler used for files whose sniffed content type matches,
+// when no handler is registered for their extension.
+func (r *ResourceManager) RegisterMimeType(resourceType ResourceType, handler FormatHandler, mimeTypes ...string) *ResourceManager {
+	for _, mimeType := range mimeTypes {
+		r.mimeFormats[mimeType] = resourceFormat{resourceType, handler}
+	}
+
+	return r
+}
+
+// SetDefaultOptions sets the options used by Load for a resource type when none are passed.
+func (r *ResourceManager) SetDefaultOptions(resourceType ResourceType, options LoaderParam) *ResourceManager {
+	r.defaultOptions[resourceType] = options
+	return r
+}
+
+// Load loads a file, inferring the resource type and the loader param from its extension or content.
+// The path becomes the resource uri. Already registered resources are returned as they are.
+func (r *ResourceManager) Load(filePath string, options ...LoaderParam) (Handle, error) {
+	if entry, ok := r.resources[filePath]; ok {
+		return entry.handle, nil
+	}
+
+	resourceType, param, err := r.resolveFormat(filePath, options)
+	if err != nil {
+		return Handle{}, err
+	}
+
+	return r.LoadResource(resourceType, filePath, param)
+}
+
+// Load is the scoped variant of ResourceManager.Load.
+func (s *ResourceScope) Load(filePath string, options ...LoaderParam) (Handle, error) {
+	if s.closed {
+		return Handle{}, ErrScopeClosed
+	}
+
+	if scopedUri, ok := s.Lookup(filePath); ok {
+		return s.manager.GetHandle(scopedUri)
+	}
+
+	resourceType, param, err := s.manager.resolveFormat(filePath, options)
+	if err != nil {
+		return Handle{}, err
+	}
+
+	return s.LoadResource(resourceType, filePath, param)
+}
+
+func (r *ResourceManager) resolveFormat(filePath string, options []LoaderParam) (ResourceType, LoaderParam, error) {
+	format, ok := r.extensionFormats[strings.ToLower(path.Ext(filePath))]
+	if !ok {
+		mimeType, err := r.sniff(filePath)
+		if err != nil {
+			return "", nil, err
+		}
+
+		format, ok = r.mimeFormats[mimeType]
+		if !ok {
+			return "", nil, fmt.Errorf("no format registred that could handle: %q (%s)", filePath, mimeType)
+		}
+	}
+
+	var opts LoaderParam
+	if len(options) > 0 {
+		opts = options[0]
+	} else {
+		opts = r.defaultOptions[format.resourceType]
+	}
+
+	param, err := format.handler(filePath, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return format.resourceType, param, nil
+}
+
+func (r *ResourceManager) sniff(filePath string) (string, error) {
+	if r.vfs == nil {
+		return "", fmt.Errorf("can't sniff content type without a VFS: %q", filePath)
+	}
+
+	file, err := r.vfs.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	mimeType := http.DetectContentType(header[:n])
+	return strings.TrimSpace(strings.Split(mimeType, ";")[0]), nil
+}
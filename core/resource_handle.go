@@ -0,0 +1,107 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrStaleHandle = errors.New("stale resource handle")
+
+// Handle is a cheap, copyable reference to a registered resource.
+// It resolves in constant time and detects use after the resource was unloaded.
+// The zero Handle is invalid.
+type Handle struct {
+	index      uint32
+	generation uint32
+}
+
+type resourceSlot struct {
+	entry      *resourceEntry
+	generation uint32
+}
+
+func (h Handle) IsValid() bool {
+	return h.generation != 0
+}
+
+// LoadResource returns the handle of the resource, loading it first if it's not registered yet.
+func (r *ResourceManager) LoadResource(resourceType ResourceType, uri string, param LoaderParam) (Handle, error) {
+	if entry, ok := r.resources[uri]; ok {
+		return entry.handle, nil
+	}
+
+	err := r.preload(nil, resourceType, uri, param, make(map[string]bool))
+	return r.resources[uri].handle, err
+}
+
+// ReplaceResource loads the resource, replacing the registered one (if any) while keeping its handle valid.
+// The replaced resource is unloaded and its dependents are reloaded.
+func (r *ResourceManager) ReplaceResource(resourceType ResourceType, uri string, param LoaderParam) (Handle, error) {
+	_, replaced := r.resources[uri]
+
+	err := r.preload(nil, resourceType, uri, param, make(map[string]bool))
+
+	if replaced {
+		r.reloadDependents(uri)
+	}
+
+	return r.resources[uri].handle, err
+}
+
+func (r *ResourceManager) GetHandle(uri string) (Handle, error) {
+	entry, ok := r.resources[uri]
+	if !ok {
+		return Handle{}, fmt.Errorf("resource not found: %q", uri)
+	}
+
+	return entry.handle, nil
+}
+
+// Resolve returns the resource referenced by the handle.
+// Like GetResource it marks the resource as used and reloads it if it was evicted.
+func (r *ResourceManager) Resolve(handle Handle) (*Resource, error) {
+	if !handle.IsValid() || int(handle.index) >= len(r.slots) {
+		return nil, ErrStaleHandle
+	}
+
+	slot := &r.slots[handle.index]
+	if slot.generation != handle.generation || slot.entry == nil {
+		return nil, ErrStaleHandle
+	}
+
+	entry := slot.entry
+	r.useCounter++
+	entry.lastUsed = r.useCounter
+
+	if entry.evicted {
+		r.restore(entry.resource.Uri, entry)
+	}
+
+	return &entry.resource, nil
+}
+
+func (r *ResourceManager) allocateSlot(entry *resourceEntry) Handle {
+	var index uint32
+	if len(r.freeSlots) > 0 {
+		index = r.freeSlots[len(r.freeSlots)-1]
+		r.freeSlots = r.freeSlots[:len(r.freeSlots)-1]
+	} else {
+		index = uint32(len(r.slots))
+		r.slots = append(r.slots, resourceSlot{})
+	}
+
+	slot := &r.slots[index]
+	slot.generation++
+	slot.entry = entry
+
+	return Handle{
+		index:      index,
+		generation: slot.generation,
+	}
+}
+
+func (r *ResourceManager) releaseSlot(handle Handle) {
+	slot := &r.slots[handle.index]
+	slot.entry = nil
+	r.freeSlots = append(r.freeSlots, handle.index)
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"fmt"
 	"log"
+	"reflect"
 )
 
 type LoaderParam interface{}
@@ -10,11 +11,15 @@ type ResourceType string
 
 type ResourceData interface{}
 type Resource struct {
-	Type   ResourceType
-	Uri    string
-	Data   ResourceData
-	Empty  bool
-	Unload func()
+	Type  ResourceType
+	Uri   string
+	Data  ResourceData
+	Empty bool
+	// Size is the estimated GPU memory used by the resource, in bytes
+	Size int64
+	// Volatile resources hold state that a reload can't restore, like dynamic textures, so they are never evicted
+	Volatile bool
+	Unload   func()
 }
 
 type ResourceLoader interface {
@@ -38,9 +43,39 @@ func (r EmptyResourceLoader) Unload(resource Resource) error {
 	return nil
 }
 
+// PlaceholderFactory creates a resource that is substituted for resources
+// of a given type that failed to load.
+type PlaceholderFactory func() (Resource, error)
+
+type resourceEntry struct {
+	resource     Resource
+	loader       ResourceLoader
+	param        LoaderParam
+	lastUsed     uint64
+	pinned       bool
+	evicted      bool
+	dependencies []string
+	dependents   map[string]struct{}
+	handle       Handle
+	scope        *ResourceScope
+}
+
 type ResourceManager struct {
-	resourceLoaders []ResourceLoader
-	resources       map[string]Resource
+	resourceLoaders      []ResourceLoader
+	resources            map[string]*resourceEntry
+	placeholderFactories map[ResourceType]PlaceholderFactory
+	placeholders         map[ResourceType]Resource
+	slots                []resourceSlot
+	freeSlots            []uint32
+	extensionFormats     map[string]resourceFormat
+	mimeFormats          map[string]resourceFormat
+	defaultOptions       map[ResourceType]LoaderParam
+	vfs                  *VFS
+	scopeCounter         uint32
+	scopes               map[uint32]*ResourceScope
+	useCounter           uint64
+	usedBytes            int64
+	budget               int64
 }
 
 func (r *ResourceManager) AddLoader(loader ResourceLoader) *ResourceManager {
@@ -58,40 +93,169 @@ func (r *ResourceManager) GetLoader(resourceType ResourceType, uri string, param
 	return EmptyResourceLoader{}, fmt.Errorf("no resource loader registred that could handle: \"%s/%v\"", resourceType, param)
 }
 
+func (r *ResourceManager) SetPlaceholder(resourceType ResourceType, factory PlaceholderFactory) *ResourceManager {
+	r.placeholderFactories[resourceType] = factory
+	return r
+}
+
+// GetPlaceholder returns the placeholder for the given resource type.
+// The placeholder is created on first use and shared by all failed resources.
+func (r *ResourceManager) GetPlaceholder(resourceType ResourceType) (Resource, bool) {
+	if rsc, ok := r.placeholders[resourceType]; ok {
+		return rsc, true
+	}
+
+	factory, ok := r.placeholderFactories[resourceType]
+	if !ok {
+		return Resource{}, false
+	}
+
+	rsc, err := factory()
+	if err != nil {
+		log.Printf("FAILED! placeholder creation failed: %s. %q\n", resourceType, err)
+		return Resource{}, false
+	}
+
+	r.placeholders[resourceType] = rsc
+	return rsc, true
+}
+
 func (r *ResourceManager) PreloadReource(resourceType ResourceType, uri string, param LoaderParam) *ResourceManager {
+	r.preload(nil, resourceType, uri, param, make(map[string]bool))
+	return r
+}
+
+// AddResource adds an already created resource, unloading the one it replaces.
+// Such resources have no loader, so they are never evicted.
+func (r *ResourceManager) AddResource(resource Resource) {
+	r.setEntry(resource.Uri, &resourceEntry{
+		resource: resource,
+	})
+	r.enforceBudget(resource.Uri)
+	r.reloadDependents(resource.Uri)
+}
+
+// GetResource returns the resource and marks it as recently used.
+// An evicted resource is reloaded with its original loader and params.
+func (r *ResourceManager) GetResource(resourceUri string) (Resource, error) {
+	entry, ok := r.resources[resourceUri]
+
+	if !ok {
+		return Resource{}, fmt.Errorf("resource not found: %q", resourceUri)
+	}
+
+	r.useCounter++
+	entry.lastUsed = r.useCounter
+
+	if entry.evicted {
+		r.restore(resourceUri, entry)
+	}
+
+	return entry.resource, nil
+}
+
+// preload loads the resource together with its dependencies, resolving them through the scope (if any).
+// The loading set holds the resources being loaded up the call chain and is used to detect dependency cycles.
+func (r *ResourceManager) preload(scope *ResourceScope, resourceType ResourceType, uri string, param LoaderParam, loading map[string]bool) error {
+	if loading[uri] {
+		err := fmt.Errorf("dependency cycle detected at: %q", uri)
+		log.Printf("FAILED! preloading of resource failed: %s(%v). %q\n", resourceType, param, err)
+		return err
+	}
+
+	loading[uri] = true
+	defer delete(loading, uri)
+
+	var rsc Resource
+	var dependencies []string
+
 	loader, err := r.GetLoader(resourceType, uri, param)
 	if err != nil {
-		log.Fatalf("FATAL! %s", err)
+		loader = nil
+	} else {
+		dependencies, err = r.loadDependencies(scope, loader, uri, param, loading)
 	}
 
-	rsc, err := loader.Load(uri, param)
+	if err == nil {
+		rsc, err = loader.Load(uri, param)
+	}
 
 	if err != nil {
 		log.Printf("FAILED! preloading of resource failed: %s(%v). %q\n", resourceType, param, err)
+		rsc = r.substitute(resourceType, uri, rsc)
+		dependencies = nil
 	}
 
-	// Add the resource inven if failed
-	r.resources[rsc.Uri] = rsc
+	// Add the resource even if failed, keeping the loader so it can be reloaded
+	r.setEntry(uri, &resourceEntry{
+		resource: rsc,
+		loader:   loader,
+		param:    param,
+		scope:    scope,
+	})
+	r.link(uri, dependencies)
+	r.enforceBudget(uri)
+
 	if !rsc.Empty {
 		log.Printf("LOADED! resource loaded: %s(%v) -> %q\n", resourceType, param, rsc.Uri)
 	} else {
 		log.Printf("LOADED! empty resource loaded: %s(%v) -> %q\n", resourceType, param, rsc.Uri)
 	}
 
-	return r
+	return err
 }
 
-func (r *ResourceManager) AddResource(resource Resource) {
-	r.resources[resource.Uri] = resource
+// setEntry registers the entry, unloading the entry it replaces unless both hold the same data.
+func (r *ResourceManager) setEntry(uri string, entry *resourceEntry) {
+	if old, ok := r.resources[uri]; ok {
+		if !old.evicted {
+			if old.resource.Unload != nil && !sameData(old.resource.Data, entry.resource.Data) {
+				old.resource.Unload()
+			}
+			r.usedBytes -= old.resource.Size
+		}
+		entry.pinned = old.pinned
+		entry.dependents = old.dependents
+		entry.handle = old.handle
+		r.slots[old.handle.index].entry = entry
+		r.unlink(uri, old)
+	} else {
+		entry.handle = r.allocateSlot(entry)
+	}
+
+	if entry.dependents == nil {
+		entry.dependents = make(map[string]struct{})
+	}
+
+	r.useCounter++
+	entry.lastUsed = r.useCounter
+	r.usedBytes += entry.resource.Size
+	r.resources[uri] = entry
+}
+
+func sameData(a, b ResourceData) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+
+	return a == b
 }
 
-func (r *ResourceManager) GetResource(resourceUri string) Resource {
-	rsc, ok := r.resources[resourceUri]
+func (r *ResourceManager) substitute(resourceType ResourceType, uri string, failed Resource) Resource {
+	if failed.Unload != nil {
+		failed.Unload()
+	}
 
+	rsc, ok := r.GetPlaceholder(resourceType)
 	if !ok {
-		log.Fatal("resource not found. At this point an empty resource should exist")
+		rsc = failed
 	}
 
+	rsc.Type = resourceType
+	rsc.Uri = uri
+	rsc.Empty = true
+	rsc.Unload = nil
+
 	return rsc
 }
 
@@ -103,18 +267,37 @@ func (r *ResourceManager) GetResource(resourceUri string) Resource {
 // }
 
 func (r *ResourceManager) Unload() {
-	for _, r := range r.resources {
+	for _, uri := range r.unloadOrder() {
+		entry := r.resources[uri]
+		if !entry.evicted && entry.resource.Unload != nil {
+			entry.resource.Unload()
+		}
+	}
+
+	for _, r := range r.placeholders {
 		if r.Unload != nil {
 			r.Unload()
 		}
 	}
 
-	r.resources = make(map[string]Resource)
+	for _, entry := range r.resources {
+		r.releaseSlot(entry.handle)
+	}
+
+	r.resources = make(map[string]*resourceEntry)
+	r.placeholders = make(map[ResourceType]Resource)
+	r.usedBytes = 0
 }
 
 func NewResourceManager() *ResourceManager {
 	return &ResourceManager{
-		resourceLoaders: make([]ResourceLoader, 0),
-		resources:       make(map[string]Resource),
+		resourceLoaders:      make([]ResourceLoader, 0),
+		resources:            make(map[string]*resourceEntry),
+		placeholderFactories: make(map[ResourceType]PlaceholderFactory),
+		placeholders:         make(map[ResourceType]Resource),
+		extensionFormats:     make(map[string]resourceFormat),
+		mimeFormats:          make(map[string]resourceFormat),
+		defaultOptions:       make(map[ResourceType]LoaderParam),
+		scopes:               make(map[uint32]*ResourceScope),
 	}
 }
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+var ErrScopeClosed = errors.New("resource scope is closed")
+
+// ResourceScope groups resources sharing a lifetime, like the assets of a single level.
+// Everything loaded through a scope is unloaded when the scope closes.
+// Scoped resources are registered in the manager under scope specific uris, so they may shadow
+// resources of the parent scopes. Lookups walk up the scope chain and end in the global namespace.
+type ResourceScope struct {
+	manager  *ResourceManager
+	parent   *ResourceScope
+	id       uint32
+	uris     map[string]struct{}
+	children map[*ResourceScope]struct{}
+	closed   bool
+}
+
+// NewScope creates a scope whose lookups fall back to the global namespace.
+func (r *ResourceManager) NewScope() *ResourceScope {
+	r.scopeCounter++
+
+	scope := &ResourceScope{
+		manager:  r,
+		id:       r.scopeCounter,
+		uris:     make(map[string]struct{}),
+		children: make(map[*ResourceScope]struct{}),
+	}
+	r.scopes[scope.id] = scope
+	return scope
+}
+
+// NewScope creates a child scope. The child is closed together with its parent.
+func (s *ResourceScope) NewScope() *ResourceScope {
+	child := s.manager.NewScope()
+	child.parent = s
+	s.children[child] = struct{}{}
+	return child
+}
+
+func (s *ResourceScope) Manager() *ResourceManager {
+	return s.manager
+}
+
+func (s *ResourceScope) Parent() *ResourceScope {
+	return s.parent
+}
+
+func (s *ResourceScope) PreloadReource(resourceType ResourceType, uri string, param LoaderParam) *ResourceScope {
+	if s.closed {
+		log.Printf("FAILED! preloading of resource failed: %s(%v). %q\n", resourceType, param, ErrScopeClosed)
+		return s
+	}
+
+	s.uris[uri] = struct{}{}
+	s.manager.preload(s, resourceType, s.qualify(uri), param, make(map[string]bool))
+	return s
+}
+
+// LoadResource returns the handle of the resource visible in the scope,
+// loading it into the scope first if it's not registered yet.
+func (s *ResourceScope) LoadResource(resourceType ResourceType, uri string, param LoaderParam) (Handle, error) {
+	if s.closed {
+		return Handle{}, ErrScopeClosed
+	}
+
+	if scopedUri, ok := s.Lookup(uri); ok {
+		return s.manager.GetHandle(scopedUri)
+	}
+
+	s.uris[uri] = struct{}{}
+	scopedUri := s.qualify(uri)
+	err := s.manager.preload(s, resourceType, scopedUri, param, make(map[string]bool))
+	handle, _ := s.manager.GetHandle(scopedUri)
+	return handle, err
+}
+
+// AddResource adds an already created resource to the scope under resource.Uri.
+func (s *ResourceScope) AddResource(resource Resource) error {
+	if s.closed {
+		return ErrScopeClosed
+	}
+
+	s.uris[resource.Uri] = struct{}{}
+	resource.Uri = s.qualify(resource.Uri)
+	s.manager.AddResource(resource)
+	return nil
+}
+
+// Lookup returns the manager uri of the resource visible from the scope.
+// The scope itself is searched first, then its parents and finally the global namespace.
+func (s *ResourceScope) Lookup(uri string) (string, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if scope.closed {
+			continue
+		}
+
+		if _, ok := scope.uris[uri]; ok {
+			scopedUri := scope.qualify(uri)
+			if _, ok := s.manager.resources[scopedUri]; ok {
+				return scopedUri, true
+			}
+		}
+	}
+
+	_, ok := s.manager.resources[uri]
+	return uri, ok
+}
+
+func (s *ResourceScope) GetResource(uri string) (Resource, error) {
+	scopedUri, ok := s.Lookup(uri)
+	if !ok {
+		return Resource{}, fmt.Errorf("resource not found: %q", uri)
+	}
+
+	return s.manager.GetResource(scopedUri)
+}
+
+func (s *ResourceScope) GetHandle(uri string) (Handle, error) {
+	scopedUri, ok := s.Lookup(uri)
+	if !ok {
+		return Handle{}, fmt.Errorf("resource not found: %q", uri)
+	}
+
+	return s.manager.GetHandle(scopedUri)
+}
+
+// Close closes the child scopes and unloads every resource loaded into the scope.
+// Resources still used by resources outside of the scope are kept and reported in the error,
+// along with the errors of the child scopes. The scope then stays open with the kept resources,
+// so they can still be looked up and the scope closed again once their dependents are gone.
+func (s *ResourceScope) Close() error {
+	if s.closed {
+		return nil
+	}
+
+	failed := make([]string, 0)
+	for child := range s.children {
+		if err := child.Close(); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+
+	owned := make(map[string]bool)
+	for uri := range s.uris {
+		owned[s.qualify(uri)] = true
+	}
+
+	for _, uri := range s.manager.unloadOrder() {
+		if !owned[uri] {
+			continue
+		}
+
+		if err := s.manager.UnloadResource(uri); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+
+	for uri := range s.uris {
+		if _, ok := s.manager.resources[s.qualify(uri)]; !ok {
+			delete(s.uris, uri)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("resource scope kept open with resources kept alive: %s", strings.Join(failed, "; "))
+	}
+
+	s.closed = true
+	delete(s.manager.scopes, s.id)
+	if s.parent != nil {
+		delete(s.parent.children, s)
+	}
+
+	return nil
+}
+
+func (s *ResourceScope) qualify(uri string) string {
+	return fmt.Sprintf("scope:%d/%s", s.id, uri)
+}
+
+// scopeOf returns the scope a manager uri was registered by, nil for the global namespace.
+func (r *ResourceManager) scopeOf(uri string) *ResourceScope {
+	if !strings.HasPrefix(uri, "scope:") {
+		return nil
+	}
+
+	end := strings.IndexByte(uri, '/')
+	if end < 0 {
+		return nil
+	}
+
+	id, err := strconv.ParseUint(uri[len("scope:"):end], 10, 32)
+	if err != nil {
+		return nil
+	}
+
+	return r.scopes[uint32(id)]
+}
+
+// ResolveDependency returns the manager uri of a dependency of the resource, resolved the same way
+// the dependencies were loaded: through the scope of the resource, if it belongs to one.
+// Loaders use it to fetch the dependencies they declared.
+func (r *ResourceManager) ResolveDependency(uri string, dependency string) string {
+	dependencyUri, _ := r.lookup(r.scopeOf(uri), dependency)
+	return dependencyUri
+}
+
+// lookup resolves the uri through the scope. If the resource isn't found,
+// the returned uri is where it should be registered.
+func (r *ResourceManager) lookup(scope *ResourceScope, uri string) (string, bool) {
+	if scope == nil {
+		_, ok := r.resources[uri]
+		return uri, ok
+	}
+
+	if scopedUri, ok := scope.Lookup(uri); ok {
+		return scopedUri, true
+	}
+
+	return scope.qualify(uri), false
+}
@@ -17,3 +17,14 @@ func MinOfUint(vars ...uint) (min uint) {
 
 	return
 }
+
+func MaxOfInt32(vars ...int32) (max int32) {
+	max = vars[0]
+	for _, i := range vars {
+		if max < i {
+			max = i
+		}
+	}
+
+	return
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type vfsMount struct {
+	point  string
+	fsys   fs.FS
+	closer io.Closer
+}
+
+// VFS is a layered, read only file system used by the resource loaders.
+// Every mount exposes a directory, an archive or any fs.FS under a mount point.
+// Mounts added later take precedence over the earlier ones, so a patch or a mod
+// can replace single assets without touching the base data.
+type VFS struct {
+	mounts []vfsMount
+}
+
+func NewVFS() *VFS {
+	return &VFS{
+		mounts: make([]vfsMount, 0),
+	}
+}
+
+func (v *VFS) MountDir(mountPoint string, dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("can't mount %q: not a directory", dir)
+	}
+
+	v.mount(mountPoint, os.DirFS(dir), nil)
+	return nil
+}
+
+// MountArchive mounts a zip archive. The archive extension does not matter,
+// so "*.pak" files packed as zip are handled the same way.
+func (v *VFS) MountArchive(mountPoint string, archivePath string) error {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+
+	v.mount(mountPoint, archive, archive)
+	return nil
+}
+
+func (v *VFS) MountFS(mountPoint string, fsys fs.FS) {
+	v.mount(mountPoint, fsys, nil)
+}
+
+// MountBaseFS mounts the file system below all the existing mounts, so it has the lowest precedence.
+// It's meant for built-in defaults, which the game data can override.
+func (v *VFS) MountBaseFS(mountPoint string, fsys fs.FS) {
+	v.mounts = append([]vfsMount{{point: cleanVfsPath(mountPoint), fsys: fsys}}, v.mounts...)
+}
+
+func (v *VFS) Unmount(mountPoint string) error {
+	mountPoint = cleanVfsPath(mountPoint)
+
+	var closeErr error
+	mounts := v.mounts[:0]
+	for _, m := range v.mounts {
+		if m.point != mountPoint {
+			mounts = append(mounts, m)
+			continue
+		}
+
+		if m.closer != nil {
+			if err := m.closer.Close(); err != nil && closeErr == nil {
+				closeErr = err
+			}
+		}
+	}
+
+	v.mounts = mounts
+	return closeErr
+}
+
+// Open implements fs.FS. Absolute OS paths, and relative ones leading out of the VFS root like "../data",
+// bypass the mounts and are opened directly.
+func (v *VFS) Open(name string) (fs.File, error) {
+	if filepath.IsAbs(name) {
+		return os.Open(name)
+	}
+
+	name = cleanVfsPath(name)
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return os.Open(filepath.FromSlash(name))
+	}
+
+	for i := len(v.mounts) - 1; i >= 0; i-- {
+		m := v.mounts[i]
+		rel, ok := m.resolve(name)
+		if !ok {
+			continue
+		}
+
+		file, err := m.fsys.Open(rel)
+		if err == nil {
+			return file, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (v *VFS) ReadFile(name string) ([]byte, error) {
+	file, err := v.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+func (v *VFS) Stat(name string) (fs.FileInfo, error) {
+	file, err := v.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return file.Stat()
+}
+
+func (v *VFS) Exists(name string) bool {
+	_, err := v.Stat(name)
+	return err == nil
+}
+
+func (v *VFS) Close() error {
+	var closeErr error
+	for _, m := range v.mounts {
+		if m.closer != nil {
+			if err := m.closer.Close(); err != nil && closeErr == nil {
+				closeErr = err
+			}
+		}
+	}
+
+	v.mounts = v.mounts[:0]
+	return closeErr
+}
+
+func (v *VFS) mount(mountPoint string, fsys fs.FS, closer io.Closer) {
+	v.mounts = append(v.mounts, vfsMount{
+		point:  cleanVfsPath(mountPoint),
+		fsys:   fsys,
+		closer: closer,
+	})
+}
+
+func (m vfsMount) resolve(name string) (string, bool) {
+	if m.point == "." {
+		return name, true
+	}
+
+	if name == m.point {
+		return ".", true
+	}
+
+	if strings.HasPrefix(name, m.point+"/") {
+		return name[len(m.point)+1:], true
+	}
+
+	return "", false
+}
+
+func cleanVfsPath(name string) string {
+	name = strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+	if name == "" {
+		return "."
+	}
+
+	return name
+}
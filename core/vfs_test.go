@@ -0,0 +1,121 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func mapFile(content string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(content)}
+}
+
+func TestVFSMountOrder(t *testing.T) {
+	vfs := NewVFS()
+	vfs.MountFS("", fstest.MapFS{
+		"a.txt":      mapFile("base a"),
+		"b.txt":      mapFile("base b"),
+		"data/c.txt": mapFile("base c"),
+	})
+	vfs.MountFS("", fstest.MapFS{
+		"a.txt": mapFile("patch a"),
+	})
+	vfs.MountFS("data", fstest.MapFS{
+		"c.txt": mapFile("mod c"),
+	})
+	vfs.MountBaseFS("", fstest.MapFS{
+		"a.txt": mapFile("default a"),
+		"d.txt": mapFile("default d"),
+	})
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"a.txt", "patch a"},
+		{"b.txt", "base b"},
+		{"data/c.txt", "mod c"},
+		{"./data/../data/c.txt", "mod c"},
+		{"d.txt", "default d"},
+	}
+
+	for _, test := range tests {
+		content, err := vfs.ReadFile(test.name)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if string(content) != test.expected {
+			t.Errorf("%s: read %q, expected %q", test.name, content, test.expected)
+		}
+	}
+
+	if _, err := vfs.Open("missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file opened with %v, expected fs.ErrNotExist", err)
+	}
+}
+
+func TestVFSUnmount(t *testing.T) {
+	vfs := NewVFS()
+	vfs.MountFS("", fstest.MapFS{"a.txt": mapFile("base")})
+	vfs.MountFS("mods", fstest.MapFS{"a.txt": mapFile("mod")})
+
+	if !vfs.Exists("mods/a.txt") {
+		t.Fatal("mods/a.txt not found")
+	}
+
+	if err := vfs.Unmount("mods/"); err != nil {
+		t.Fatal(err)
+	}
+
+	if vfs.Exists("mods/a.txt") {
+		t.Error("mods/a.txt found after unmounting")
+	}
+
+	if !vfs.Exists("a.txt") {
+		t.Error("a.txt not found after unmounting the other mount")
+	}
+}
+
+func TestVFSPathsOutsideOfMounts(t *testing.T) {
+	vfs := NewVFS()
+	vfs.MountFS("", fstest.MapFS{"go.mod": mapFile("mounted")})
+
+	path := filepath.Join(t.TempDir(), "absolute.txt")
+	if err := os.WriteFile(path, []byte("absolute"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := vfs.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "absolute" {
+		t.Errorf("absolute path read %q", content)
+	}
+
+	// tests run in the package directory, the module file is one level up
+	content, err = vfs.ReadFile("../go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(content), "module ") {
+		t.Errorf("relative path leading out of the root read %q", content)
+	}
+
+	content, err = vfs.ReadFile("data/../go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "mounted" {
+		t.Errorf("path staying inside of the root read %q, expected the mounted file", content)
+	}
+}
@@ -0,0 +1,179 @@
+package gctex
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// CompressBC1 encodes the image as BC1 (DXT1) blocks, dropping the alpha channel.
+func CompressBC1(img *image.RGBA) []byte {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	out := make([]byte, 0, FORMAT_BC1.LevelSize(w, h))
+
+	for by := 0; by < blockCount(h); by++ {
+		for bx := 0; bx < blockCount(w); bx++ {
+			block := readBlock(img, bx*4, by*4)
+			out = append(out, encodeColorBlock(&block)...)
+		}
+	}
+
+	return out
+}
+
+// CompressBC3 encodes the image as BC3 (DXT5) blocks, with interpolated alpha.
+func CompressBC3(img *image.RGBA) []byte {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	out := make([]byte, 0, FORMAT_BC3.LevelSize(w, h))
+
+	for by := 0; by < blockCount(h); by++ {
+		for bx := 0; bx < blockCount(w); bx++ {
+			block := readBlock(img, bx*4, by*4)
+			out = append(out, encodeAlphaBlock(&block)...)
+			out = append(out, encodeColorBlock(&block)...)
+		}
+	}
+
+	return out
+}
+
+// readBlock reads a 4x4 block, repeating the edge pixels for blocks crossing the image border.
+func readBlock(img *image.RGBA, x0, y0 int) (block [16][4]uint8) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+
+	for i := range block {
+		x := minInt(x0+i%4, w-1)
+		y := minInt(y0+i/4, h-1)
+		offset := y*img.Stride + x*4
+		copy(block[i][:], img.Pix[offset:offset+4])
+	}
+
+	return
+}
+
+func encodeColorBlock(block *[16][4]uint8) []byte {
+	minColor := [3]uint8{255, 255, 255}
+	maxColor := [3]uint8{0, 0, 0}
+
+	for _, pixel := range block {
+		for c := 0; c < 3; c++ {
+			minColor[c] = minUint8(minColor[c], pixel[c])
+			maxColor[c] = maxUint8(maxColor[c], pixel[c])
+		}
+	}
+
+	c0 := to565(maxColor)
+	c1 := to565(minColor)
+
+	out := make([]byte, 8)
+
+	if c0 == c1 {
+		// single color block, all indices point to c0
+		binary.LittleEndian.PutUint16(out[0:], c0)
+		binary.LittleEndian.PutUint16(out[2:], c1)
+		return out
+	}
+
+	if c0 < c1 {
+		// c0 > c1 selects the four color mode
+		c0, c1 = c1, c0
+	}
+
+	palette := [4][3]int{from565(c0), from565(c1)}
+	for c := 0; c < 3; c++ {
+		palette[2][c] = (2*palette[0][c] + palette[1][c]) / 3
+		palette[3][c] = (palette[0][c] + 2*palette[1][c]) / 3
+	}
+
+	var indices uint32
+	for i, pixel := range block {
+		best, bestDist := 0, -1
+		for p, color := range palette {
+			dist := 0
+			for c := 0; c < 3; c++ {
+				d := int(pixel[c]) - color[c]
+				dist += d * d
+			}
+
+			if bestDist < 0 || dist < bestDist {
+				best, bestDist = p, dist
+			}
+		}
+
+		indices |= uint32(best) << (2 * uint(i))
+	}
+
+	binary.LittleEndian.PutUint16(out[0:], c0)
+	binary.LittleEndian.PutUint16(out[2:], c1)
+	binary.LittleEndian.PutUint32(out[4:], indices)
+	return out
+}
+
+func encodeAlphaBlock(block *[16][4]uint8) []byte {
+	a0, a1 := uint8(0), uint8(255)
+	for _, pixel := range block {
+		a0 = maxUint8(a0, pixel[3])
+		a1 = minUint8(a1, pixel[3])
+	}
+
+	out := make([]byte, 8)
+	out[0], out[1] = a0, a1
+
+	if a0 == a1 {
+		return out
+	}
+
+	// a0 > a1 selects the eight value mode
+	var palette [8]int
+	palette[0], palette[1] = int(a0), int(a1)
+	for i := 1; i < 7; i++ {
+		palette[i+1] = ((7-i)*int(a0) + i*int(a1)) / 7
+	}
+
+	var indices uint64
+	for i, pixel := range block {
+		best, bestDist := 0, -1
+		for p, alpha := range palette {
+			dist := int(pixel[3]) - alpha
+			if dist < 0 {
+				dist = -dist
+			}
+
+			if bestDist < 0 || dist < bestDist {
+				best, bestDist = p, dist
+			}
+		}
+
+		indices |= uint64(best) << (3 * uint(i))
+	}
+
+	for i := 0; i < 6; i++ {
+		out[2+i] = uint8(indices >> (8 * uint(i)))
+	}
+
+	return out
+}
+
+func to565(c [3]uint8) uint16 {
+	return uint16(c[0]>>3)<<11 | uint16(c[1]>>2)<<5 | uint16(c[2]>>3)
+}
+
+func from565(c uint16) [3]int {
+	r := int(c>>11) & 0x1f
+	g := int(c>>5) & 0x3f
+	b := int(c) & 0x1f
+	return [3]int{r<<3 | r>>2, g<<2 | g>>4, b<<3 | b>>2}
+}
+
+func minUint8(a, b uint8) uint8 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxUint8(a, b uint8) uint8 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,169 @@
+package gctex
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// decodeColorBlock decodes a four color BC1 block into rgb triples.
+func decodeColorBlock(data []byte) [16][3]int {
+	c0 := binary.LittleEndian.Uint16(data[0:])
+	c1 := binary.LittleEndian.Uint16(data[2:])
+	indices := binary.LittleEndian.Uint32(data[4:])
+
+	palette := [4][3]int{from565(c0), from565(c1)}
+	for c := 0; c < 3; c++ {
+		palette[2][c] = (2*palette[0][c] + palette[1][c]) / 3
+		palette[3][c] = (palette[0][c] + 2*palette[1][c]) / 3
+	}
+
+	var out [16][3]int
+	for i := range out {
+		out[i] = palette[(indices>>(2*uint(i)))&3]
+	}
+	return out
+}
+
+func decodeAlphaBlock(data []byte) [16]int {
+	var palette [8]int
+	palette[0], palette[1] = int(data[0]), int(data[1])
+	for i := 1; i < 7; i++ {
+		palette[i+1] = ((7-i)*palette[0] + i*palette[1]) / 7
+	}
+
+	var indices uint64
+	for i := 0; i < 6; i++ {
+		indices |= uint64(data[2+i]) << (8 * uint(i))
+	}
+
+	var out [16]int
+	for i := range out {
+		out[i] = palette[(indices>>(3*uint(i)))&7]
+	}
+	return out
+}
+
+func gradientImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 255 / w), G: 128, B: uint8(y * 255 / h), A: uint8((x + y) * 255 / (w + h))})
+		}
+	}
+	return img
+}
+
+// rampImage has all the colors of a block on the line between its min and max color,
+// so the only encoding error is the quantization.
+func rampImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8((x + y) * 8)
+			img.SetRGBA(x, y, color.RGBA{R: 40 + v, G: 100 + v, B: v, A: 255 - v})
+		}
+	}
+	return img
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func TestCompressSizes(t *testing.T) {
+	tests := []struct {
+		w, h     int
+		bc1, bc3 int
+	}{
+		{4, 4, 8, 16},
+		{8, 4, 16, 32},
+		{5, 5, 32, 64},
+		{1, 1, 8, 16},
+		{16, 16, 128, 256},
+	}
+
+	for _, test := range tests {
+		img := gradientImage(test.w, test.h)
+		if size := len(CompressBC1(img)); size != test.bc1 {
+			t.Errorf("%dx%d: bc1 size %d, expected %d", test.w, test.h, size, test.bc1)
+		}
+		if size := len(CompressBC3(img)); size != test.bc3 {
+			t.Errorf("%dx%d: bc3 size %d, expected %d", test.w, test.h, size, test.bc3)
+		}
+	}
+}
+
+func TestCompressBC1SolidColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{255, 0, 0, 255})
+	}
+
+	data := CompressBC1(img)
+	if c0 := binary.LittleEndian.Uint16(data); c0 != 0xf800 {
+		t.Fatalf("c0 is %#x, expected 0xf800", c0)
+	}
+	if indices := binary.LittleEndian.Uint32(data[4:]); indices != 0 {
+		t.Fatalf("indices are %#x, expected 0", indices)
+	}
+}
+
+func TestCompressBC1FourColorMode(t *testing.T) {
+	img := gradientImage(4, 4)
+	data := CompressBC1(img)
+
+	// c0 <= c1 would switch the decoder to the three color mode with transparent black
+	if c0, c1 := binary.LittleEndian.Uint16(data[0:]), binary.LittleEndian.Uint16(data[2:]); c0 <= c1 {
+		t.Fatalf("c0 %#x is not greater than c1 %#x", c0, c1)
+	}
+}
+
+func TestCompressBC3RoundTrip(t *testing.T) {
+	img := rampImage(8, 8)
+	data := CompressBC3(img)
+
+	for block := 0; block < 4; block++ {
+		bx, by := block%2, block/2
+		alpha := decodeAlphaBlock(data[block*16:])
+		colors := decodeColorBlock(data[block*16+8:])
+
+		for i := 0; i < 16; i++ {
+			pixel := img.RGBAAt(bx*4+i%4, by*4+i/4)
+			if d := abs(alpha[i] - int(pixel.A)); d > 4 {
+				t.Errorf("block %d pixel %d: alpha %d, expected %d", block, i, alpha[i], pixel.A)
+			}
+
+			expected := [3]int{int(pixel.R), int(pixel.G), int(pixel.B)}
+			for c := 0; c < 3; c++ {
+				if d := abs(colors[i][c] - expected[c]); d > 12 {
+					t.Errorf("block %d pixel %d: channel %d is %d, expected %d", block, i, c, colors[i][c], expected[c])
+				}
+			}
+		}
+	}
+}
+
+func TestReadBlockRepeatsEdges(t *testing.T) {
+	img := gradientImage(2, 3)
+	block := readBlock(img, 0, 0)
+
+	for i, pixel := range block {
+		x, y := i%4, i/4
+		if x > 1 {
+			x = 1
+		}
+		if y > 2 {
+			y = 2
+		}
+
+		expected := img.RGBAAt(x, y)
+		if pixel != [4]uint8{expected.R, expected.G, expected.B, expected.A} {
+			t.Errorf("pixel %d is %v, expected %v", i, pixel, expected)
+		}
+	}
+}
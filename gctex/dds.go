@@ -0,0 +1,208 @@
+package gctex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+var ddsMagic = [4]byte{'D', 'D', 'S', ' '}
+
+const (
+	ddsFlagMipmapCount = 0x20000
+	ddsPixelFourCC     = 0x4
+	ddsPixelRGB        = 0x40
+	ddsPixelAlpha      = 0x1
+	ddsCaps2Cubemap    = 0x200
+	ddsCaps2Volume     = 0x200000
+)
+
+// DXGI formats used by DX10 DDS files
+const (
+	dxgiR8G8B8A8Unorm     = 28
+	dxgiR8G8B8A8UnormSrgb = 29
+	dxgiBC1Unorm          = 71
+	dxgiBC1UnormSrgb      = 72
+	dxgiBC2Unorm          = 74
+	dxgiBC2UnormSrgb      = 75
+	dxgiBC3Unorm          = 77
+	dxgiBC3UnormSrgb      = 78
+	dxgiResourceTexture2d = 3
+)
+
+type ddsPixelFormat struct {
+	Size        uint32
+	Flags       uint32
+	FourCC      [4]byte
+	RGBBitCount uint32
+	RBitMask    uint32
+	GBitMask    uint32
+	BBitMask    uint32
+	ABitMask    uint32
+}
+
+type ddsHeader struct {
+	Size              uint32
+	Flags             uint32
+	Height            uint32
+	Width             uint32
+	PitchOrLinearSize uint32
+	Depth             uint32
+	MipMapCount       uint32
+	Reserved1         [11]uint32
+	PixelFormat       ddsPixelFormat
+	Caps              uint32
+	Caps2             uint32
+	Caps3             uint32
+	Caps4             uint32
+	Reserved2         uint32
+}
+
+type ddsHeaderDX10 struct {
+	DxgiFormat        uint32
+	ResourceDimension uint32
+	MiscFlag          uint32
+	ArraySize         uint32
+	MiscFlags2        uint32
+}
+
+// IsDDS reports whether the data starts with the DDS magic.
+func IsDDS(data []byte) bool {
+	return len(data) >= len(ddsMagic) && bytes.Equal(data[:len(ddsMagic)], ddsMagic[:])
+}
+
+// DecodeDDS reads a 2d DDS texture with DXT1/3/5 (BC1-3) or 32 bit RGB(A) data.
+func DecodeDDS(r io.Reader) (*Texture, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, err
+	}
+
+	if magic != ddsMagic {
+		return nil, errors.New("not a dds file")
+	}
+
+	var hdr ddsHeader
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return nil, err
+	}
+
+	if hdr.Size != 124 || hdr.PixelFormat.Size != 32 {
+		return nil, errors.New("invalid dds header")
+	}
+
+	if hdr.Width == 0 || hdr.Height == 0 || hdr.Caps2&(ddsCaps2Cubemap|ddsCaps2Volume) != 0 {
+		return nil, errors.New("only 2d dds textures are supported")
+	}
+
+	format, convert, err := ddsFormat(r, hdr.PixelFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := 1
+	if hdr.Flags&ddsFlagMipmapCount != 0 && hdr.MipMapCount > 1 {
+		levels = int(hdr.MipMapCount)
+	}
+
+	tex := &Texture{
+		Format: format,
+		Width:  int(hdr.Width),
+		Height: int(hdr.Height),
+		Levels: make([][]byte, levels),
+	}
+
+	for level := range tex.Levels {
+		w, h := LevelDimensions(tex.Width, tex.Height, level)
+		tex.Levels[level] = make([]byte, format.LevelSize(w, h))
+
+		if _, err := io.ReadFull(r, tex.Levels[level]); err != nil {
+			return nil, err
+		}
+
+		if convert != nil {
+			convert(tex.Levels[level])
+		}
+	}
+
+	return tex, nil
+}
+
+// ddsFormat maps the pixel format, reading the DX10 header extension if present.
+// Uncompressed formats come with a function converting the pixels to RGBA in place.
+func ddsFormat(r io.Reader, pf ddsPixelFormat) (Format, func([]byte), error) {
+	if pf.Flags&ddsPixelFourCC != 0 {
+		switch string(pf.FourCC[:]) {
+		case "DXT1":
+			return FORMAT_BC1, nil, nil
+		case "DXT2", "DXT3":
+			return FORMAT_BC2, nil, nil
+		case "DXT4", "DXT5":
+			return FORMAT_BC3, nil, nil
+		case "DX10":
+			return ddsDX10Format(r)
+		default:
+			return 0, nil, fmt.Errorf("unsuported dds compression: %q", pf.FourCC[:])
+		}
+	}
+
+	if pf.Flags&ddsPixelRGB == 0 || pf.RGBBitCount != 32 {
+		return 0, nil, fmt.Errorf("unsuported dds pixel format: %d bit", pf.RGBBitCount)
+	}
+
+	masks := [4]uint32{pf.RBitMask, pf.GBitMask, pf.BBitMask, pf.ABitMask}
+	if pf.Flags&ddsPixelAlpha == 0 {
+		masks[3] = 0
+	}
+
+	return FORMAT_RGBA8, func(pixels []byte) {
+		for i := 0; i+4 <= len(pixels); i += 4 {
+			value := binary.LittleEndian.Uint32(pixels[i:])
+			for c, mask := range masks {
+				pixels[i+c] = extractChannel(value, mask)
+			}
+		}
+	}, nil
+}
+
+func ddsDX10Format(r io.Reader) (Format, func([]byte), error) {
+	var hdr ddsHeaderDX10
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return 0, nil, err
+	}
+
+	if hdr.ResourceDimension != dxgiResourceTexture2d || hdr.ArraySize > 1 {
+		return 0, nil, errors.New("only 2d dds textures are supported")
+	}
+
+	switch hdr.DxgiFormat {
+	case dxgiR8G8B8A8Unorm, dxgiR8G8B8A8UnormSrgb:
+		return FORMAT_RGBA8, nil, nil
+	case dxgiBC1Unorm, dxgiBC1UnormSrgb:
+		return FORMAT_BC1, nil, nil
+	case dxgiBC2Unorm, dxgiBC2UnormSrgb:
+		return FORMAT_BC2, nil, nil
+	case dxgiBC3Unorm, dxgiBC3UnormSrgb:
+		return FORMAT_BC3, nil, nil
+	default:
+		return 0, nil, fmt.Errorf("unsuported dds dxgi format: %d", hdr.DxgiFormat)
+	}
+}
+
+// extractChannel returns the 8 bit value of the masked channel, missing channels are opaque.
+func extractChannel(value, mask uint32) uint8 {
+	if mask == 0 {
+		return 255
+	}
+
+	value = (value & mask) >> bits.TrailingZeros32(mask)
+	width := bits.OnesCount32(mask)
+	if width >= 8 {
+		return uint8(value >> (width - 8))
+	}
+
+	return uint8(value * 255 / (1<<width - 1))
+}
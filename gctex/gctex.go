@@ -0,0 +1,324 @@
+// Package gctex implements the baked texture container produced by gocraft-pack.
+// A container holds a single 2d texture in raw RGBA or block compressed form,
+// together with all its mip levels, so it can be uploaded without decoding.
+// KTX and DDS files are read into the same Texture representation.
+package gctex
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"math/bits"
+)
+
+type Format uint16
+
+const (
+	FORMAT_RGBA8 Format = 1
+	FORMAT_BC1   Format = 2
+	FORMAT_BC3   Format = 3
+	FORMAT_BC2   Format = 4
+	// ETC2 textures can only be read from KTX files, they are not produced by the packer
+	FORMAT_ETC2_RGB8  Format = 5
+	FORMAT_ETC2_RGBA8 Format = 6
+)
+
+const Extension = ".gctex"
+
+var Magic = [4]byte{'G', 'C', 'T', 'X'}
+
+const version uint16 = 1
+
+// MaxDimension and MaxSize bound the textures read from containers, so corrupt headers
+// are rejected before anything is allocated
+const (
+	MaxDimension = 1 << 14
+	MaxSize      = 1 << 30
+)
+
+type Texture struct {
+	Format Format
+	Width  int
+	Height int
+	// Levels holds the mip levels data, starting from the full size image
+	Levels [][]byte
+}
+
+type header struct {
+	Magic   [4]byte
+	Version uint16
+	Format  Format
+	Width   uint32
+	Height  uint32
+	Levels  uint32
+}
+
+func (f Format) String() string {
+	switch f {
+	case FORMAT_RGBA8:
+		return "rgba8"
+	case FORMAT_BC1:
+		return "bc1"
+	case FORMAT_BC3:
+		return "bc3"
+	case FORMAT_BC2:
+		return "bc2"
+	case FORMAT_ETC2_RGB8:
+		return "etc2_rgb8"
+	case FORMAT_ETC2_RGBA8:
+		return "etc2_rgba8"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint16(f))
+	}
+}
+
+func (f Format) Compressed() bool {
+	return f.blockSize() > 0
+}
+
+// LevelSize returns the expected byte size of a mip level.
+func (f Format) LevelSize(width, height int) int {
+	if f.Compressed() {
+		return blockCount(width) * blockCount(height) * f.blockSize()
+	}
+
+	return width * height * 4
+}
+
+// blockSize returns the byte size of a 4x4 block, or 0 for uncompressed formats.
+func (f Format) blockSize() int {
+	switch f {
+	case FORMAT_BC1, FORMAT_ETC2_RGB8:
+		return 8
+	case FORMAT_BC2, FORMAT_BC3, FORMAT_ETC2_RGBA8:
+		return 16
+	default:
+		return 0
+	}
+}
+
+// LevelDimensions returns the size of the given mip level.
+func LevelDimensions(width, height, level int) (int, int) {
+	return maxInt(width>>level, 1), maxInt(height>>level, 1)
+}
+
+// IsContainer reports whether the data starts with the container magic.
+func IsContainer(data []byte) bool {
+	return len(data) >= len(Magic) && bytes.Equal(data[:len(Magic)], Magic[:])
+}
+
+func Encode(w io.Writer, tex *Texture) error {
+	if len(tex.Levels) == 0 {
+		return errors.New("texture has no levels")
+	}
+
+	bw := bufio.NewWriter(w)
+	err := binary.Write(bw, binary.LittleEndian, header{
+		Magic:   Magic,
+		Version: version,
+		Format:  tex.Format,
+		Width:   uint32(tex.Width),
+		Height:  uint32(tex.Height),
+		Levels:  uint32(len(tex.Levels)),
+	})
+	if err != nil {
+		return err
+	}
+
+	for level, data := range tex.Levels {
+		w, h := LevelDimensions(tex.Width, tex.Height, level)
+		if len(data) != tex.Format.LevelSize(w, h) {
+			return fmt.Errorf("level %d has %d bytes, expected %d", level, len(data), tex.Format.LevelSize(w, h))
+		}
+
+		if err := binary.Write(bw, binary.LittleEndian, uint32(len(data))); err != nil {
+			return err
+		}
+
+		if _, err := bw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+func Decode(r io.Reader) (*Texture, error) {
+	var hdr header
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return nil, err
+	}
+
+	if hdr.Magic != Magic {
+		return nil, errors.New("not a gctex container")
+	}
+
+	if hdr.Version != version {
+		return nil, fmt.Errorf("unsuported gctex version: %d", hdr.Version)
+	}
+
+	if hdr.Format != FORMAT_RGBA8 && !hdr.Format.Compressed() {
+		return nil, fmt.Errorf("unsuported gctex format: %s", hdr.Format)
+	}
+
+	if err := validateHeader(hdr); err != nil {
+		return nil, err
+	}
+
+	tex := &Texture{
+		Format: hdr.Format,
+		Width:  int(hdr.Width),
+		Height: int(hdr.Height),
+		Levels: make([][]byte, hdr.Levels),
+	}
+
+	for level := range tex.Levels {
+		var size uint32
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, err
+		}
+
+		w, h := LevelDimensions(tex.Width, tex.Height, level)
+		if int(size) != tex.Format.LevelSize(w, h) {
+			return nil, fmt.Errorf("level %d has %d bytes, expected %d", level, size, tex.Format.LevelSize(w, h))
+		}
+
+		tex.Levels[level] = make([]byte, size)
+		if _, err := io.ReadFull(r, tex.Levels[level]); err != nil {
+			return nil, err
+		}
+	}
+
+	return tex, nil
+}
+
+// validateHeader checks the size and level count are possible and the data isn't too big.
+func validateHeader(hdr header) error {
+	if hdr.Width == 0 || hdr.Height == 0 || hdr.Width > MaxDimension || hdr.Height > MaxDimension {
+		return fmt.Errorf("invalid gctex size: %d x %d", hdr.Width, hdr.Height)
+	}
+
+	maxLevels := bits.Len32(hdr.Width)
+	if hdr.Height > hdr.Width {
+		maxLevels = bits.Len32(hdr.Height)
+	}
+	if hdr.Levels == 0 || int(hdr.Levels) > maxLevels {
+		return fmt.Errorf("invalid gctex level count: %d, at most %d", hdr.Levels, maxLevels)
+	}
+
+	total := 0
+	for level := 0; level < int(hdr.Levels); level++ {
+		w, h := LevelDimensions(int(hdr.Width), int(hdr.Height), level)
+		total += hdr.Format.LevelSize(w, h)
+	}
+	if total > MaxSize {
+		return fmt.Errorf("gctex data is too big: %d bytes", total)
+	}
+
+	return nil
+}
+
+// FromImage creates a texture from the image, optionally with the full mip chain.
+func FromImage(img image.Image, format Format, mipmaps bool) (*Texture, error) {
+	levels := []*image.RGBA{ToRGBA(img)}
+	if mipmaps {
+		levels = GenerateMipmaps(levels[0])
+	}
+
+	tex := &Texture{
+		Format: format,
+		Width:  levels[0].Rect.Dx(),
+		Height: levels[0].Rect.Dy(),
+		Levels: make([][]byte, len(levels)),
+	}
+
+	for i, level := range levels {
+		switch format {
+		case FORMAT_RGBA8:
+			tex.Levels[i] = level.Pix
+		case FORMAT_BC1:
+			tex.Levels[i] = CompressBC1(level)
+		case FORMAT_BC3:
+			tex.Levels[i] = CompressBC3(level)
+		default:
+			return nil, fmt.Errorf("unsuported gctex format: %s", format)
+		}
+	}
+
+	return tex, nil
+}
+
+// ToRGBA converts the image into a tightly packed RGBA image starting at (0, 0).
+func ToRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) && rgba.Stride == rgba.Rect.Dx()*4 {
+		return rgba
+	}
+
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// GenerateMipmaps returns the full mip chain of the image, down to 1x1, using a box filter.
+func GenerateMipmaps(img *image.RGBA) []*image.RGBA {
+	levels := []*image.RGBA{img}
+
+	for current := img; current.Rect.Dx() > 1 || current.Rect.Dy() > 1; {
+		current = downsample(current)
+		levels = append(levels, current)
+	}
+
+	return levels
+}
+
+func downsample(src *image.RGBA) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := maxInt(sw/2, 1), maxInt(sh/2, 1)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sum [4]int
+			for dy := 0; dy < 2; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					sx, sy := minInt(x*2+dx, sw-1), minInt(y*2+dy, sh-1)
+					offset := sy*src.Stride + sx*4
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[offset+c])
+					}
+				}
+			}
+
+			offset := y*dst.Stride + x*4
+			for c := 0; c < 4; c++ {
+				dst.Pix[offset+c] = uint8((sum[c] + 2) / 4)
+			}
+		}
+	}
+
+	return dst
+}
+
+func blockCount(size int) int {
+	return maxInt((size+3)/4, 1)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
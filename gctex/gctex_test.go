@@ -0,0 +1,159 @@
+package gctex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		w, h    int
+		mipmaps bool
+		levels  int
+	}{
+		{"rgba8", FORMAT_RGBA8, 8, 4, false, 1},
+		{"rgba8 mipmaps", FORMAT_RGBA8, 8, 4, true, 4},
+		{"bc1 mipmaps", FORMAT_BC1, 16, 16, true, 5},
+		{"bc3 odd size", FORMAT_BC3, 7, 3, true, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tex, err := FromImage(gradientImage(test.w, test.h), test.format, test.mipmaps)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tex.Levels) != test.levels {
+				t.Fatalf("%d levels, expected %d", len(tex.Levels), test.levels)
+			}
+
+			var buffer bytes.Buffer
+			if err = Encode(&buffer, tex); err != nil {
+				t.Fatal(err)
+			}
+
+			if !IsContainer(buffer.Bytes()) {
+				t.Fatal("encoded data is not recognized as a container")
+			}
+
+			decoded, err := Decode(&buffer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded.Format != tex.Format || decoded.Width != tex.Width || decoded.Height != tex.Height {
+				t.Fatalf("decoded %s %dx%d, expected %s %dx%d",
+					decoded.Format, decoded.Width, decoded.Height, tex.Format, tex.Width, tex.Height)
+			}
+
+			for level := range tex.Levels {
+				if !bytes.Equal(decoded.Levels[level], tex.Levels[level]) {
+					t.Errorf("level %d differs", level)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeRejectsWrongLevelSize(t *testing.T) {
+	tex := &Texture{Format: FORMAT_BC1, Width: 8, Height: 8, Levels: [][]byte{make([]byte, 16)}}
+	if err := Encode(&bytes.Buffer{}, tex); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestDecodeRejectsInvalidData(t *testing.T) {
+	tex, err := FromImage(gradientImage(4, 4), FORMAT_RGBA8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	if err = Encode(&buffer, tex); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+
+	badMagic := append([]byte("XXXX"), data[4:]...)
+	if _, err = Decode(bytes.NewReader(badMagic)); err == nil {
+		t.Error("expected an error for a bad magic")
+	}
+
+	if _, err = Decode(bytes.NewReader(data[:len(data)-1])); err == nil {
+		t.Error("expected an error for truncated data")
+	}
+}
+
+func TestDecodeRejectsCorruptHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header header
+	}{
+		{"zero width", header{Width: 0, Height: 4, Levels: 1}},
+		{"zero height", header{Width: 4, Height: 0, Levels: 1}},
+		{"huge size", header{Width: 1 << 31, Height: 1 << 31, Levels: 1}},
+		{"no levels", header{Width: 4, Height: 4, Levels: 0}},
+		{"too many levels", header{Width: 4, Height: 2, Levels: 4}},
+		{"huge level count", header{Width: 4, Height: 4, Levels: 1 << 30}},
+		{"too big", header{Width: MaxDimension, Height: MaxDimension, Levels: 15}},
+	}
+
+	for _, test := range tests {
+		hdr := test.header
+		hdr.Magic, hdr.Version, hdr.Format = Magic, version, FORMAT_RGBA8
+
+		var buffer bytes.Buffer
+		if err := binary.Write(&buffer, binary.LittleEndian, hdr); err != nil {
+			t.Fatal(err)
+		}
+		// a level size matching the header, the data is missing
+		binary.Write(&buffer, binary.LittleEndian, uint32(FORMAT_RGBA8.LevelSize(int(hdr.Width), int(hdr.Height))))
+
+		if _, err := Decode(&buffer); err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+			t.Errorf("%s: expected a header error, got %v", test.name, err)
+		}
+	}
+}
+
+func TestLevelSize(t *testing.T) {
+	tests := []struct {
+		format Format
+		w, h   int
+		size   int
+	}{
+		{FORMAT_RGBA8, 3, 5, 60},
+		{FORMAT_BC1, 1, 1, 8},
+		{FORMAT_BC1, 9, 4, 24},
+		{FORMAT_BC3, 8, 8, 64},
+		{FORMAT_BC2, 2, 2, 16},
+	}
+
+	for _, test := range tests {
+		if size := test.format.LevelSize(test.w, test.h); size != test.size {
+			t.Errorf("%s %dx%d: size %d, expected %d", test.format, test.w, test.h, size, test.size)
+		}
+	}
+}
+
+func TestGenerateMipmaps(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	copy(img.Pix, []uint8{
+		0, 0, 0, 0, 255, 255, 255, 255,
+		255, 255, 255, 255, 0, 0, 0, 0,
+	})
+
+	levels := GenerateMipmaps(img)
+	if len(levels) != 2 {
+		t.Fatalf("%d levels, expected 2", len(levels))
+	}
+
+	if pixel := levels[1].Pix[:4]; !bytes.Equal(pixel, []uint8{128, 128, 128, 128}) {
+		t.Fatalf("averaged pixel is %v", pixel)
+	}
+}
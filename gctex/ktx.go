@@ -0,0 +1,153 @@
+package gctex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ktxIdentifier = [12]byte{0xAB, 'K', 'T', 'X', ' ', '1', '1', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+const ktxEndianness uint32 = 0x04030201
+
+// OpenGL internal formats used by KTX files
+const (
+	glRGBA                      = 0x1908
+	glUnsignedByte              = 0x1401
+	glCompressedRgbS3tcDxt1     = 0x83F0
+	glCompressedRgbaS3tcDxt1    = 0x83F1
+	glCompressedRgbaS3tcDxt3    = 0x83F2
+	glCompressedRgbaS3tcDxt5    = 0x83F3
+	glCompressedRgb8Etc2        = 0x9274
+	glCompressedRgba8Etc2Eac    = 0x9278
+	glCompressedSrgbS3tcDxt1    = 0x8C4C
+	glCompressedSrgbAlphaDxt1   = 0x8C4D
+	glCompressedSrgbAlphaDxt3   = 0x8C4E
+	glCompressedSrgbAlphaDxt5   = 0x8C4F
+	glCompressedSrgb8Etc2       = 0x9275
+	glCompressedSrgb8Alpha8Etc2 = 0x9279
+)
+
+// ktxHeader holds the header fields following the identifier and the endianness marker
+type ktxHeader struct {
+	GlType                uint32
+	GlTypeSize            uint32
+	GlFormat              uint32
+	GlInternalFormat      uint32
+	GlBaseInternalFormat  uint32
+	PixelWidth            uint32
+	PixelHeight           uint32
+	PixelDepth            uint32
+	NumberOfArrayElements uint32
+	NumberOfFaces         uint32
+	NumberOfMipmapLevels  uint32
+	BytesOfKeyValueData   uint32
+}
+
+// IsKTX reports whether the data starts with the KTX 1.1 identifier.
+func IsKTX(data []byte) bool {
+	return len(data) >= len(ktxIdentifier) && bytes.Equal(data[:len(ktxIdentifier)], ktxIdentifier[:])
+}
+
+// DecodeKTX reads a 2d KTX 1.1 texture with RGBA8, BC1-3 or ETC2 data.
+func DecodeKTX(r io.Reader) (*Texture, error) {
+	var identifier [12]byte
+	if _, err := io.ReadFull(r, identifier[:]); err != nil {
+		return nil, err
+	}
+
+	if identifier != ktxIdentifier {
+		return nil, errors.New("not a ktx file")
+	}
+
+	var endianness [4]byte
+	if _, err := io.ReadFull(r, endianness[:]); err != nil {
+		return nil, err
+	}
+
+	var order binary.ByteOrder
+	switch ktxEndianness {
+	case binary.LittleEndian.Uint32(endianness[:]):
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(endianness[:]):
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("invalid ktx endianness")
+	}
+
+	var hdr ktxHeader
+	if err := binary.Read(r, order, &hdr); err != nil {
+		return nil, err
+	}
+
+	if hdr.PixelWidth == 0 || hdr.PixelHeight == 0 || hdr.PixelDepth > 1 || hdr.NumberOfArrayElements > 0 || hdr.NumberOfFaces > 1 {
+		return nil, errors.New("only 2d ktx textures are supported")
+	}
+
+	format, err := ktxFormat(hdr)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.CopyN(io.Discard, r, int64(hdr.BytesOfKeyValueData)); err != nil {
+		return nil, err
+	}
+
+	tex := &Texture{
+		Format: format,
+		Width:  int(hdr.PixelWidth),
+		Height: int(hdr.PixelHeight),
+		Levels: make([][]byte, maxInt(int(hdr.NumberOfMipmapLevels), 1)),
+	}
+
+	for level := range tex.Levels {
+		var size uint32
+		if err := binary.Read(r, order, &size); err != nil {
+			return nil, err
+		}
+
+		w, h := LevelDimensions(tex.Width, tex.Height, level)
+		if int(size) != format.LevelSize(w, h) {
+			return nil, fmt.Errorf("level %d has %d bytes, expected %d", level, size, format.LevelSize(w, h))
+		}
+
+		tex.Levels[level] = make([]byte, size)
+		if _, err := io.ReadFull(r, tex.Levels[level]); err != nil {
+			return nil, err
+		}
+
+		// mip levels are padded to 4 bytes
+		if _, err := io.CopyN(io.Discard, r, int64(3-(size+3)%4)); err != nil {
+			return nil, err
+		}
+	}
+
+	return tex, nil
+}
+
+func ktxFormat(hdr ktxHeader) (Format, error) {
+	if hdr.GlType != 0 {
+		if hdr.GlType == glUnsignedByte && hdr.GlFormat == glRGBA {
+			return FORMAT_RGBA8, nil
+		}
+
+		return 0, fmt.Errorf("unsuported ktx pixel format: type 0x%x, format 0x%x", hdr.GlType, hdr.GlFormat)
+	}
+
+	switch hdr.GlInternalFormat {
+	case glCompressedRgbS3tcDxt1, glCompressedRgbaS3tcDxt1, glCompressedSrgbS3tcDxt1, glCompressedSrgbAlphaDxt1:
+		return FORMAT_BC1, nil
+	case glCompressedRgbaS3tcDxt3, glCompressedSrgbAlphaDxt3:
+		return FORMAT_BC2, nil
+	case glCompressedRgbaS3tcDxt5, glCompressedSrgbAlphaDxt5:
+		return FORMAT_BC3, nil
+	case glCompressedRgb8Etc2, glCompressedSrgb8Etc2:
+		return FORMAT_ETC2_RGB8, nil
+	case glCompressedRgba8Etc2Eac, glCompressedSrgb8Alpha8Etc2:
+		return FORMAT_ETC2_RGBA8, nil
+	default:
+		return 0, fmt.Errorf("unsuported ktx internal format: 0x%x", hdr.GlInternalFormat)
+	}
+}
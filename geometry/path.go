@@ -0,0 +1,459 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type PathCommand uint8
+
+const (
+	PC_MOVE_TO PathCommand = iota
+	PC_LINE_TO
+	PC_QUAD_TO
+	PC_CUBIC_TO
+	PC_CLOSE
+)
+
+// FillRule decides which areas of nested and overlapping subpaths are inside the path.
+type FillRule uint8
+
+const (
+	// FR_EVEN_ODD fills the areas inside an odd number of subpaths
+	FR_EVEN_ODD FillRule = iota
+	// FR_NONZERO fills the areas the subpaths wind around, the SVG default
+	FR_NONZERO
+)
+
+type pathSegment struct {
+	command PathCommand
+	// points holds the control points followed by the end point
+	points [3]vec2
+}
+
+// Path is a sequence of subpaths made of lines and bezier curves. Arcs are stored as cubic curves.
+type Path struct {
+	segments []pathSegment
+	start    vec2
+	current  vec2
+	open     bool
+}
+
+// Subpath is a flattened subpath.
+type Subpath struct {
+	Points []mgl32.Vec2
+	Closed bool
+}
+
+func NewPath() *Path {
+	return &Path{}
+}
+
+// Empty checks the path has no segments.
+func (p *Path) Empty() bool {
+	return len(p.segments) == 0
+}
+
+// CurrentPoint returns the end point of the last segment.
+func (p *Path) CurrentPoint() mgl32.Vec2 {
+	return p.current
+}
+
+func (p *Path) MoveTo(x, y float32) *Path {
+	p.current, p.start = vec2{x, y}, vec2{x, y}
+	p.segments = append(p.segments, pathSegment{command: PC_MOVE_TO, points: [3]vec2{p.current}})
+	p.open = true
+	return p
+}
+
+func (p *Path) LineTo(x, y float32) *Path {
+	p.ensureSubpath()
+	p.current = vec2{x, y}
+	p.segments = append(p.segments, pathSegment{command: PC_LINE_TO, points: [3]vec2{p.current}})
+	return p
+}
+
+// QuadTo adds a quadratic bezier curve with the control point (cx, cy).
+func (p *Path) QuadTo(cx, cy, x, y float32) *Path {
+	p.ensureSubpath()
+	p.current = vec2{x, y}
+	p.segments = append(p.segments, pathSegment{command: PC_QUAD_TO, points: [3]vec2{{cx, cy}, p.current}})
+	return p
+}
+
+// CubicTo adds a cubic bezier curve with the control points (c1x, c1y) and (c2x, c2y).
+func (p *Path) CubicTo(c1x, c1y, c2x, c2y, x, y float32) *Path {
+	p.ensureSubpath()
+	p.current = vec2{x, y}
+	p.segments = append(p.segments, pathSegment{command: PC_CUBIC_TO, points: [3]vec2{{c1x, c1y}, {c2x, c2y}, p.current}})
+	return p
+}
+
+// ArcTo adds an elliptic arc to (x, y) like the SVG arc command: rx and ry are the ellipse radii,
+// rotation is the ellipse x axis angle in radians, largeArc and sweep select one of the four arcs.
+// The radii are scaled up when the end point is out of their reach.
+func (p *Path) ArcTo(rx, ry, rotation float32, largeArc, sweep bool, x, y float32) *Path {
+	p.ensureSubpath()
+	from, to := p.current, vec2{x, y}
+	if from.ApproxEqual(to) {
+		return p
+	}
+
+	rx, ry = float32(math.Abs(float64(rx))), float32(math.Abs(float64(ry)))
+	if rx == 0 || ry == 0 {
+		return p.LineTo(x, y)
+	}
+
+	for _, c := range arcCubics(from, to, float64(rx), float64(ry), float64(rotation), largeArc, sweep) {
+		p.CubicTo(c[0][0], c[0][1], c[1][0], c[1][1], c[2][0], c[2][1])
+	}
+
+	// the last curve ends at the point computed from the angle
+	p.current = to
+	p.segments[len(p.segments)-1].points[2] = to
+	return p
+}
+
+// Close closes the subpath with a line back to its start.
+func (p *Path) Close() *Path {
+	if !p.open {
+		return p
+	}
+
+	p.segments = append(p.segments, pathSegment{command: PC_CLOSE})
+	p.current = p.start
+	p.open = false
+	return p
+}
+
+// ensureSubpath starts a subpath at the current point when drawing after Close or without MoveTo.
+func (p *Path) ensureSubpath() {
+	if !p.open {
+		p.MoveTo(p.current[0], p.current[1])
+	}
+}
+
+// Transformed returns a copy of the path with the 2D affine transformation applied to all the points.
+func (p *Path) Transformed(transform mgl32.Mat3) *Path {
+	apply := func(v vec2) vec2 {
+		return transform.Mul3x1(mgl32.Vec3{v[0], v[1], 1}).Vec2()
+	}
+
+	transformed := &Path{
+		segments: make([]pathSegment, len(p.segments)),
+		start:    apply(p.start),
+		current:  apply(p.current),
+		open:     p.open,
+	}
+	for i, segment := range p.segments {
+		for j := range segment.points {
+			segment.points[j] = apply(segment.points[j])
+		}
+		transformed.segments[i] = segment
+	}
+
+	return transformed
+}
+
+// Flatten approximates the curves with lines, the flattened points are at most tolerance away from the curves.
+func (p *Path) Flatten(tolerance float32) []Subpath {
+	if tolerance <= 0 {
+		tolerance = 0.01
+	}
+
+	var subpaths []Subpath
+	var current *Subpath
+	last := vec2{}
+
+	for _, segment := range p.segments {
+		switch segment.command {
+		case PC_MOVE_TO:
+			subpaths = append(subpaths, Subpath{Points: []mgl32.Vec2{segment.points[0]}})
+			current = &subpaths[len(subpaths)-1]
+			last = segment.points[0]
+			continue
+		case PC_LINE_TO:
+			current.Points = append(current.Points, segment.points[0])
+		case PC_QUAD_TO:
+			current.Points = flattenCurve(current.Points, []vec2{last, segment.points[0], segment.points[1]}, tolerance)
+		case PC_CUBIC_TO:
+			current.Points = flattenCurve(current.Points, []vec2{last, segment.points[0], segment.points[1], segment.points[2]}, tolerance)
+		case PC_CLOSE:
+			current.Closed = true
+			last = current.Points[0]
+			continue
+		}
+
+		last = current.Points[len(current.Points)-1]
+	}
+
+	return subpaths
+}
+
+// Polygons flattens the closed subpaths, open subpaths are closed, into polygons filled by the rule.
+// The rule is exact for non intersecting subpaths, intersecting ones are filled as separate outlines.
+func (p *Path) Polygons(tolerance float32, rule FillRule) []Polygon {
+	var rings [][]mgl32.Vec2
+	for _, subpath := range p.Flatten(tolerance) {
+		ring := removeDuplicates(subpath.Points, true)
+		if len(ring) >= 3 && SignedArea(ring) != 0 {
+			rings = append(rings, ring)
+		}
+	}
+
+	return PolygonsFromRings(rings, rule)
+}
+
+// PolygonsFromRings groups the rings into polygons with holes by their nesting and the fill rule.
+// A ring is nested only when it's fully inside the other one, rings crossing their
+// containing ring or the other holes of its polygon become separate outlines.
+func PolygonsFromRings(rings [][]mgl32.Vec2, rule FillRule) []Polygon {
+	areas := make([]float32, len(rings))
+	order := make([]int, len(rings))
+	for i, ring := range rings {
+		areas[i] = float32(math.Abs(float64(SignedArea(ring))))
+		order[i] = i
+	}
+
+	// bigger rings first, a ring can only be inside a bigger one
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && areas[order[j]] > areas[order[j-1]]; j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+
+	filled := func(winding int) bool {
+		if rule == FR_NONZERO {
+			return winding != 0
+		}
+		return winding%2 != 0
+	}
+
+	var polygons []Polygon
+	// polygonOf maps the rings with a filled inside to their polygon
+	polygonOf := make(map[int]int)
+	// winding is the winding number right inside of the ring, the even-odd rule counts all rings the same way
+	winding := make(map[int]int)
+
+	for k, i := range order {
+		parent := -1
+		// the last containing ring is the smallest one
+		for l := k - 1; l >= 0; l-- {
+			if ringInside(rings[i], rings[order[l]]) {
+				parent = order[l]
+				break
+			}
+		}
+
+		outside := 0
+		if parent >= 0 {
+			outside = winding[parent]
+		}
+		winding[i] = outside + 1
+		if rule == FR_NONZERO && SignedArea(rings[i]) < 0 {
+			winding[i] = outside - 1
+		}
+
+		switch {
+		case filled(winding[i]) == filled(outside):
+			// not an edge of the filled area, like a ring inside a ring of the same direction
+			if filled(outside) {
+				polygonOf[i] = polygonOf[parent]
+			}
+			continue
+		case !filled(winding[i]):
+			polygon := &polygons[polygonOf[parent]]
+			if !ringCrossesAny(rings[i], polygon.Holes) {
+				polygon.Holes = append(polygon.Holes, rings[i])
+				continue
+			}
+
+			// filled like its parent, its own holes nest in it
+			winding[i] = outside
+		}
+		polygonOf[i] = len(polygons)
+		polygons = append(polygons, Polygon{Outline: rings[i]})
+	}
+
+	return polygons
+}
+
+// ringInside checks the inner ring lies inside the outer one, touching is allowed but crossing isn't.
+func ringInside(inner, outer []mgl32.Vec2) bool {
+	innerMin, innerMax := Polygon{Outline: inner}.Bounds()
+	outerMin, outerMax := Polygon{Outline: outer}.Bounds()
+	if innerMin[0] < outerMin[0] || innerMin[1] < outerMin[1] || innerMax[0] > outerMax[0] || innerMax[1] > outerMax[1] {
+		return false
+	}
+
+	// without crossings, all the points are on the same side as any of them
+	return pointInRing(inner[0], outer) && !ringsCross(inner, outer)
+}
+
+func ringCrossesAny(ring []mgl32.Vec2, others [][]mgl32.Vec2) bool {
+	for _, other := range others {
+		if ringsCross(ring, other) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ringsCross checks some edges of the rings properly intersect.
+func ringsCross(a, b []mgl32.Vec2) bool {
+	for i, j := 0, len(a)-1; i < len(a); j, i = i, i+1 {
+		for k, l := 0, len(b)-1; k < len(b); l, k = k, k+1 {
+			if segmentsCross(a[j], a[i], b[l], b[k]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// segmentsCross checks the segments intersect in a single point inside both of them.
+func segmentsCross(a, b, c, d vec2) bool {
+	side := func(p, q, r vec2) float32 {
+		return (q[0]-p[0])*(r[1]-p[1]) - (q[1]-p[1])*(r[0]-p[0])
+	}
+
+	abc, abd := side(a, b, c), side(a, b, d)
+	cda, cdb := side(c, d, a), side(c, d, b)
+	return ((abc > 0 && abd < 0) || (abc < 0 && abd > 0)) && ((cda > 0 && cdb < 0) || (cda < 0 && cdb > 0))
+}
+
+// pointInRing checks the point is inside the ring, by the even-odd rule.
+func pointInRing(p vec2, ring []mgl32.Vec2) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > p[1]) != (b[1] > p[1]) && p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// Stroke flattens and strokes all the subpaths, see Stroke.
+func (p *Path) Stroke(tolerance float32, style StrokeStyle) (vertices []float32, indices []uint32) {
+	for _, subpath := range p.Flatten(tolerance) {
+		subVertices, subIndices := Stroke(subpath.Points, subpath.Closed, style)
+
+		base := uint32(len(vertices) / StrokeVertexSize)
+		for _, index := range subIndices {
+			indices = append(indices, base+index)
+		}
+		vertices = append(vertices, subVertices...)
+	}
+
+	return vertices, indices
+}
+
+// flattenCurve appends the bezier curve points, without the first one. The segment count comes from
+// Wang's formula, which bounds the distance between the curve and its flattened version.
+func flattenCurve(points []mgl32.Vec2, curve []vec2, tolerance float32) []mgl32.Vec2 {
+	degree := len(curve) - 1
+
+	maxDifference := float32(0)
+	for i := 0; i+2 < len(curve); i++ {
+		difference := curve[i].Sub(curve[i+1].Mul(2)).Add(curve[i+2]).Len()
+		maxDifference = float32(math.Max(float64(maxDifference), float64(difference)))
+	}
+
+	segments := int(math.Ceil(math.Sqrt(float64(degree*(degree-1)) / 8 * float64(maxDifference) / float64(tolerance))))
+	if segments < 1 {
+		segments = 1
+	}
+
+	for i := 1; i <= segments; i++ {
+		points = append(points, bezierPoint(curve, float32(i)/float32(segments)))
+	}
+
+	return points
+}
+
+// bezierPoint evaluates the curve with the de Casteljau algorithm.
+func bezierPoint(curve []vec2, t float32) vec2 {
+	var buffer [4]vec2
+	points := buffer[:len(curve)]
+	copy(points, curve)
+
+	for n := len(points) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			points[i] = points[i].Mul(1 - t).Add(points[i+1].Mul(t))
+		}
+	}
+
+	return points[0]
+}
+
+// arcCubics converts the SVG endpoint arc to cubic curves of at most 90 degrees,
+// following the SVG implementation notes (F.6.5 and F.6.6).
+func arcCubics(from, to vec2, rx, ry, rotation float64, largeArc, sweep bool) [][3]vec2 {
+	sin, cos := math.Sincos(rotation)
+
+	// the middle point in the ellipse coordinates
+	dx, dy := float64(from[0]-to[0])/2, float64(from[1]-to[1])/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	if scale := x1*x1/(rx*rx) + y1*y1/(ry*ry); scale > 1 {
+		rx, ry = rx*math.Sqrt(scale), ry*math.Sqrt(scale)
+	}
+
+	numerator := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	denominator := rx*rx*y1*y1 + ry*ry*x1*x1
+	factor := math.Sqrt(math.Max(numerator, 0) / denominator)
+	if largeArc == sweep {
+		factor = -factor
+	}
+	cx1, cy1 := factor*rx*y1/ry, -factor*ry*x1/rx
+
+	cx := cos*cx1 - sin*cy1 + float64(from[0]+to[0])/2
+	cy := sin*cx1 + cos*cy1 + float64(from[1]+to[1])/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	start := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	point := func(theta float64) (float64, float64) {
+		x, y := rx*math.Cos(theta), ry*math.Sin(theta)
+		return cos*x - sin*y + cx, sin*x + cos*y + cy
+	}
+	derivative := func(theta float64) (float64, float64) {
+		x, y := -rx*math.Sin(theta), ry*math.Cos(theta)
+		return cos*x - sin*y, sin*x + cos*y
+	}
+
+	count := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(count)
+	k := 4.0 / 3 * math.Tan(step/4)
+
+	cubics := make([][3]vec2, count)
+	for i := range cubics {
+		a, b := start+step*float64(i), start+step*float64(i+1)
+		ax, ay := point(a)
+		bx, by := point(b)
+		adx, ady := derivative(a)
+		bdx, bdy := derivative(b)
+
+		cubics[i] = [3]vec2{
+			{float32(ax + k*adx), float32(ay + k*ady)},
+			{float32(bx - k*bdx), float32(by - k*bdy)},
+			{float32(bx), float32(by)},
+		}
+	}
+
+	return cubics
+}
@@ -0,0 +1,231 @@
+package geometry
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// circle returns a clockwise circle made of two half arcs.
+func circle(x, y, r float32) *Path {
+	return NewPath().
+		MoveTo(x-r, y).
+		ArcTo(r, r, 0, true, false, x+r, y).
+		ArcTo(r, r, 0, true, false, x-r, y).
+		Close()
+}
+
+func counterClockwiseCircle(x, y, r float32) *Path {
+	return NewPath().
+		MoveTo(x-r, y).
+		ArcTo(r, r, 0, true, true, x+r, y).
+		ArcTo(r, r, 0, true, true, x-r, y).
+		Close()
+}
+
+func TestFlatten(t *testing.T) {
+	path := NewPath().MoveTo(0, 0).LineTo(10, 0).LineTo(10, 10).Close().MoveTo(20, 0).LineTo(30, 0)
+	subpaths := path.Flatten(0.1)
+
+	if len(subpaths) != 2 {
+		t.Fatalf("expected 2 subpaths, got %d", len(subpaths))
+	}
+	if !subpaths[0].Closed || subpaths[1].Closed {
+		t.Errorf("expected the first subpath closed and the second open, got %v and %v", subpaths[0].Closed, subpaths[1].Closed)
+	}
+	expected := []mgl32.Vec2{{0, 0}, {10, 0}, {10, 10}}
+	if len(subpaths[0].Points) != len(expected) {
+		t.Fatalf("expected points %v, got %v", expected, subpaths[0].Points)
+	}
+	for i := range expected {
+		if subpaths[0].Points[i] != expected[i] {
+			t.Errorf("expected points %v, got %v", expected, subpaths[0].Points)
+		}
+	}
+}
+
+func TestFlattenCurves(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve []vec2
+	}{
+		{"quad", []vec2{{0, 0}, {50, 100}, {100, 0}}},
+		{"cubic", []vec2{{0, 0}, {0, 100}, {100, 100}, {100, 0}}},
+		{"cubic loop", []vec2{{0, 0}, {150, 100}, {-50, 100}, {100, 0}}},
+		{"flat", []vec2{{0, 0}, {30, 0}, {60, 0}, {100, 0}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := NewPath().MoveTo(test.curve[0][0], test.curve[0][1])
+			if len(test.curve) == 3 {
+				path.QuadTo(test.curve[1][0], test.curve[1][1], test.curve[2][0], test.curve[2][1])
+			} else {
+				path.CubicTo(test.curve[1][0], test.curve[1][1], test.curve[2][0], test.curve[2][1], test.curve[3][0], test.curve[3][1])
+			}
+
+			previous := 0
+			for _, tolerance := range []float32{1, 0.1, 0.01} {
+				points := path.Flatten(tolerance)[0].Points
+				if points[len(points)-1] != test.curve[len(test.curve)-1] {
+					t.Errorf("tolerance %v: expected the curve end %v, got %v", tolerance, test.curve[len(test.curve)-1], points[len(points)-1])
+				}
+				if len(points) < previous {
+					t.Errorf("tolerance %v: expected at least %d points, got %d", tolerance, previous, len(points))
+				}
+				previous = len(points)
+
+				// the segments are evenly spaced in t, compare their middle points with the curve
+				segments := len(points) - 1
+				for i := 0; i < segments; i++ {
+					middle := points[i].Add(points[i+1]).Mul(0.5)
+					onCurve := bezierPoint(test.curve, (float32(i)+0.5)/float32(segments))
+					if distance := middle.Sub(onCurve).Len(); distance > tolerance*1.001 {
+						t.Errorf("tolerance %v: segment %d is %v away from the curve", tolerance, i, distance)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestArcCubics(t *testing.T) {
+	tests := []struct {
+		name               string
+		from, to           vec2
+		rx, ry, rotation   float64
+		largeArc, sweep    bool
+		cubics             int
+		center             vec2
+		scaledRx, scaledRy float64
+	}{
+		{"quarter", vec2{10, 0}, vec2{0, 10}, 10, 10, 0, false, true, 1, vec2{0, 0}, 10, 10},
+		{"small arc", vec2{10, 0}, vec2{0, 10}, 10, 10, 0, false, false, 1, vec2{10, 10}, 10, 10},
+		{"large arc", vec2{10, 0}, vec2{0, 10}, 10, 10, 0, true, true, 3, vec2{10, 10}, 10, 10},
+		{"half", vec2{0, 0}, vec2{20, 0}, 10, 10, 0, false, true, 2, vec2{10, 0}, 10, 10},
+		{"radii scaled up", vec2{0, 0}, vec2{20, 0}, 5, 5, 0, false, true, 2, vec2{10, 0}, 10, 10},
+		{"ellipse", vec2{-20, 0}, vec2{20, 0}, 20, 10, 0, false, true, 2, vec2{0, 0}, 20, 10},
+		{"rotated ellipse", vec2{0, -20}, vec2{0, 20}, 20, 10, math.Pi / 2, false, false, 2, vec2{0, 0}, 20, 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cubics := arcCubics(test.from, test.to, test.rx, test.ry, test.rotation, test.largeArc, test.sweep)
+			if len(cubics) != test.cubics {
+				t.Fatalf("expected %d cubics, got %d", test.cubics, len(cubics))
+			}
+			if end := cubics[len(cubics)-1][2]; end.Sub(test.to).Len() > 1e-3 {
+				t.Errorf("expected the arc to end at %v, got %v", test.to, end)
+			}
+
+			sin, cos := math.Sincos(test.rotation)
+			start := test.from
+			for i, cubic := range cubics {
+				curve := []vec2{start, cubic[0], cubic[1], cubic[2]}
+				for step := 0; step <= 8; step++ {
+					p := bezierPoint(curve, float32(step)/8).Sub(test.center)
+					x := cos*float64(p[0]) + sin*float64(p[1])
+					y := -sin*float64(p[0]) + cos*float64(p[1])
+					// the cubic approximation of a quarter circle is off by 0.03%
+					if radius := math.Hypot(x/test.scaledRx, y/test.scaledRy); math.Abs(radius-1) > 1e-3 {
+						t.Errorf("cubic %d at %d/8 is off the ellipse by %v", i, step, radius-1)
+					}
+				}
+				start = cubic[2]
+			}
+
+			// sweep goes in the positive angle direction, the arc and its chord make a positive area
+			points := []mgl32.Vec2{test.from}
+			for _, cubic := range cubics {
+				points = append(points, cubic[2])
+			}
+			if area := SignedArea(points); len(points) > 2 && (area > 0) != test.sweep {
+				t.Errorf("expected the sweep %v, got the area %v", test.sweep, area)
+			}
+		})
+	}
+}
+
+func TestPolygons(t *testing.T) {
+	circleArea := float32(math.Pi * 100)
+	tests := []struct {
+		name     string
+		path     *Path
+		rule     FillRule
+		polygons int
+		holes    int
+		area     float32
+	}{
+		{"square", NewPath().MoveTo(0, 0).LineTo(10, 0).LineTo(10, 10).LineTo(0, 10).Close(), FR_EVEN_ODD, 1, 0, 100},
+		{"open subpath", NewPath().MoveTo(0, 0).LineTo(10, 0).LineTo(10, 10).LineTo(0, 10), FR_EVEN_ODD, 1, 0, 100},
+		{"line", NewPath().MoveTo(0, 0).LineTo(10, 0).LineTo(20, 0), FR_EVEN_ODD, 0, 0, 0},
+		{"circle", circle(10, 10, 10), FR_EVEN_ODD, 1, 0, circleArea},
+		{"ring", appendPath(circle(10, 10, 10), circle(10, 10, 5)), FR_EVEN_ODD, 1, 1, circleArea * 3 / 4},
+		{
+			"ring with an island",
+			appendPath(circle(10, 10, 10), circle(10, 10, 5), circle(10, 10, 2)),
+			FR_EVEN_ODD, 2, 1, circleArea*3/4 + circleArea/25,
+		},
+		{"two circles", appendPath(circle(10, 10, 10), circle(40, 10, 10)), FR_EVEN_ODD, 2, 0, circleArea * 2},
+		{"overlapping circles", appendPath(circle(10, 10, 10), circle(20, 10, 10)), FR_EVEN_ODD, 2, 0, circleArea * 2},
+		{
+			"overlapping holes",
+			appendPath(circle(20, 20, 20), circle(15, 20, 4), circle(20, 20, 4)),
+			FR_EVEN_ODD, 2, 1, circleArea * 4,
+		},
+		{"nonzero circle", counterClockwiseCircle(10, 10, 10), FR_NONZERO, 1, 0, circleArea},
+		{"nonzero same direction", appendPath(circle(10, 10, 10), circle(10, 10, 5)), FR_NONZERO, 1, 0, circleArea},
+		{
+			"nonzero opposite direction",
+			appendPath(circle(10, 10, 10), counterClockwiseCircle(10, 10, 5)),
+			FR_NONZERO, 1, 1, circleArea * 3 / 4,
+		},
+		{
+			"nonzero hole in a same direction ring",
+			appendPath(circle(10, 10, 10), circle(10, 10, 5), counterClockwiseCircle(10, 10, 3), counterClockwiseCircle(10, 10, 2)),
+			FR_NONZERO, 1, 1, circleArea - circleArea*4/100,
+		},
+		{
+			"nonzero island",
+			appendPath(circle(10, 10, 10), counterClockwiseCircle(10, 10, 5), circle(10, 10, 2)),
+			FR_NONZERO, 2, 1, circleArea*3/4 + circleArea/25,
+		},
+		{"nonzero overlapping circles", appendPath(circle(10, 10, 10), circle(20, 10, 10)), FR_NONZERO, 2, 0, circleArea * 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			polygons := test.path.Polygons(0.01, test.rule)
+			if len(polygons) != test.polygons {
+				t.Fatalf("expected %d polygons, got %d", test.polygons, len(polygons))
+			}
+
+			holes, area := 0, float32(0)
+			for _, polygon := range polygons {
+				holes += len(polygon.Holes)
+				area += polygon.Area()
+
+				if _, err := Triangulate(polygon); err != nil {
+					t.Errorf("triangulation failed: %v", err)
+				}
+			}
+
+			if holes != test.holes {
+				t.Errorf("expected %d holes, got %d", test.holes, holes)
+			}
+			if math.Abs(float64(area-test.area)) > float64(test.area)*1e-3 {
+				t.Errorf("expected the area %v, got %v", test.area, area)
+			}
+		})
+	}
+}
+
+// appendPath joins the subpaths of the paths.
+func appendPath(path *Path, others ...*Path) *Path {
+	for _, other := range others {
+		path.segments = append(path.segments, other.segments...)
+	}
+
+	return path
+}
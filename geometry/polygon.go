@@ -0,0 +1,92 @@
+// Package geometry implements 2D polygon operations used to build meshes,
+// like the triangulation of concave polygons with holes.
+package geometry
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Polygon is a simple polygon, its outline and holes must not intersect themselves or each other.
+// The winding of the rings doesn't matter.
+type Polygon struct {
+	Outline []mgl32.Vec2
+	Holes   [][]mgl32.Vec2
+}
+
+// SignedArea returns the ring area, positive for counter clockwise rings.
+func SignedArea(ring []mgl32.Vec2) float32 {
+	area := 0.0
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		area += float64(a[0])*float64(b[1]) - float64(b[0])*float64(a[1])
+	}
+
+	return float32(area / 2)
+}
+
+// Area returns the polygon area, without the holes.
+func (p Polygon) Area() float32 {
+	area := float32(math.Abs(float64(SignedArea(p.Outline))))
+	for _, hole := range p.Holes {
+		area -= float32(math.Abs(float64(SignedArea(hole))))
+	}
+
+	return area
+}
+
+// Bounds returns the min and max corners of the outline bounding box.
+func (p Polygon) Bounds() (min, max mgl32.Vec2) {
+	if len(p.Outline) == 0 {
+		return
+	}
+
+	min, max = p.Outline[0], p.Outline[0]
+	for _, point := range p.Outline[1:] {
+		for i := 0; i < 2; i++ {
+			min[i] = float32(math.Min(float64(min[i]), float64(point[i])))
+			max[i] = float32(math.Max(float64(max[i]), float64(point[i])))
+		}
+	}
+
+	return
+}
+
+// Points returns the outline points followed by the points of every hole,
+// the order the triangulation indices refer to.
+func (p Polygon) Points() []mgl32.Vec2 {
+	points := append([]mgl32.Vec2{}, p.Outline...)
+	for _, hole := range p.Holes {
+		points = append(points, hole...)
+	}
+
+	return points
+}
+
+// Mesh2d triangulates the polygon into vertices with the position and texture coordinates layout
+// of the 2D meshes, to be drawn as triangles. The texture coordinates map the bounding box
+// to the whole texture, with v going down like for the quad.
+func (p Polygon) Mesh2d() (vertices []float32, indices []uint32, err error) {
+	indices, err = Triangulate(p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	min, max := p.Bounds()
+	size := max.Sub(min)
+	if size[0] == 0 {
+		size[0] = 1
+	}
+	if size[1] == 0 {
+		size[1] = 1
+	}
+
+	points := p.Points()
+	vertices = make([]float32, 0, len(points)*5)
+	for _, point := range points {
+		vertices = append(vertices, point[0], point[1], 0, (point[0]-min[0])/size[0], (max[1]-point[1])/size[1])
+	}
+
+	return vertices, indices, nil
+}
@@ -0,0 +1,399 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type LineJoin uint8
+
+const (
+	LJ_MITER LineJoin = iota
+	LJ_BEVEL
+	LJ_ROUND
+)
+
+type LineCap uint8
+
+const (
+	LC_BUTT LineCap = iota
+	LC_SQUARE
+	LC_ROUND
+)
+
+// StrokeStyle describes how a polyline is stroked, lengths are in the units of the polyline points.
+type StrokeStyle struct {
+	Width float32
+	Join  LineJoin
+	Cap   LineCap
+	// MiterLimit is the max ratio of the miter length to the half width,
+	// sharper miter joins are beveled (4 by default)
+	MiterLimit float32
+	// Dashes are alternating dash and gap lengths, the line is solid without them.
+	// Patterns too short for the line, giving more than maxDashes dashes, are drawn solid too.
+	Dashes     []float32
+	DashOffset float32
+	// Feather is the width of the anti-aliased edge, about a pixel, the stroke fades out over it
+	Feather float32
+	// RoundSegments is the number of segments of a half circle for round joins and caps (8 by default)
+	RoundSegments int
+}
+
+// StrokeVertexSize is the number of floats of a stroke vertex: position (x, y),
+// texture coordinates (u along the line, v across it) and coverage.
+const StrokeVertexSize = 5
+
+type vec2 = mgl32.Vec2
+
+// strokeSection is a cross section of the stroke, the left side points are at center + left * offset,
+// the right side ones at center + right * offset.
+type strokeSection struct {
+	center      vec2
+	left, right vec2
+	u           float32
+	alpha       float32
+}
+
+// Stroke tessellates the polyline into triangles, see StrokeVertexSize for the vertex layout.
+func Stroke(points []mgl32.Vec2, closed bool, style StrokeStyle) (vertices []float32, indices []uint32) {
+	points = removeDuplicates(points, closed)
+	if len(points) < 2 || style.Width <= 0 {
+		return nil, nil
+	}
+
+	if style.MiterLimit <= 0 {
+		style.MiterLimit = 4
+	}
+	if style.RoundSegments <= 0 {
+		style.RoundSegments = 8
+	}
+
+	dashes, ok := dashPolyline(points, closed, style.Dashes, style.DashOffset)
+	if !ok {
+		return strokePolyline(points, closed, style, 0, nil, nil)
+	}
+
+	for _, dash := range dashes {
+		dashPoints := removeDuplicates(dash.points, false)
+		if len(dashPoints) < 2 {
+			// zero length dashes are dots with the square and round caps
+			if style.Cap == LC_BUTT {
+				continue
+			}
+			dashPoints = append(dashPoints, dashPoints[0].Add(dash.direction.Mul(style.Width*1e-3)))
+		}
+		vertices, indices = strokePolyline(dashPoints, false, style, dash.u, vertices, indices)
+	}
+
+	return vertices, indices
+}
+
+func removeDuplicates(points []mgl32.Vec2, closed bool) []mgl32.Vec2 {
+	unique := make([]mgl32.Vec2, 0, len(points))
+	for _, p := range points {
+		if len(unique) == 0 || !unique[len(unique)-1].ApproxEqual(p) {
+			unique = append(unique, p)
+		}
+	}
+
+	if closed && len(unique) > 1 && unique[0].ApproxEqual(unique[len(unique)-1]) {
+		unique = unique[:len(unique)-1]
+	}
+
+	return unique
+}
+
+func perpendicular(d vec2) vec2 {
+	return vec2{-d[1], d[0]}
+}
+
+func rotate(v vec2, angle float64) vec2 {
+	sin, cos := math.Sincos(angle)
+	return vec2{float32(float64(v[0])*cos - float64(v[1])*sin), float32(float64(v[0])*sin + float64(v[1])*cos)}
+}
+
+func strokePolyline(points []vec2, closed bool, style StrokeStyle, u float32, vertices []float32, indices []uint32) ([]float32, []uint32) {
+	count := len(points)
+	sections := make([]strokeSection, 0, count*2+style.RoundSegments*2)
+	if !closed {
+		d := points[1].Sub(points[0]).Normalize()
+		sections = appendCap(sections, points[0], d.Mul(-1), u, true, style)
+		u += points[1].Sub(points[0]).Len()
+	}
+
+	for i := 0; i < count; i++ {
+		if !closed && (i == 0 || i == count-1) {
+			continue
+		}
+
+		prev, next := points[(i+count-1)%count], points[(i+1)%count]
+		sections = appendJoin(sections, prev, points[i], next, u, style)
+		u += next.Sub(points[i]).Len()
+	}
+
+	if closed {
+		// the strip ends where it started
+		first := sections[0]
+		first.u = u
+		sections = append(sections, first)
+	} else {
+		d := points[count-1].Sub(points[count-2]).Normalize()
+		sections = appendCap(sections, points[count-1], d, u, false, style)
+	}
+
+	return emitSections(sections, style.Width/2, style.Feather, vertices, indices)
+}
+
+// appendJoin adds the sections of the join at the point, between the incoming and the outgoing segment.
+func appendJoin(sections []strokeSection, prev, p, next vec2, u float32, style StrokeStyle) []strokeSection {
+	d0, d1 := p.Sub(prev), next.Sub(p)
+	len0, len1 := d0.Len(), d1.Len()
+	d0, d1 = d0.Mul(1/len0), d1.Mul(1/len1)
+	n0, n1 := perpendicular(d0), perpendicular(d1)
+
+	cross := d0[0]*d1[1] - d0[1]*d1[0]
+	dot := n0.Dot(n1)
+	if dot > 0.9999 {
+		return append(sections, strokeSection{center: p, left: n0, right: n0.Mul(-1), u: u, alpha: 1})
+	}
+
+	// the miter vector reaches the corner of the offset lines at unit half width
+	miter := n0.Add(n1).Mul(1 / float32(math.Max(float64(1+dot), 1e-6)))
+	miterLength := miter.Len()
+
+	if style.Join == LJ_MITER && miterLength <= style.MiterLimit {
+		return append(sections, strokeSection{center: p, left: miter, right: miter.Mul(-1), u: u, alpha: 1})
+	}
+
+	// the inner side keeps the miter, shortened so it doesn't pass the neighbour segments
+	outer := style.Width/2 + style.Feather/2
+	inner := miter
+	if maxLength := float32(math.Min(float64(len0), float64(len1))) / outer; miterLength > maxLength {
+		inner = miter.Mul(maxLength / miterLength)
+	}
+
+	// the outer side goes from the incoming to the outgoing normal, directly or around an arc
+	outerSide := arcVectors(n0, n1, style)
+	if cross > 0 {
+		outerSide = arcVectors(n0.Mul(-1), n1.Mul(-1), style)
+	}
+
+	for _, v := range outerSide {
+		if cross > 0 {
+			sections = append(sections, strokeSection{center: p, left: inner, right: v, u: u, alpha: 1})
+		} else {
+			sections = append(sections, strokeSection{center: p, left: v, right: inner.Mul(-1), u: u, alpha: 1})
+		}
+	}
+
+	return sections
+}
+
+// arcVectors returns the unit vectors from one to the other, just the two for bevel joins.
+func arcVectors(from, to vec2, style StrokeStyle) []vec2 {
+	if style.Join != LJ_ROUND {
+		return []vec2{from, to}
+	}
+
+	angle := math.Atan2(float64(from[0]*to[1]-from[1]*to[0]), float64(from.Dot(to)))
+	steps := int(math.Ceil(math.Abs(angle) / (math.Pi / float64(style.RoundSegments))))
+	if steps < 1 {
+		steps = 1
+	}
+
+	vectors := make([]vec2, steps+1)
+	for i := range vectors {
+		vectors[i] = rotate(from, angle*float64(i)/float64(steps))
+	}
+	vectors[steps] = to
+
+	return vectors
+}
+
+// appendCap adds the cap sections at the line end, d points out of the line.
+// Start caps are built from the tip inwards, end caps from the line outwards.
+func appendCap(sections []strokeSection, p, d vec2, u float32, start bool, style StrokeStyle) []strokeSection {
+	n := perpendicular(d)
+	if start {
+		// the left side of the line is on the right when looking out of the start
+		n = n.Mul(-1)
+	}
+
+	var capSections []strokeSection
+	switch style.Cap {
+	case LC_ROUND:
+		steps := (style.RoundSegments + 1) / 2
+		for i := steps; i >= 0; i-- {
+			angle := math.Pi / 2 * float64(i) / float64(steps)
+			sin, cos := math.Sincos(angle)
+			out := d.Mul(float32(sin))
+			capSections = append(capSections, strokeSection{
+				center: p,
+				left:   n.Mul(float32(cos)).Add(out),
+				right:  n.Mul(-float32(cos)).Add(out),
+				alpha:  1,
+			})
+		}
+	default:
+		extent := float32(0)
+		if style.Cap == LC_SQUARE {
+			extent = style.Width / 2
+		}
+
+		if style.Feather > 0 {
+			capSections = append(capSections, strokeSection{center: p.Add(d.Mul(extent + style.Feather/2)), left: n, right: n.Mul(-1), alpha: 0})
+			extent -= style.Feather / 2
+		}
+		capSections = append(capSections, strokeSection{center: p.Add(d.Mul(extent)), left: n, right: n.Mul(-1), alpha: 1})
+	}
+
+	for i := range capSections {
+		capSections[i].u = u
+	}
+
+	if start {
+		return append(sections, capSections...)
+	}
+
+	for i := len(capSections) - 1; i >= 0; i-- {
+		sections = append(sections, capSections[i])
+	}
+	return sections
+}
+
+// emitSections connects the consecutive sections with quads. Without feathering every section has
+// the two edge points, with it four: the transparent outer edge and the opaque inner one on both sides.
+func emitSections(sections []strokeSection, halfWidth, feather float32, vertices []float32, indices []uint32) ([]float32, []uint32) {
+	offsets := []float32{halfWidth, -halfWidth}
+	coverage := []float32{1, 1}
+	if feather > 0 {
+		inner := float32(math.Max(float64(halfWidth-feather/2), 0))
+		// lines thinner than the feather are never fully covered
+		innerCoverage := float32(math.Min(1, float64(halfWidth*2/feather)))
+		offsets = []float32{halfWidth + feather/2, inner, -inner, -halfWidth - feather/2}
+		coverage = []float32{0, innerCoverage, innerCoverage, 0}
+	}
+
+	columns := len(offsets)
+	base := uint32(len(vertices) / StrokeVertexSize)
+	outer := offsets[0]
+
+	for _, section := range sections {
+		for c, offset := range offsets {
+			var position vec2
+			if offset >= 0 {
+				position = section.center.Add(section.left.Mul(offset))
+			} else {
+				position = section.center.Add(section.right.Mul(-offset))
+			}
+
+			v := 0.5 - offset/(2*outer)
+			vertices = append(vertices, position[0], position[1], section.u, v, coverage[c]*section.alpha)
+		}
+	}
+
+	for s := 0; s < len(sections)-1; s++ {
+		for c := 0; c < columns-1; c++ {
+			a := base + uint32(s*columns+c)
+			b := a + uint32(columns)
+			indices = append(indices, a, a+1, b, a+1, b+1, b)
+		}
+	}
+
+	return vertices, indices
+}
+
+type dash struct {
+	points    []vec2
+	direction vec2
+	u         float32
+}
+
+// maxDashes limits the dashes of a stroke, denser patterns can't be told from a solid line anyway
+const maxDashes = 10000
+
+// dashPolyline splits the polyline into the dashes of the pattern.
+// It fails for empty patterns and patterns giving more than maxDashes dashes.
+func dashPolyline(points []vec2, closed bool, pattern []float32, offset float32) ([]dash, bool) {
+	total := float32(0)
+	for _, length := range pattern {
+		total += float32(math.Abs(float64(length)))
+	}
+	if total == 0 {
+		return nil, false
+	}
+
+	if len(pattern)%2 == 1 {
+		// odd patterns repeat with dashes and gaps swapped
+		pattern = append(append([]float32{}, pattern...), pattern...)
+		total *= 2
+	}
+
+	if closed {
+		points = append(append([]vec2{}, points...), points[0])
+	}
+
+	polylineLength := 0.0
+	for i := 0; i < len(points)-1; i++ {
+		polylineLength += float64(points[i+1].Sub(points[i]).Len())
+	}
+	if polylineLength/float64(total)*float64(len(pattern)/2) > maxDashes {
+		return nil, false
+	}
+
+	// position in the pattern
+	index := 0
+	remaining := float32(math.Abs(float64(pattern[0])))
+	offset = float32(math.Mod(float64(offset), float64(total)))
+	if offset < 0 {
+		offset += total
+	}
+	for offset > 0 {
+		if offset < remaining {
+			remaining -= offset
+			break
+		}
+		offset -= remaining
+		index = (index + 1) % len(pattern)
+		remaining = float32(math.Abs(float64(pattern[index])))
+	}
+
+	var dashes []dash
+	var current *dash
+	u := float32(0)
+	for i := 0; i < len(points)-1; i++ {
+		a, b := points[i], points[i+1]
+		segment := b.Sub(a)
+		length := segment.Len()
+		position := float32(0)
+
+		for position < length {
+			on := index%2 == 0
+			step := float32(math.Min(float64(remaining), float64(length-position)))
+			from, to := a.Add(segment.Mul(position/length)), a.Add(segment.Mul((position+step)/length))
+
+			if on {
+				if current == nil {
+					dashes = append(dashes, dash{points: []vec2{from}, direction: segment.Mul(1 / length), u: u + position})
+					current = &dashes[len(dashes)-1]
+				}
+				current.points = append(current.points, to)
+			}
+
+			position += step
+			remaining -= step
+			if remaining <= 0 {
+				if on {
+					current = nil
+				}
+				index = (index + 1) % len(pattern)
+				remaining = float32(math.Abs(float64(pattern[index])))
+			}
+		}
+
+		u += length
+	}
+
+	return dashes, true
+}
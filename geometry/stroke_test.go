@@ -0,0 +1,121 @@
+package geometry
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// strokeArea sums the areas of the stroke triangles and counts the clockwise ones.
+func strokeArea(vertices []float32, indices []uint32) (area float32, clockwise int) {
+	position := func(index uint32) mgl32.Vec2 {
+		return mgl32.Vec2{vertices[index*StrokeVertexSize], vertices[index*StrokeVertexSize+1]}
+	}
+
+	for i := 0; i < len(indices); i += 3 {
+		triangleArea := SignedArea([]mgl32.Vec2{position(indices[i]), position(indices[i+1]), position(indices[i+2])})
+		if triangleArea < -1e-6 {
+			clockwise++
+		}
+		area += triangleArea
+	}
+
+	return
+}
+
+func TestStroke(t *testing.T) {
+	line := []mgl32.Vec2{{0, 0}, {10, 0}}
+	corner := []mgl32.Vec2{{0, 0}, {10, 0}, {10, 10}}
+	square := []mgl32.Vec2{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	sharp := []mgl32.Vec2{{0, 0}, {10, 0}, {0, 1}}
+
+	// the round joins and caps are polygons with RoundSegments segments per half circle, of radius 1 here
+	halfCircle := float32(4 * math.Sin(math.Pi/8))
+
+	tests := []struct {
+		name   string
+		points []mgl32.Vec2
+		closed bool
+		style  StrokeStyle
+		area   float32
+	}{
+		{"butt cap", line, false, StrokeStyle{Width: 2}, 20},
+		{"square cap", line, false, StrokeStyle{Width: 2, Cap: LC_SQUARE}, 24},
+		{"round cap", line, false, StrokeStyle{Width: 2, Cap: LC_ROUND}, 20 + 2*halfCircle},
+		{"miter join", corner, false, StrokeStyle{Width: 2}, 40},
+		{"bevel join", corner, false, StrokeStyle{Width: 2, Join: LJ_BEVEL}, 39.5},
+		{"round join", corner, false, StrokeStyle{Width: 2, Join: LJ_ROUND}, 39 + halfCircle/2},
+		{"miter limit", sharp, false, StrokeStyle{Width: 0.2}, 3.81},
+		{"sharp bevel", sharp, false, StrokeStyle{Width: 0.2, Join: LJ_BEVEL}, 3.81},
+		{"closed", square, true, StrokeStyle{Width: 2}, 80},
+		{"closed with a repeated point", append(square, square[0]), true, StrokeStyle{Width: 2}, 80},
+		{"dashes", line, false, StrokeStyle{Width: 1, Dashes: []float32{2, 2}}, 6},
+		{"dash offset", line, false, StrokeStyle{Width: 1, Dashes: []float32{2, 2}, DashOffset: 1}, 5},
+		{"odd dashes", line, false, StrokeStyle{Width: 1, Dashes: []float32{1}}, 5},
+		{"odd dashes offset", []mgl32.Vec2{{0, 0}, {9, 0}}, false, StrokeStyle{Width: 1, Dashes: []float32{2}, DashOffset: 3}, 4},
+		{"closed dashes", square, true, StrokeStyle{Width: 1, Dashes: []float32{5, 5}}, 20},
+		// dots are a thousandth of the width long
+		{"dots", []mgl32.Vec2{{0, 0}, {12, 0}}, false, StrokeStyle{Width: 1, Cap: LC_ROUND, Dashes: []float32{0, 5}}, 3 * (halfCircle/2 + 1e-3)},
+		{"butt dots", line, false, StrokeStyle{Width: 1, Dashes: []float32{0, 5}}, 0},
+		{"dashes too dense", []mgl32.Vec2{{0, 0}, {1e5, 0}}, false, StrokeStyle{Width: 1, Dashes: []float32{1e-6, 1e-6}}, 1e5},
+		{"closed dashes too dense", square, true, StrokeStyle{Width: 2, Dashes: []float32{1e-6}}, 80},
+		{"empty dash pattern", square, true, StrokeStyle{Width: 2, Dashes: []float32{0, 0}}, 80},
+		{"feather", line, false, StrokeStyle{Width: 2, Feather: 1}, 33},
+		{"single point", []mgl32.Vec2{{1, 1}, {1, 1}}, false, StrokeStyle{Width: 2}, 0},
+		{"zero width", line, false, StrokeStyle{}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vertices, indices := Stroke(test.points, test.closed, test.style)
+			if len(vertices)%StrokeVertexSize != 0 {
+				t.Fatalf("%d floats is not a multiple of the vertex size", len(vertices))
+			}
+
+			area, clockwise := strokeArea(vertices, indices)
+			if clockwise > 0 {
+				t.Errorf("%d clockwise triangles", clockwise)
+			}
+
+			if math.Abs(float64(area-test.area)) > 1e-3*math.Max(1, float64(test.area)) {
+				t.Fatalf("stroke area is %v, expected %v", area, test.area)
+			}
+		})
+	}
+}
+
+func TestStrokeFeather(t *testing.T) {
+	vertices, _ := Stroke([]mgl32.Vec2{{0, 0}, {10, 0}}, false, StrokeStyle{Width: 2, Feather: 1})
+
+	for i := 0; i < len(vertices); i += StrokeVertexSize {
+		x, y, coverage := vertices[i], vertices[i+1], vertices[i+4]
+
+		// the feather is centered on the stroke edge and fades out towards the outside
+		outside := math.Abs(float64(y)) > 1 || x < 0 || x > 10
+		switch {
+		case math.Abs(float64(y)) > 1.5 || x < -0.5 || x > 10.5:
+			t.Errorf("vertex (%v, %v) outside of the feathered stroke", x, y)
+		case outside && coverage != 0:
+			t.Errorf("outer vertex (%v, %v) has coverage %v", x, y, coverage)
+		case !outside && coverage != 1:
+			t.Errorf("inner vertex (%v, %v) has coverage %v", x, y, coverage)
+		}
+	}
+}
+
+func TestStrokeTextureCoordinates(t *testing.T) {
+	vertices, _ := Stroke([]mgl32.Vec2{{0, 0}, {3, 0}, {3, 4}}, false, StrokeStyle{Width: 1, Join: LJ_BEVEL})
+
+	for i := 0; i < len(vertices); i += StrokeVertexSize {
+		u, v := vertices[i+2], vertices[i+3]
+		if u < 0 || u > 7 || v < 0 || v > 1 {
+			t.Errorf("vertex %d has texture coordinates (%v, %v)", i/StrokeVertexSize, u, v)
+		}
+	}
+
+	// u runs along the line, the last section is at the polyline length
+	if u := vertices[len(vertices)-StrokeVertexSize+2]; u != 7 {
+		t.Fatalf("last u is %v, expected 7", u)
+	}
+}
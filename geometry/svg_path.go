@@ -0,0 +1,241 @@
+package geometry
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ParseSVGPath parses the SVG path data, the "d" attribute. All the commands are supported,
+// in absolute and relative forms. The coordinates are kept as they are, SVG has the y axis pointing down.
+func ParseSVGPath(d string) (*Path, error) {
+	parser := svgPathParser{data: d, path: NewPath()}
+	if err := parser.parse(); err != nil {
+		return nil, err
+	}
+
+	return parser.path, nil
+}
+
+type svgPathParser struct {
+	data     string
+	position int
+	path     *Path
+	// lastControl is the last curve control point, reflected by the smooth curve commands
+	lastControl vec2
+	lastCommand byte
+}
+
+func (p *svgPathParser) parse() error {
+	command := byte(0)
+	for {
+		p.skipSeparators()
+		if p.position >= len(p.data) {
+			return nil
+		}
+
+		c := p.data[p.position]
+		if isSVGCommand(c) {
+			command = c
+			p.position++
+		} else if command == 0 || command == 'Z' || command == 'z' {
+			return fmt.Errorf("svg path: expected a command at %d, got %q", p.position, c)
+		}
+
+		if err := p.parseCommand(command); err != nil {
+			return fmt.Errorf("svg path: command %q at %d: %w", command, p.position, err)
+		}
+
+		// coordinates following a move are implicit lines
+		if command == 'M' {
+			command = 'L'
+		} else if command == 'm' {
+			command = 'l'
+		}
+	}
+}
+
+func isSVGCommand(c byte) bool {
+	switch c {
+	case 'M', 'm', 'L', 'l', 'H', 'h', 'V', 'v', 'C', 'c', 'S', 's', 'Q', 'q', 'T', 't', 'A', 'a', 'Z', 'z':
+		return true
+	}
+
+	return false
+}
+
+func (p *svgPathParser) parseCommand(command byte) error {
+	relative := command >= 'a'
+	current := p.path.CurrentPoint()
+	offset := func(x, y float32) (float32, float32) {
+		if relative {
+			return x + current[0], y + current[1]
+		}
+		return x, y
+	}
+
+	upper := command &^ 0x20
+	var numbers []float32
+	var err error
+	switch upper {
+	case 'Z':
+		p.path.Close()
+		p.lastCommand = upper
+		return nil
+	case 'H', 'V':
+		numbers, err = p.numbers(1)
+	case 'M', 'L', 'T':
+		numbers, err = p.numbers(2)
+	case 'S', 'Q':
+		numbers, err = p.numbers(4)
+	case 'C':
+		numbers, err = p.numbers(6)
+	case 'A':
+		numbers, err = p.arcNumbers()
+	}
+	if err != nil {
+		return err
+	}
+
+	// the smooth curves reflect the control point of the previous curve of the same kind
+	reflected := current
+	if (upper == 'S' && (p.lastCommand == 'C' || p.lastCommand == 'S')) ||
+		(upper == 'T' && (p.lastCommand == 'Q' || p.lastCommand == 'T')) {
+		reflected = current.Mul(2).Sub(p.lastControl)
+	}
+
+	switch upper {
+	case 'M':
+		p.path.MoveTo(offset(numbers[0], numbers[1]))
+	case 'L':
+		p.path.LineTo(offset(numbers[0], numbers[1]))
+	case 'H':
+		x, _ := offset(numbers[0], 0)
+		p.path.LineTo(x, current[1])
+	case 'V':
+		_, y := offset(0, numbers[0])
+		p.path.LineTo(current[0], y)
+	case 'C':
+		c1x, c1y := offset(numbers[0], numbers[1])
+		c2x, c2y := offset(numbers[2], numbers[3])
+		x, y := offset(numbers[4], numbers[5])
+		p.path.CubicTo(c1x, c1y, c2x, c2y, x, y)
+		p.lastControl = vec2{c2x, c2y}
+	case 'S':
+		c2x, c2y := offset(numbers[0], numbers[1])
+		x, y := offset(numbers[2], numbers[3])
+		p.path.CubicTo(reflected[0], reflected[1], c2x, c2y, x, y)
+		p.lastControl = vec2{c2x, c2y}
+	case 'Q':
+		cx, cy := offset(numbers[0], numbers[1])
+		x, y := offset(numbers[2], numbers[3])
+		p.path.QuadTo(cx, cy, x, y)
+		p.lastControl = vec2{cx, cy}
+	case 'T':
+		x, y := offset(numbers[0], numbers[1])
+		p.path.QuadTo(reflected[0], reflected[1], x, y)
+		p.lastControl = reflected
+	case 'A':
+		x, y := offset(numbers[5], numbers[6])
+		rotation := float32(float64(numbers[2]) * math.Pi / 180)
+		p.path.ArcTo(numbers[0], numbers[1], rotation, numbers[3] != 0, numbers[4] != 0, x, y)
+	}
+
+	p.lastCommand = upper
+	return nil
+}
+
+func (p *svgPathParser) numbers(count int) ([]float32, error) {
+	numbers := make([]float32, count)
+	for i := range numbers {
+		number, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		numbers[i] = number
+	}
+
+	return numbers, nil
+}
+
+// arcNumbers parses the arc arguments, the flags are single digits and may have no separators, like "0 01".
+func (p *svgPathParser) arcNumbers() ([]float32, error) {
+	numbers, err := p.numbers(3)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < 2; i++ {
+		p.skipSeparators()
+		if p.position >= len(p.data) || (p.data[p.position] != '0' && p.data[p.position] != '1') {
+			return nil, fmt.Errorf("expected an arc flag at %d", p.position)
+		}
+		numbers = append(numbers, float32(p.data[p.position]-'0'))
+		p.position++
+	}
+
+	end, err := p.numbers(2)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(numbers, end...), nil
+}
+
+// number parses a number, numbers may follow each other without separators, like "1-2" or "1.5.5".
+func (p *svgPathParser) number() (float32, error) {
+	p.skipSeparators()
+	start := p.position
+	i := p.position
+	if i < len(p.data) && (p.data[i] == '+' || p.data[i] == '-') {
+		i++
+	}
+
+	digits, dot := 0, false
+	for ; i < len(p.data); i++ {
+		c := p.data[i]
+		if c >= '0' && c <= '9' {
+			digits++
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+	}
+
+	if digits == 0 {
+		return 0, fmt.Errorf("expected a number at %d", start)
+	}
+
+	if i < len(p.data) && (p.data[i] == 'e' || p.data[i] == 'E') {
+		j := i + 1
+		if j < len(p.data) && (p.data[j] == '+' || p.data[j] == '-') {
+			j++
+		}
+		if j < len(p.data) && p.data[j] >= '0' && p.data[j] <= '9' {
+			for j < len(p.data) && p.data[j] >= '0' && p.data[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+
+	value, err := strconv.ParseFloat(p.data[start:i], 32)
+	if err != nil {
+		return 0, err
+	}
+
+	p.position = i
+	return float32(value), nil
+}
+
+func (p *svgPathParser) skipSeparators() {
+	for p.position < len(p.data) {
+		switch p.data[p.position] {
+		case ' ', '\t', '\n', '\r', '\f', ',':
+			p.position++
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package geometry
+
+import "testing"
+
+func TestParseSVGPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		d        string
+		expected *Path
+	}{
+		{"empty", "", NewPath()},
+		{"lines", "M1 2 L3 4 L5 6", NewPath().MoveTo(1, 2).LineTo(3, 4).LineTo(5, 6)},
+		{"relative lines", "m1 2 l3 4 l-1 -1", NewPath().MoveTo(1, 2).LineTo(4, 6).LineTo(3, 5)},
+		{"implicit lines", "M1 2 3 4 m1 1 2 2", NewPath().MoveTo(1, 2).LineTo(3, 4).MoveTo(4, 5).LineTo(6, 7)},
+		{"horizontal and vertical", "M1 2 H5 V7 h-2 v-3", NewPath().MoveTo(1, 2).LineTo(5, 2).LineTo(5, 7).LineTo(3, 7).LineTo(3, 4)},
+		{"close", "M1 2 L3 4 Z l1 1 z", NewPath().MoveTo(1, 2).LineTo(3, 4).Close().LineTo(2, 3).Close()},
+		{"compact numbers", "M1-2L.5.5-1e1,2E-1", NewPath().MoveTo(1, -2).LineTo(0.5, 0.5).LineTo(-10, 0.2)},
+		{"separators", "M 1,2\n\tL\r3 , 4", NewPath().MoveTo(1, 2).LineTo(3, 4)},
+		{"cubic", "M0 0 C1 2 3 4 5 6 c1 1 2 2 3 3", NewPath().MoveTo(0, 0).CubicTo(1, 2, 3, 4, 5, 6).CubicTo(6, 7, 7, 8, 8, 9)},
+		{"smooth cubic", "M0 0 C1 2 3 4 5 6 S9 10 11 12", NewPath().MoveTo(0, 0).CubicTo(1, 2, 3, 4, 5, 6).CubicTo(7, 8, 9, 10, 11, 12)},
+		{"smooth cubic after a line", "M0 0 L1 1 S2 3 4 5", NewPath().MoveTo(0, 0).LineTo(1, 1).CubicTo(1, 1, 2, 3, 4, 5)},
+		{"quad", "M0 0 Q1 2 3 4 q1 1 2 0", NewPath().MoveTo(0, 0).QuadTo(1, 2, 3, 4).QuadTo(4, 5, 5, 4)},
+		{"smooth quad", "M0 0 Q1 2 3 4 T5 6 t1 1", NewPath().MoveTo(0, 0).QuadTo(1, 2, 3, 4).QuadTo(5, 6, 5, 6).QuadTo(5, 6, 6, 7)},
+		{"arc", "M0 0 A10 10 0 0 1 20 0", NewPath().MoveTo(0, 0).ArcTo(10, 10, 0, false, true, 20, 0)},
+		{"arc compact flags", "M0 0 a10 5 90 1020 0", NewPath().MoveTo(0, 0).ArcTo(10, 5, 1.5707964, true, false, 20, 0)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := ParseSVGPath(test.d)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(path.segments) != len(test.expected.segments) {
+				t.Fatalf("expected %d segments, got %d", len(test.expected.segments), len(path.segments))
+			}
+			for i, segment := range path.segments {
+				expected := test.expected.segments[i]
+				if segment.command != expected.command {
+					t.Errorf("segment %d: expected the command %d, got %d", i, expected.command, segment.command)
+				}
+				for j := range segment.points {
+					if !segment.points[j].ApproxEqual(expected.points[j]) {
+						t.Errorf("segment %d: expected the points %v, got %v", i, expected.points, segment.points)
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseSVGPathErrors(t *testing.T) {
+	tests := []string{
+		"1 2",
+		"M1",
+		"M1 x",
+		"M1 2 Z 3 4",
+		"M0 0 C1 2 3 4",
+		"M0 0 A10 10 0 2 0 20 0",
+		"M0 0 A10 10 0 1",
+		"M1 2 X3 4",
+		"M-",
+	}
+
+	for _, d := range tests {
+		if _, err := ParseSVGPath(d); err == nil {
+			t.Errorf("%q: expected an error", d)
+		}
+	}
+}
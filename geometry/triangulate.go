@@ -0,0 +1,278 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type point struct {
+	x, y float64
+}
+
+// vertex is a node of the circular list of the ring being clipped.
+type vertex struct {
+	index      uint32
+	p          point
+	prev, next *vertex
+}
+
+// Triangulate triangulates the polygon by ear clipping, the holes are first bridged into the outline.
+// The returned triangles are counter clockwise and index the polygon Points.
+func Triangulate(polygon Polygon) ([]uint32, error) {
+	if len(polygon.Outline) < 3 {
+		return nil, fmt.Errorf("polygon outline has %d points, at least 3 are needed", len(polygon.Outline))
+	}
+
+	outline := buildRing(polygon.Outline, 0, true)
+
+	offset := uint32(len(polygon.Outline))
+	holes := make([]*vertex, 0, len(polygon.Holes))
+	for i, hole := range polygon.Holes {
+		if len(hole) < 3 {
+			return nil, fmt.Errorf("polygon hole %d has %d points, at least 3 are needed", i, len(hole))
+		}
+
+		holes = append(holes, buildRing(hole, offset, false))
+		offset += uint32(len(hole))
+	}
+
+	// holes are bridged right to left, so the bridges never cross holes merged before
+	rightmost := make([]*vertex, len(holes))
+	for i, hole := range holes {
+		rightmost[i] = rightmostVertex(hole)
+	}
+	sort.Slice(rightmost, func(i, j int) bool {
+		return rightmost[i].p.x > rightmost[j].p.x
+	})
+
+	for _, holeVertex := range rightmost {
+		bridge := findBridge(outline, holeVertex)
+		if bridge == nil {
+			return nil, errors.New("polygon hole is outside of the outline")
+		}
+		splice(bridge, holeVertex)
+	}
+
+	return clipEars(outline, int(offset)+2*len(holes))
+}
+
+// buildRing links the points into a circular list, counter clockwise for outlines and clockwise for holes.
+func buildRing(points []mgl32.Vec2, offset uint32, counterClockwise bool) *vertex {
+	area := 0.0
+	for i := range points {
+		a, b := points[i], points[(i+1)%len(points)]
+		area += float64(a[0])*float64(b[1]) - float64(b[0])*float64(a[1])
+	}
+
+	var first, last *vertex
+	appendVertex := func(i int) {
+		v := &vertex{index: offset + uint32(i), p: point{float64(points[i][0]), float64(points[i][1])}}
+		if first == nil {
+			first, v.prev, v.next = v, v, v
+		} else {
+			v.prev, v.next = last, first
+			last.next, first.prev = v, v
+		}
+		last = v
+	}
+
+	if (area > 0) == counterClockwise {
+		for i := range points {
+			appendVertex(i)
+		}
+	} else {
+		for i := len(points) - 1; i >= 0; i-- {
+			appendVertex(i)
+		}
+	}
+
+	return first
+}
+
+func rightmostVertex(ring *vertex) *vertex {
+	rightmost := ring
+	for v := ring.next; v != ring; v = v.next {
+		if v.p.x > rightmost.p.x || (v.p.x == rightmost.p.x && v.p.y < rightmost.p.y) {
+			rightmost = v
+		}
+	}
+
+	return rightmost
+}
+
+// findBridge finds an outline vertex visible from the hole vertex, by casting a ray to the right
+// and taking the closest edge it hits (David Eberly, Triangulation by Ear Clipping).
+func findBridge(outline *vertex, hole *vertex) *vertex {
+	m := hole.p
+	var candidate *vertex
+	closestX := math.Inf(-1)
+
+	v := outline
+	for {
+		a, b := v.p, v.next.p
+		if m.y <= math.Max(a.y, b.y) && m.y >= math.Min(a.y, b.y) && a.y != b.y {
+			x := a.x + (m.y-a.y)*(b.x-a.x)/(b.y-a.y)
+			if x >= m.x && (candidate == nil || x < closestX) {
+				closestX = x
+				if x == m.x {
+					// the hole touches the outline
+					if m.y == a.y {
+						return v
+					}
+					if m.y == b.y {
+						return v.next
+					}
+				}
+				candidate = v
+				if b.x > a.x {
+					candidate = v.next
+				}
+			}
+		}
+
+		v = v.next
+		if v == outline {
+			break
+		}
+	}
+
+	if candidate == nil {
+		return nil
+	}
+
+	// vertices inside the (hole point, intersection, candidate) triangle can hide the candidate,
+	// the one with the smallest angle to the ray is visible. Earlier bridges duplicate vertices,
+	// only the duplicate whose corner faces the hole point can be used (as in mapbox earcut).
+	intersection := point{closestX, m.y}
+	best := candidate
+	bestTan := math.Inf(1)
+	v = candidate
+	for {
+		if v.p.x > m.x && v.p.x <= candidate.p.x {
+			var inside bool
+			if m.y < candidate.p.y {
+				inside = pointInTriangle(m, intersection, candidate.p, v.p)
+			} else {
+				inside = pointInTriangle(intersection, m, candidate.p, v.p)
+			}
+
+			if inside && locallyInside(v, m) {
+				tan := math.Abs(m.y-v.p.y) / (v.p.x - m.x)
+				if tan < bestTan || (tan == bestTan && (v.p.x < best.p.x || (v.p.x == best.p.x && sectorContainsSector(best, v)))) {
+					best, bestTan = v, tan
+				}
+			}
+		}
+
+		v = v.next
+		if v == candidate {
+			break
+		}
+	}
+
+	return best
+}
+
+// locallyInside checks the direction from the vertex to p is inside the polygon corner at the vertex.
+func locallyInside(v *vertex, p point) bool {
+	if area(v.prev.p, v.p, v.next.p) > 0 {
+		return area(v.p, v.next.p, p) >= 0 && area(v.p, p, v.prev.p) >= 0
+	}
+
+	return area(v.p, v.next.p, p) > 0 || area(v.p, p, v.prev.p) > 0
+}
+
+// sectorContainsSector checks the corner at m contains the corner at v, both at the same point.
+func sectorContainsSector(m, v *vertex) bool {
+	return area(m.prev.p, m.p, v.prev.p) > 0 && area(v.next.p, m.p, m.next.p) > 0
+}
+
+// splice connects the hole to the outline with a zero width bridge, duplicating both bridge vertices.
+func splice(outlineVertex, holeVertex *vertex) {
+	outlineCopy := &vertex{index: outlineVertex.index, p: outlineVertex.p}
+	holeCopy := &vertex{index: holeVertex.index, p: holeVertex.p}
+
+	outlineNext, holePrev := outlineVertex.next, holeVertex.prev
+
+	outlineVertex.next, holeVertex.prev = holeVertex, outlineVertex
+	holePrev.next, holeCopy.prev = holeCopy, holePrev
+	holeCopy.next, outlineCopy.prev = outlineCopy, holeCopy
+	outlineCopy.next, outlineNext.prev = outlineNext, outlineCopy
+}
+
+func clipEars(ring *vertex, count int) ([]uint32, error) {
+	indices := make([]uint32, 0, (count-2)*3)
+
+	v := ring
+	stalled := 0
+	strict := true
+	for count > 3 {
+		if isEar(v, strict) {
+			if area(v.prev.p, v.p, v.next.p) != 0 {
+				indices = append(indices, v.prev.index, v.index, v.next.index)
+			}
+			v.prev.next, v.next.prev = v.next, v.prev
+			v = v.next
+			count--
+			stalled, strict = 0, true
+			continue
+		}
+
+		v = v.next
+		stalled++
+		if stalled > count {
+			if !strict {
+				return nil, errors.New("polygon is not simple")
+			}
+
+			// collinear and duplicated points can leave no strict ears, clipping their flat corners resolves them
+			strict, stalled = false, 0
+		}
+	}
+
+	if area(v.prev.p, v.p, v.next.p) != 0 {
+		indices = append(indices, v.prev.index, v.index, v.next.index)
+	}
+
+	return indices, nil
+}
+
+// isEar checks the corner is convex and no other vertex is inside it.
+// The non strict check also accepts flat corners, like the ones of duplicated points,
+// their triangles have no area so nothing can be inside them.
+func isEar(v *vertex, strict bool) bool {
+	a, b, c := v.prev.p, v.p, v.next.p
+	if corner := area(a, b, c); corner <= 0 {
+		return corner == 0 && !strict
+	}
+
+	for p := v.next.next; p != v.prev; p = p.next {
+		if p.p == a || p.p == b || p.p == c {
+			continue
+		}
+
+		if pointInTriangle(a, b, c, p.p) && isReflex(p) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isReflex(v *vertex) bool {
+	return area(v.prev.p, v.p, v.next.p) < 0
+}
+
+// area returns twice the signed area of the triangle, positive for counter clockwise triangles.
+func area(a, b, c point) float64 {
+	return (b.x-a.x)*(c.y-a.y) - (c.x-a.x)*(b.y-a.y)
+}
+
+// pointInTriangle checks p is inside or on the edges of the counter clockwise triangle.
+func pointInTriangle(a, b, c, p point) bool {
+	return area(a, b, p) >= 0 && area(b, c, p) >= 0 && area(c, a, p) >= 0
+}
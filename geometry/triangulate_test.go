@@ -0,0 +1,163 @@
+package geometry
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func square(x, y, size float32) []mgl32.Vec2 {
+	return []mgl32.Vec2{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}
+}
+
+func TestTriangulate(t *testing.T) {
+	// n points and h holes give n + 2h - 2 triangles, one less when the last one is flat,
+	// like when the bridges end collinear with hole edges
+	tests := []struct {
+		name      string
+		polygon   Polygon
+		triangles int
+		area      float32
+	}{
+		{"triangle", Polygon{Outline: []mgl32.Vec2{{0, 0}, {1, 0}, {0, 1}}}, 1, 0.5},
+		{"clockwise square", Polygon{Outline: []mgl32.Vec2{{0, 0}, {0, 1}, {1, 1}, {1, 0}}}, 2, 1},
+		{"concave", Polygon{Outline: []mgl32.Vec2{{0, 0}, {2, 0}, {2, 2}, {1, 1}, {0, 2}}}, 3, 3},
+		{"one hole", Polygon{Outline: square(0, 0, 100), Holes: [][]mgl32.Vec2{square(40, 40, 20)}}, 8, 9600},
+		{
+			"holes in a column",
+			Polygon{Outline: square(0, 0, 100), Holes: [][]mgl32.Vec2{square(30, 70, 10), square(30, 50, 10)}},
+			13, 9800,
+		},
+		{
+			"holes in a row",
+			Polygon{Outline: square(0, 0, 100), Holes: [][]mgl32.Vec2{square(10, 40, 10), square(40, 40, 10), square(70, 40, 10)}},
+			20, 9700,
+		},
+		// the later holes bridge to vertices already duplicated by the earlier bridges
+		{
+			"shared bridge vertex",
+			Polygon{Outline: square(0, 0, 100), Holes: [][]mgl32.Vec2{square(10, 10, 10), square(30, 70, 10), square(30, 50, 10)}},
+			19, 9700,
+		},
+		{
+			"shared bridge vertex, other order",
+			Polygon{Outline: square(0, 0, 100), Holes: [][]mgl32.Vec2{square(30, 70, 10), square(10, 50, 10), square(10, 10, 10)}},
+			20, 9700,
+		},
+		{
+			"shared bridge vertex, diagonal",
+			Polygon{Outline: square(0, 0, 100), Holes: [][]mgl32.Vec2{square(50, 10, 10), square(10, 50, 10), square(30, 70, 10)}},
+			20, 9700,
+		},
+		{
+			"shared bridge vertex, stacked",
+			Polygon{Outline: square(0, 0, 100), Holes: [][]mgl32.Vec2{square(10, 70, 10), square(10, 50, 10), square(30, 70, 10)}},
+			20, 9700,
+		},
+		{
+			"shared bridge vertex, concave outline",
+			Polygon{
+				Outline: []mgl32.Vec2{{0, 0}, {100, 0}, {100, 40}, {80, 50}, {100, 60}, {100, 100}, {0, 100}},
+				Holes:   [][]mgl32.Vec2{square(10, 10, 10), square(10, 50, 10), square(50, 70, 10), square(50, 30, 10)},
+			},
+			28, 9400,
+		},
+		// the near duplicated and collinear points stall the strict ear search
+		{
+			"near collinear",
+			Polygon{Outline: []mgl32.Vec2{{2.999999, 3}, {6, 1}, {2.999999, 6}, {1.000001, 6}, {0.999999, 6}, {1e-06, 6}}},
+			2, 9,
+		},
+		{
+			"near collinear concave",
+			Polygon{Outline: []mgl32.Vec2{
+				{1e-06, 3}, {1, 3}, {2, 0}, {3.999999, 1}, {4.000001, 2}, {4, 3},
+				{4.000001, 3}, {4.999999, 4}, {2.999999, 4}, {2.000001, 4}, {1.999999, 4},
+			}},
+			7, 10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indices, err := Triangulate(test.polygon)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			points := test.polygon.Points()
+			total := float32(0)
+			for i := 0; i < len(indices); i += 3 {
+				a, b, c := points[indices[i]], points[indices[i+1]], points[indices[i+2]]
+				area := SignedArea([]mgl32.Vec2{a, b, c})
+				if area <= 0 {
+					t.Errorf("triangle %d is degenerate or clockwise", i/3)
+				}
+				total += area
+			}
+
+			// ear clipping keeps the total area even for overlapping triangles, they are checked apart
+			for i := 0; i < len(indices); i += 3 {
+				for j := i + 3; j < len(indices); j += 3 {
+					a := [3]mgl32.Vec2{points[indices[i]], points[indices[i+1]], points[indices[i+2]]}
+					b := [3]mgl32.Vec2{points[indices[j]], points[indices[j+1]], points[indices[j+2]]}
+					if trianglesOverlap(a, b) {
+						t.Errorf("triangles %d and %d overlap", i/3, j/3)
+					}
+				}
+			}
+
+			if math.Abs(float64(total-test.area)) > 1e-3 {
+				t.Fatalf("triangles area is %v, expected %v", total, test.area)
+			}
+
+			if len(indices) != test.triangles*3 {
+				t.Fatalf("%d triangles, expected %d", len(indices)/3, test.triangles)
+			}
+		})
+	}
+}
+
+// trianglesOverlap checks the counter clockwise triangles share some area, by the separating axis test.
+func trianglesOverlap(a, b [3]mgl32.Vec2) bool {
+	separated := func(t, other [3]mgl32.Vec2) bool {
+		for i := range t {
+			edge := t[(i+1)%3].Sub(t[i])
+			normal := mgl32.Vec2{edge[1], -edge[0]}.Normalize()
+
+			// all the other triangle points are outside of the edge, touching is allowed
+			outside := true
+			for _, p := range other {
+				if p.Sub(t[i]).Dot(normal) < -1e-5 {
+					outside = false
+				}
+			}
+			if outside {
+				return true
+			}
+		}
+		return false
+	}
+
+	return !separated(a, b) && !separated(b, a)
+}
+
+func TestTriangulateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		polygon Polygon
+	}{
+		{"degenerate outline", Polygon{Outline: []mgl32.Vec2{{0, 0}, {1, 0}}}},
+		{"degenerate hole", Polygon{Outline: square(0, 0, 10), Holes: [][]mgl32.Vec2{{{1, 1}, {2, 2}}}}},
+		{"hole outside", Polygon{Outline: square(0, 0, 10), Holes: [][]mgl32.Vec2{square(20, 0, 5)}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Triangulate(test.polygon); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -6,5 +6,5 @@ require (
 	github.com/go-gl/gl v0.0.0-20210905235341-f7a045908259
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20210727001814-0db043d8d5be
 	github.com/go-gl/mathgl v1.0.0 // indirect
-	golang.org/x/image v0.0.0-20190321063152-3fc05d484e9f // indirect
+	golang.org/x/image v0.0.0-20190321063152-3fc05d484e9f
 )
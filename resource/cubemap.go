@@ -0,0 +1,220 @@
+package resource
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+const (
+	RT_CUBEMAP core.ResourceType = "cubemap"
+)
+
+// CubemapParams builds a cubemap from six square images, in the +X, -X, +Y, -Y, +Z, -Z order.
+type CubemapParams struct {
+	Faces            [6]string
+	NearestFiltering bool
+	Sampling         TextureSampling
+	SRGB             bool
+}
+
+// CubemapCrossParams builds a cubemap from a single image with the faces in a cross layout.
+// Horizontal (4x3 faces) and vertical (3x4 faces, -Z upside down at the bottom) crosses are detected by the image size.
+type CubemapCrossParams struct {
+	FilePath         string
+	NearestFiltering bool
+	Sampling         TextureSampling
+	SRGB             bool
+}
+
+type CubemapData struct {
+	Id uint32
+	// Size is the width and height of every face
+	Size int32
+	SRGB bool
+}
+
+// Bind binds the cubemap to the texture unit, to be used with a samplerCube uniform.
+func (c CubemapData) Bind(unit uint32) {
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, c.Id)
+	gl.ActiveTexture(gl.TEXTURE0)
+}
+
+func GetEmptyCubemap(uri string) core.Resource {
+	return core.Resource{
+		Type:  RT_CUBEMAP,
+		Uri:   uri,
+		Empty: true,
+		Data:  CubemapData{},
+	}
+}
+
+func GetCubemap(rm *core.ResourceManager, uri string) (CubemapData, error) {
+	rsc, err := getTypedResource(rm, RT_CUBEMAP, uri)
+
+	data, ok := rsc.Data.(CubemapData)
+	if err == nil && !ok {
+		err = fmt.Errorf("resource %q does not hold cubemap data", uri)
+	}
+
+	return data, err
+}
+
+type CubemapLoader struct {
+	vfs *core.VFS
+}
+
+func (l CubemapLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
+	if resourceType != RT_CUBEMAP {
+		return false
+	}
+
+	switch param.(type) {
+	case CubemapParams, CubemapCrossParams:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l CubemapLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
+	var faces [6]image.Image
+	var sampling TextureSampling
+	var srgb bool
+
+	switch params := param.(type) {
+	case CubemapParams:
+		for i, filePath := range params.Faces {
+			img, err := decodeImageFile(l.vfs, filePath)
+			if err != nil {
+				return GetEmptyCubemap(uri), err
+			}
+			faces[i] = img
+		}
+		sampling = params.Sampling.withDefaults(params.NearestFiltering)
+		srgb = params.SRGB
+	case CubemapCrossParams:
+		img, err := decodeImageFile(l.vfs, params.FilePath)
+		if err != nil {
+			return GetEmptyCubemap(uri), err
+		}
+
+		if faces, err = sliceCross(img); err != nil {
+			return GetEmptyCubemap(uri), fmt.Errorf("%s: %w", params.FilePath, err)
+		}
+		sampling = params.Sampling.withDefaults(params.NearestFiltering)
+		srgb = params.SRGB
+	}
+
+	size := faces[0].Bounds().Dx()
+	for i, face := range faces {
+		if face.Bounds().Dx() != size || face.Bounds().Dy() != size {
+			return GetEmptyCubemap(uri), fmt.Errorf("cubemap face %d is %v, expected %dx%d", i, face.Bounds().Size(), size, size)
+		}
+	}
+
+	var textureId uint32
+	gl.GenTextures(1, &textureId)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, textureId)
+	defer gl.BindTexture(gl.TEXTURE_CUBE_MAP, 0)
+
+	// faces are opaque, the alpha mode doesn't matter
+	for i, face := range faces {
+		colorImagePixels(face, true, srgb).upload(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i), int32(size), int32(size))
+	}
+
+	mipLevels := 1
+	if sampling.GenerateMipmaps {
+		mipLevels = MipLevelCount(int32(size), int32(size))
+		gl.GenerateMipmap(gl.TEXTURE_CUBE_MAP)
+	}
+
+	// faces are always clamped, repeating would show seams at the cube edges
+	sampling.WrapS, sampling.WrapT = TW_CLAMP_TO_EDGE, TW_CLAMP_TO_EDGE
+	applyTextureSampling(gl.TEXTURE_CUBE_MAP, sampling, mipLevels)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_WRAP_R, gl.CLAMP_TO_EDGE)
+
+	return core.Resource{
+		Type:  RT_CUBEMAP,
+		Uri:   uri,
+		Empty: false,
+		Size:  EstimateTextureSize(int32(size), int32(size), 4, mipLevels) * 6,
+		Data: CubemapData{
+			Id:   textureId,
+			Size: int32(size),
+			SRGB: srgb,
+		},
+		Unload: func() {
+			gl.DeleteTextures(1, &textureId)
+		},
+	}, nil
+}
+
+func NewCubemapLoader(vfs *core.VFS) CubemapLoader {
+	return CubemapLoader{
+		vfs: vfs,
+	}
+}
+
+// sliceCross cuts the faces out of a horizontal or vertical cross:
+//
+//	    +Y                +Y
+//	-X  +Z  +X  -Z    -X  +Z  +X
+//	    -Y                -Y
+//	                      -Z
+func sliceCross(img image.Image) ([6]image.Image, error) {
+	var faces [6]image.Image
+	source, ok := img.(subImager)
+	if !ok {
+		return faces, fmt.Errorf("unsuported image type: %T", img)
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var size int
+	var cells [6]image.Point
+	vertical := false
+
+	switch {
+	case width*3 == height*4:
+		size = width / 4
+		cells = [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {3, 1}}
+	case width*4 == height*3:
+		size = width / 3
+		cells = [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {1, 3}}
+		vertical = true
+	default:
+		return faces, fmt.Errorf("%dx%d image is not a 4x3 or 3x4 cubemap cross", width, height)
+	}
+
+	if size == 0 {
+		return faces, fmt.Errorf("%dx%d image is too small for a cubemap cross", width, height)
+	}
+
+	for i, cell := range cells {
+		rect := image.Rect(cell.X*size, cell.Y*size, (cell.X+1)*size, (cell.Y+1)*size).Add(bounds.Min)
+		faces[i] = source.SubImage(rect)
+	}
+
+	if vertical {
+		faces[5] = rotate180(faces[5])
+	}
+
+	return faces, nil
+}
+
+func rotate180(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rotated := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			rotated.Set(bounds.Dx()-1-x, bounds.Dy()-1-y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	return rotated
+}
@@ -1,15 +1,17 @@
 package resource
 
-import "embed"
+import (
+	"embed"
+
+	"github.com/ddomurad/goCraft/core"
+)
 
 //go:embed embeded
 var embededResources embed.FS
 
-func readEmbededFileAsString(name string) string {
-	data, err := embededResources.ReadFile("embeded/" + name)
-	if err != nil {
-		return ""
-	}
-
-	return string(data)
+// MountEmbededResources exposes the built-in resources under "embeded/".
+// They are mounted below everything else, so files at the same paths in any other mount,
+// like the working directory, override them.
+func MountEmbededResources(vfs *core.VFS) {
+	vfs.MountBaseFS("", embededResources)
 }
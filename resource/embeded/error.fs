@@ -0,0 +1,9 @@
+#version 330 core
+
+out vec4 FragColor; 
+
+void main() { 
+    vec2 cell = floor(gl_FragCoord.xy / 8.0);
+    float checker = mod(cell.x + cell.y, 2.0);
+    FragColor = mix(vec4(1.0, 0.0, 1.0, 1.0), vec4(0.0, 0.0, 0.0, 1.0), checker); 
+}
@@ -0,0 +1,8 @@
+#version 330 core
+
+in vec4 instanceColor;
+out vec4 FragColor; 
+
+void main() { 
+    FragColor = instanceColor; 
+} 
\ No newline at end of file
@@ -6,10 +6,11 @@ layout (location = 1) in vec2 aTex;
 uniform mat4 uTrans;
 uniform mat4 uProj;
 uniform mat4 uView;
+uniform vec4 uUVRect;
 
 out vec2 texCoord;
 
 void main(){
     gl_Position = uProj * uView * uTrans * vec4(aPos, 1.0);
-    texCoord = aTex;
+    texCoord = uUVRect.xy + aTex * uUVRect.zw;
 }
\ No newline at end of file
@@ -0,0 +1,11 @@
+#version 330 core
+
+uniform sampler2D textSampler;
+
+in vec2 texCoord;
+in vec4 instanceColor;
+out vec4 FragColor; 
+
+void main() { 
+    FragColor = instanceColor*texture(textSampler, texCoord); 
+} 
\ No newline at end of file
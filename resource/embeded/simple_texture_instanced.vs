@@ -0,0 +1,22 @@
+#version 330 core
+
+layout (location = 0) in vec3 aPos;
+layout (location = 1) in vec2 aTex;
+layout (location = 8) in vec3 aInstanceTransform0;
+layout (location = 9) in vec3 aInstanceTransform1;
+layout (location = 10) in vec4 aInstanceColor;
+layout (location = 11) in vec4 aInstanceUVRect;
+
+uniform mat4 uProj;
+uniform mat4 uView;
+
+out vec2 texCoord;
+out vec4 instanceColor;
+
+void main(){
+    vec3 pos = vec3(aPos.xy, 1.0);
+    vec2 world = vec2(dot(aInstanceTransform0, pos), dot(aInstanceTransform1, pos));
+    gl_Position = uProj * uView * vec4(world, aPos.z, 1.0);
+    texCoord = aInstanceUVRect.xy + aTex * aInstanceUVRect.zw;
+    instanceColor = aInstanceColor;
+}
\ No newline at end of file
@@ -0,0 +1,10 @@
+#version 330 core
+
+uniform vec4 uColor;
+
+in float coverage;
+out vec4 FragColor; 
+
+void main() { 
+    FragColor = vec4(uColor.rgb, uColor.a * coverage); 
+} 
\ No newline at end of file
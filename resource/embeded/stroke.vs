@@ -0,0 +1,18 @@
+#version 330 core
+
+layout (location = 0) in vec2 aPos;
+layout (location = 1) in vec2 aTex;
+layout (location = 6) in float aCoverage;
+
+uniform mat4 uTrans;
+uniform mat4 uProj;
+uniform mat4 uView;
+
+out vec2 texCoord;
+out float coverage;
+
+void main(){
+    gl_Position = uProj * uView * uTrans * vec4(aPos, 0.0, 1.0);
+    texCoord = aTex;
+    coverage = aCoverage;
+}
\ No newline at end of file
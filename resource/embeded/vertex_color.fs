@@ -0,0 +1,19 @@
+#version 330 core
+
+uniform vec4 uColor;
+uniform bool uLinear;
+
+in vec4 vertexColor;
+out vec4 FragColor; 
+
+vec3 toLinear(vec3 color) {
+    return mix(color / 12.92, pow((color + 0.055) / 1.055, vec3(2.4)), step(0.04045, color));
+}
+
+void main() { 
+    vec4 color = vertexColor;
+    if (uLinear) {
+        color.rgb = toLinear(color.rgb);
+    }
+    FragColor = color * uColor; 
+} 
\ No newline at end of file
@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/ddomurad/goCraft/gctex"
+)
+
+// RegisterDefaultFormats registers the file formats handled by the built-in loaders,
+// so they can be loaded with ResourceManager.Load.
+func RegisterDefaultFormats(rm *core.ResourceManager) *core.ResourceManager {
+	return rm.
+		RegisterExtension(RT_TEXTURE, TextureFormat, ".png", ".jpg", ".jpeg", ".gif", ".bmp", ".tga", ".webp").
+		RegisterExtension(RT_TEXTURE, TextureFormat, gctex.Extension, ".ktx", ".dds").
+		RegisterMimeType(RT_TEXTURE, TextureFormat, "image/png", "image/jpeg", "image/gif", "image/bmp", "image/webp").
+		RegisterExtension(RT_SHADER, ShaderPairFormat, ".vs", ".fs").
+		RegisterExtension(RT_SHADER, ShaderCombinedFormat, ".glsl").
+		RegisterExtension(RT_MESH, ObjMeshFormat, ".obj").
+		RegisterExtension(RT_MESH, SvgMeshFormat, ".svg")
+}
+
+// TextureFormat accepts TextureParams as options, the file path is filled in.
+func TextureFormat(filePath string, options core.LoaderParam) (core.LoaderParam, error) {
+	params, ok := options.(TextureParams)
+	if !ok && options != nil {
+		return nil, fmt.Errorf("unsuported texture options: %T", options)
+	}
+
+	params.FilePath = filePath
+	return params, nil
+}
+
+// ShaderPairFormat loads a ".vs" and ".fs" file pair sharing the same name,
+// starting from either of them.
+func ShaderPairFormat(filePath string, options core.LoaderParam) (core.LoaderParam, error) {
+	if options != nil {
+		return nil, fmt.Errorf("unsuported shader options: %T", options)
+	}
+
+	basePath := strings.TrimSuffix(filePath, path.Ext(filePath))
+	return ShaderFileSource{
+		VertexShaderPath:   basePath + ".vs",
+		FragmentShaderPath: basePath + ".fs",
+	}, nil
+}
+
+func ShaderCombinedFormat(filePath string, options core.LoaderParam) (core.LoaderParam, error) {
+	if options != nil {
+		return nil, fmt.Errorf("unsuported shader options: %T", options)
+	}
+
+	return ShaderCombinedFileSource{
+		ShaderPath: filePath,
+	}, nil
+}
+
+func ObjMeshFormat(filePath string, options core.LoaderParam) (core.LoaderParam, error) {
+	source, ok := options.(ObjMeshSource)
+	if !ok && options != nil {
+		return nil, fmt.Errorf("unsuported mesh options: %T", options)
+	}
+
+	source.FilePath = filePath
+	return source, nil
+}
+
+func SvgMeshFormat(filePath string, options core.LoaderParam) (core.LoaderParam, error) {
+	source, ok := options.(SvgMeshSource)
+	if !ok && options != nil {
+		return nil, fmt.Errorf("unsuported mesh options: %T", options)
+	}
+
+	source.FilePath = filePath
+	return source, nil
+}
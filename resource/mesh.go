@@ -1,6 +1,10 @@
 package resource
 
 import (
+	"errors"
+	"fmt"
+	"unsafe"
+
 	"github.com/ddomurad/goCraft/core"
 	"github.com/go-gl/gl/v3.3-core/gl"
 )
@@ -12,11 +16,25 @@ const (
 )
 
 type MeshData struct {
-	VAO     uint32
-	VBO     uint32
+	VAO uint32
+	// VBO is the interleaved vertex buffer, or the buffer of the first attribute for the separate layout
+	VBO uint32
+	// VBOs holds all the vertex buffers
+	VBOs    []uint32
 	IBO     uint32
 	VCount  int32
 	Drawing uint32
+	// IndexType is the gl type of the indices, UNSIGNED_SHORT or UNSIGNED_INT (also used when it's zero)
+	IndexType uint32
+	Format    VertexFormat
+	// IndexOffset is the byte offset of the first index and BaseVertex is added to every index,
+	// both are used by ring buffered dynamic meshes
+	IndexOffset int
+	BaseVertex  int32
+	// Instances is the attached per-instance attributes buffer, see AttachInstances
+	Instances *InstanceBuffer
+	// attachment is shared by the copies of the mesh data, it's nil for mesh data created by hand
+	attachment *instanceAttachment
 }
 
 func GetEmptyMesh(uri string) core.Resource {
@@ -29,8 +47,222 @@ func GetEmptyMesh(uri string) core.Resource {
 	}
 }
 
+// EstimateMeshSize returns the memory used by the vertex and index buffers of a mesh.
+func EstimateMeshSize(vertexBytes, indexBytes int) int64 {
+	return int64(vertexBytes) + int64(indexBytes)
+}
+
+// GetMesh returns the mesh data of a loaded mesh resource.
+// If the resource is missing or has a different type, the mesh placeholder is returned along with the error.
+func GetMesh(rm *core.ResourceManager, uri string) (MeshData, error) {
+	rsc, err := getTypedResource(rm, RT_MESH, uri)
+
+	data, ok := meshData(rsc.Data)
+	if err == nil && !ok {
+		err = fmt.Errorf("resource %q does not hold mesh data", uri)
+	}
+
+	return data, err
+}
+
+type MeshHandle struct {
+	core.Handle
+}
+
+// LoadMesh returns a handle to the mesh, loading it first if it's not registered yet.
+func LoadMesh(rm *core.ResourceManager, uri string, param core.LoaderParam) (MeshHandle, error) {
+	handle, err := rm.LoadResource(RT_MESH, uri, param)
+	return MeshHandle{handle}, err
+}
+
+func GetMeshHandle(rm *core.ResourceManager, uri string) (MeshHandle, error) {
+	handle, err := getTypedHandle(rm, RT_MESH, uri)
+	return MeshHandle{handle}, err
+}
+
+// ResolveMesh returns the mesh data referenced by the handle.
+// For stale handles the mesh placeholder is returned along with the error.
+func ResolveMesh(rm *core.ResourceManager, handle MeshHandle) (MeshData, error) {
+	rscData, err := resolveTypedResource(rm, RT_MESH, handle.Handle)
+
+	data, ok := meshData(rscData)
+	if err == nil && !ok {
+		err = errors.New("resource does not hold mesh data")
+	}
+
+	return data, err
+}
+
+// meshData returns the mesh data of static and dynamic meshes.
+func meshData(data core.ResourceData) (MeshData, bool) {
+	switch mesh := data.(type) {
+	case MeshData:
+		return mesh, true
+	case *DynamicMesh:
+		return mesh.MeshData, true
+	default:
+		return MeshData{}, false
+	}
+}
+
+// CreateErrorMesh creates a unit quad used in place of meshes that failed to load.
+func CreateErrorMesh() (core.Resource, error) {
+	verticesData, indices, drawingType := GetQuadVertices()
+	return CreateMesh2dResource("placeholder_mesh", verticesData, indices, drawingType)
+}
+
+// DrawElements draws the mesh, the vertex array has to be bound.
+func (m MeshData) DrawElements() {
+	if m.BaseVertex != 0 {
+		gl.DrawElementsBaseVertex(m.Drawing, m.VCount, m.indexType(), gl.PtrOffset(m.IndexOffset), m.BaseVertex)
+		return
+	}
+
+	gl.DrawElements(m.Drawing, m.VCount, m.indexType(), gl.PtrOffset(m.IndexOffset))
+}
+
+// indexType returns the index type, mesh data created by hand without one has 32 bit indices.
+func (m MeshData) indexType() uint32 {
+	if m.IndexType == 0 {
+		return gl.UNSIGNED_INT
+	}
+
+	return m.IndexType
+}
+
 func (m MeshData) Unload() {
-	gl.DeleteBuffers(1, &m.VBO)
+	if len(m.VBOs) > 0 {
+		gl.DeleteBuffers(int32(len(m.VBOs)), &m.VBOs[0])
+	} else {
+		gl.DeleteBuffers(1, &m.VBO)
+	}
 	gl.DeleteBuffers(1, &m.IBO)
 	gl.DeleteVertexArrays(1, &m.VAO)
 }
+
+// CreateMeshResource creates a mesh from vertex data in the layout described by the format,
+// see CreateMesh.
+func CreateMeshResource(uri string, format VertexFormat, vertexBuffers [][]byte, indexData []uint32, drawingType uint32) (core.Resource, error) {
+	meshData, err := CreateMesh(format, vertexBuffers, indexData, drawingType)
+	if err != nil {
+		return GetEmptyMesh(uri), err
+	}
+
+	vertexBytes := 0
+	for _, buffer := range vertexBuffers {
+		vertexBytes += len(buffer)
+	}
+
+	return core.Resource{
+		Type:  RT_MESH,
+		Uri:   uri,
+		Empty: false,
+		Size:  EstimateMeshSize(vertexBytes, len(indexData)*indexTypeSize(meshData.IndexType)),
+		Data:  meshData,
+		Unload: func() {
+			meshData.Unload()
+		},
+	}, nil
+}
+
+// CreateMesh uploads the vertex buffers, a single one for the interleaved layout or one per attribute.
+// Indices are stored as 16 bit values when all the vertices can be addressed with them.
+func CreateMesh(format VertexFormat, vertexBuffers [][]byte, indexData []uint32, drawingType uint32) (MeshData, error) {
+	return createMesh(format, vertexBuffers, indexData, drawingType, 0)
+}
+
+// createMesh creates the mesh with the given index type, or the smallest one if it's zero.
+func createMesh(format VertexFormat, vertexBuffers [][]byte, indexData []uint32, drawingType uint32, indexType uint32) (MeshData, error) {
+	if err := format.Validate(); err != nil {
+		return MeshData{}, err
+	}
+
+	vertexCount, err := format.VertexCount(vertexBuffers)
+	if err != nil {
+		return MeshData{}, err
+	}
+
+	for _, index := range indexData {
+		if int(index) >= vertexCount {
+			return MeshData{}, fmt.Errorf("index %d out of range of %d vertices", index, vertexCount)
+		}
+	}
+
+	meshData := MeshData{
+		VBOs:       make([]uint32, len(vertexBuffers)),
+		VCount:     int32(len(indexData)),
+		Drawing:    drawingType,
+		IndexType:  indexType,
+		Format:     format,
+		attachment: &instanceAttachment{},
+	}
+	if indexType == 0 {
+		meshData.IndexType = indexTypeFor(vertexCount)
+	}
+
+	gl.GenVertexArrays(1, &meshData.VAO)
+	gl.BindVertexArray(meshData.VAO)
+
+	gl.GenBuffers(int32(len(meshData.VBOs)), &meshData.VBOs[0])
+	for i, buffer := range vertexBuffers {
+		gl.BindBuffer(gl.ARRAY_BUFFER, meshData.VBOs[i])
+		gl.BufferData(gl.ARRAY_BUFFER, len(buffer), glBytes(buffer), gl.STATIC_DRAW)
+	}
+	meshData.VBO = meshData.VBOs[0]
+
+	gl.GenBuffers(1, &meshData.IBO)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, meshData.IBO)
+	uploadIndices(gl.ELEMENT_ARRAY_BUFFER, indexData, meshData.IndexType, gl.STATIC_DRAW)
+
+	format.bindAttributes(meshData.VBOs)
+
+	gl.BindVertexArray(0)
+	return meshData, nil
+}
+
+// indexTypeFor returns the smallest index type addressing all the vertices.
+func indexTypeFor(vertexCount int) uint32 {
+	if vertexCount <= 1<<16 {
+		return gl.UNSIGNED_SHORT
+	}
+
+	return gl.UNSIGNED_INT
+}
+
+func indexTypeSize(indexType uint32) int {
+	if indexType == gl.UNSIGNED_SHORT {
+		return 2
+	}
+
+	return 4
+}
+
+// uploadIndices uploads the indices to the bound buffer, narrowing them to 16 bits if requested.
+func uploadIndices(target uint32, indexData []uint32, indexType uint32, usage uint32) {
+	switch {
+	case len(indexData) == 0:
+		gl.BufferData(target, 0, nil, usage)
+	case indexType == gl.UNSIGNED_SHORT:
+		gl.BufferData(target, len(indexData)*2, gl.Ptr(narrowIndices(indexData)), usage)
+	default:
+		gl.BufferData(target, len(indexData)*4, gl.Ptr(indexData), usage)
+	}
+}
+
+func narrowIndices(indices []uint32) []uint16 {
+	narrowed := make([]uint16, len(indices))
+	for i, index := range indices {
+		narrowed[i] = uint16(index)
+	}
+
+	return narrowed
+}
+
+// glBytes returns a pointer to the data, or nil for empty data which gl.Ptr rejects.
+func glBytes(data []byte) unsafe.Pointer {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return gl.Ptr(data)
+}
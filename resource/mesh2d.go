@@ -1,7 +1,7 @@
 package resource
 
 import (
-	"errors"
+	"log"
 	"math"
 	"strings"
 
@@ -11,6 +11,8 @@ import (
 
 type ProceduralMesh2dLoader struct{}
 
+// The _BORDER variants of the procedural meshes are 1 pixel hairlines drawn as line lists,
+// the line width is not scaled with the mesh. Thick borders are stroked with Renderer2d.DrawShapeBorder.
 const (
 	PMT_QUAD          ProceduralMeshType = "pmt_2d_quad"
 	PMT_QUAD_BORDER   ProceduralMeshType = "pmt_2d_quad_border"
@@ -26,6 +28,8 @@ func (l ProceduralMesh2dLoader) CanLoad(resourceType core.ResourceType, uri stri
 	switch meshType := param.(type) {
 	case ProceduralMeshType:
 		return strings.HasPrefix(string(meshType), "pmt_2d_")
+	case ProceduralMesh2dParams:
+		return strings.HasPrefix(string(meshType.Type), "pmt_2d_")
 	default:
 		return false
 	}
@@ -36,6 +40,14 @@ func (l ProceduralMesh2dLoader) Load(uri string, param core.LoaderParam) (core.R
 	var indices []uint32
 	var drawingType uint32
 
+	if params, ok := param.(ProceduralMesh2dParams); ok {
+		verticesData, indices, drawingType, err := GetShapeVertices(params)
+		if err != nil {
+			return GetEmptyMesh(uri), err
+		}
+		return CreateMesh2dResource(uri, verticesData, indices, drawingType)
+	}
+
 	switch param.(ProceduralMeshType) {
 	case PMT_QUAD:
 		verticesData, indices, drawingType = GetQuadVertices()
@@ -46,7 +58,12 @@ func (l ProceduralMesh2dLoader) Load(uri string, param core.LoaderParam) (core.R
 	case PMT_CIRCLE_BORDER:
 		verticesData, indices, drawingType = GetCircleBorderVertices(24)
 	default:
-		return GetEmptyMesh(uri), errors.New("unsuported procedural mesh type")
+		// the parameterized shapes with their default parameters
+		var err error
+		verticesData, indices, drawingType, err = GetShapeVertices(ProceduralMesh2dParams{Type: param.(ProceduralMeshType)})
+		if err != nil {
+			return GetEmptyMesh(uri), err
+		}
 	}
 
 	return CreateMesh2dResource(uri, verticesData, indices, drawingType)
@@ -56,52 +73,31 @@ func NewProceduralMesh2dLoader() ProceduralMesh2dLoader {
 	return ProceduralMesh2dLoader{}
 }
 
+// CreateMesh2dResource creates a mesh from interleaved VF_POS3_UV2 vertices.
 func CreateMesh2dResource(uri string, verticesData []float32, indexData []uint32, drawingType uint32) (core.Resource, error) {
-	meshData := CreateMesh2d(verticesData, indexData, drawingType)
-
-	return core.Resource{
-		Type:  RT_MESH,
-		Uri:   uri,
-		Empty: false,
-		Data:  meshData,
-		Unload: func() {
-			meshData.Unload()
-		},
-	}, nil
+	return CreateMeshResource(uri, VF_POS3_UV2, [][]byte{Float32Bytes(verticesData)}, indexData, drawingType)
 }
 
+// CreateMesh2d creates a mesh from interleaved VF_POS3_UV2 vertices. Invalid data is logged
+// and results in an empty mesh, use CreateMesh to get the error.
 func CreateMesh2d(verticesData []float32, indexData []uint32, drawingType uint32) MeshData {
-	vao, vbo, ibo := CreateMeshBuffers(verticesData, indexData)
-
-	return MeshData{
-		VAO:     vao,
-		VBO:     vbo,
-		IBO:     ibo,
-		VCount:  int32(len(indexData)),
-		Drawing: drawingType,
+	meshData, err := CreateMesh(VF_POS3_UV2, [][]byte{Float32Bytes(verticesData)}, indexData, drawingType)
+	if err != nil {
+		log.Printf("FAILED! mesh creation failed: %q\n", err)
 	}
+
+	return meshData
 }
 
+// CreateMeshBuffers uploads interleaved VF_POS3_UV2 vertices with 32 bit indices and returns the buffers.
+// Invalid data is logged and results in zero buffers, use CreateMesh to get the error.
 func CreateMeshBuffers(verticesData []float32, indexData []uint32) (vao, vbo, ibo uint32) {
-	gl.GenVertexArrays(1, &vao)
-	gl.BindVertexArray(vao)
-
-	gl.GenBuffers(1, &vbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(verticesData)*4, gl.Ptr(verticesData), gl.STATIC_DRAW)
-
-	gl.GenBuffers(1, &ibo)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ibo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indexData)*4, gl.Ptr(indexData), gl.STATIC_DRAW)
-
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 5*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 5*4, gl.PtrOffset(3*4))
-	gl.EnableVertexAttribArray(1)
+	meshData, err := createMesh(VF_POS3_UV2, [][]byte{Float32Bytes(verticesData)}, indexData, gl.TRIANGLES, gl.UNSIGNED_INT)
+	if err != nil {
+		log.Printf("FAILED! mesh creation failed: %q\n", err)
+	}
 
-	gl.BindVertexArray(0)
-	return
+	return meshData.VAO, meshData.VBO, meshData.IBO
 }
 
 func GetQuadVertices() ([]float32, []uint32, uint32) {
@@ -0,0 +1,386 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	PMT_ROUNDED_RECT        ProceduralMeshType = "pmt_2d_rounded_rect"
+	PMT_ROUNDED_RECT_BORDER ProceduralMeshType = "pmt_2d_rounded_rect_border"
+	PMT_RING                ProceduralMeshType = "pmt_2d_ring"
+	PMT_RING_BORDER         ProceduralMeshType = "pmt_2d_ring_border"
+	// PMT_ARC is a pie slice, or an arc band when InnerRadius is set
+	PMT_ARC             ProceduralMeshType = "pmt_2d_arc"
+	PMT_ARC_BORDER      ProceduralMeshType = "pmt_2d_arc_border"
+	PMT_POLYGON         ProceduralMeshType = "pmt_2d_polygon"
+	PMT_POLYGON_BORDER  ProceduralMeshType = "pmt_2d_polygon_border"
+	PMT_STAR            ProceduralMeshType = "pmt_2d_star"
+	PMT_STAR_BORDER     ProceduralMeshType = "pmt_2d_star_border"
+	PMT_CAPSULE         ProceduralMeshType = "pmt_2d_capsule"
+	PMT_CAPSULE_BORDER  ProceduralMeshType = "pmt_2d_capsule_border"
+	PMT_TRIANGLE        ProceduralMeshType = "pmt_2d_triangle"
+	PMT_TRIANGLE_BORDER ProceduralMeshType = "pmt_2d_triangle_border"
+)
+
+const (
+	defaultShapeSegments  = 24
+	defaultCornerSegments = 6
+)
+
+// ProceduralMesh2dParams builds a parameterized 2D shape. All shapes fit the unit square centered at (0, 0),
+// like the quad, so they are scaled by the draw size. Zero values select the defaults.
+type ProceduralMesh2dParams struct {
+	Type ProceduralMeshType
+	// Segments is the number of segments of a full circle, or of a single corner for
+	// rounded rects and capsules (24 and 6 by default)
+	Segments uint
+	// InnerRadius is the hole radius of rings and arcs, or the inner radius of stars,
+	// as a fraction of the outer radius (0.5 by default for rings and stars)
+	InnerRadius float32
+	// CornerRadius is the rounded rect corner radius (0.1 by default)
+	// or the capsule cap radius along the x axis (0.25 by default), in unit square units.
+	// Capsule caps are round when drawn with width = height * 0.5 / CornerRadius.
+	CornerRadius float32
+	// StartAngle and EndAngle are the arc angles in radians, counter clockwise from the x axis
+	StartAngle float32
+	EndAngle   float32
+	// Sides is the polygon side count (6 by default)
+	Sides uint
+	// Points is the star point count (5 by default)
+	Points uint
+}
+
+type shapePoint [2]float64
+
+// GetShapeVertices builds the vertices of a parameterized shape. Fills are triangle lists, borders are hairline line lists.
+func GetShapeVertices(params ProceduralMesh2dParams) (vertices []float32, indices []uint32, drawingType uint32, err error) {
+	segments := int(params.Segments)
+	innerRadius := float64(params.InnerRadius)
+
+	switch params.Type {
+	case PMT_QUAD, PMT_QUAD_BORDER:
+		vertices, indices, drawingType = GetQuadVertices()
+		if params.Type == PMT_QUAD_BORDER {
+			vertices, indices, drawingType = GetQuadBorderVertices()
+		}
+		return
+	case PMT_CIRCLE:
+		vertices, indices, drawingType = GetCircleVertices(uint(segmentsOrDefault(segments, defaultShapeSegments)))
+		return
+	case PMT_CIRCLE_BORDER:
+		vertices, indices, drawingType = GetCircleBorderVertices(uint(segmentsOrDefault(segments, defaultShapeSegments)))
+		return
+	case PMT_ROUNDED_RECT, PMT_ROUNDED_RECT_BORDER:
+		radius := float64(params.CornerRadius)
+		if radius == 0 {
+			radius = 0.1
+		}
+		if radius < 0 || radius > 0.5 {
+			return nil, nil, 0, fmt.Errorf("invalid corner radius: %f", radius)
+		}
+		outline := roundedRectOutline(radius, radius, segmentsOrDefault(segments, defaultCornerSegments))
+		return closedShape(params.Type == PMT_ROUNDED_RECT_BORDER, outline)
+	case PMT_CAPSULE, PMT_CAPSULE_BORDER:
+		radius := float64(params.CornerRadius)
+		if radius == 0 {
+			radius = 0.25
+		}
+		if radius < 0 || radius > 0.5 {
+			return nil, nil, 0, fmt.Errorf("invalid capsule cap radius: %f", radius)
+		}
+		outline := roundedRectOutline(radius, 0.5, segmentsOrDefault(segments, defaultCornerSegments))
+		return closedShape(params.Type == PMT_CAPSULE_BORDER, outline)
+	case PMT_POLYGON, PMT_POLYGON_BORDER:
+		sides := int(params.Sides)
+		if sides == 0 {
+			sides = 6
+		}
+		if sides < 3 {
+			return nil, nil, 0, fmt.Errorf("polygon needs at least 3 sides, got %d", sides)
+		}
+		outline := arcOutline(0.5, 0.5, math.Pi/2, math.Pi/2+2*math.Pi, sides)
+		return closedShape(params.Type == PMT_POLYGON_BORDER, outline[:sides])
+	case PMT_STAR, PMT_STAR_BORDER:
+		points := int(params.Points)
+		if points == 0 {
+			points = 5
+		}
+		if innerRadius == 0 {
+			innerRadius = 0.5
+		}
+		if points < 2 || innerRadius < 0 || innerRadius > 1 {
+			return nil, nil, 0, fmt.Errorf("invalid star: %d points, %f inner radius", points, innerRadius)
+		}
+		return closedShape(params.Type == PMT_STAR_BORDER, starOutline(points, innerRadius*0.5))
+	case PMT_TRIANGLE, PMT_TRIANGLE_BORDER:
+		outline := []shapePoint{{-0.5, -0.5}, {0.5, -0.5}, {0, 0.5}}
+		return closedShape(params.Type == PMT_TRIANGLE_BORDER, outline)
+	case PMT_RING, PMT_RING_BORDER:
+		if innerRadius == 0 {
+			innerRadius = 0.5
+		}
+		return bandShape(params.Type == PMT_RING_BORDER, 0, 2*math.Pi, innerRadius, segmentsOrDefault(segments, defaultShapeSegments))
+	case PMT_ARC, PMT_ARC_BORDER:
+		start, end := float64(params.StartAngle), float64(params.EndAngle)
+		if start == end {
+			return nil, nil, 0, errors.New("arc start and end angles are the same")
+		}
+		if math.Abs(end-start) > 2*math.Pi {
+			end = start + math.Copysign(2*math.Pi, end-start)
+		}
+
+		// the segment count is for a full circle
+		arcSegments := int(math.Ceil(float64(segmentsOrDefault(segments, defaultShapeSegments)) * math.Abs(end-start) / (2 * math.Pi)))
+		arcSegments = segmentsOrDefault(arcSegments, 1)
+
+		if innerRadius > 0 {
+			return bandShape(params.Type == PMT_ARC_BORDER, start, end, innerRadius, arcSegments)
+		}
+		outline := append([]shapePoint{{0, 0}}, arcOutline(0.5, 0.5, start, end, arcSegments)...)
+		return closedShape(params.Type == PMT_ARC_BORDER, outline)
+	default:
+		return nil, nil, 0, errors.New("unsuported procedural mesh type")
+	}
+}
+
+// GetShapeOutlines returns the closed outlines of a parameterized shape, the border variant has the same outlines.
+// Rings have the outer and the inner circle, the other shapes a single outline.
+func GetShapeOutlines(params ProceduralMesh2dParams) ([][]mgl32.Vec2, error) {
+	outlines, _, err := shapeOutlines(params)
+	return outlines, err
+}
+
+// shapeOutlines builds the outlines of the shape, holes follow the first outline.
+func shapeOutlines(params ProceduralMesh2dParams) (outlines [][]mgl32.Vec2, border bool, err error) {
+	segments := int(params.Segments)
+	innerRadius := float64(params.InnerRadius)
+
+	var outline []shapePoint
+	switch params.Type {
+	case PMT_QUAD, PMT_QUAD_BORDER:
+		outline = []shapePoint{{-0.5, -0.5}, {0.5, -0.5}, {0.5, 0.5}, {-0.5, 0.5}}
+	case PMT_CIRCLE, PMT_CIRCLE_BORDER:
+		segments = segmentsOrDefault(segments, defaultShapeSegments)
+		outline = arcOutline(0.5, 0.5, 0, 2*math.Pi, segments)[:segments]
+	case PMT_ROUNDED_RECT, PMT_ROUNDED_RECT_BORDER:
+		radius := float64(params.CornerRadius)
+		if radius == 0 {
+			radius = 0.1
+		}
+		if radius < 0 || radius > 0.5 {
+			return nil, false, fmt.Errorf("invalid corner radius: %f", radius)
+		}
+		outline = roundedRectOutline(radius, radius, segmentsOrDefault(segments, defaultCornerSegments))
+	case PMT_CAPSULE, PMT_CAPSULE_BORDER:
+		radius := float64(params.CornerRadius)
+		if radius == 0 {
+			radius = 0.25
+		}
+		if radius < 0 || radius > 0.5 {
+			return nil, false, fmt.Errorf("invalid capsule cap radius: %f", radius)
+		}
+		outline = roundedRectOutline(radius, 0.5, segmentsOrDefault(segments, defaultCornerSegments))
+	case PMT_POLYGON, PMT_POLYGON_BORDER:
+		sides := int(params.Sides)
+		if sides == 0 {
+			sides = 6
+		}
+		if sides < 3 {
+			return nil, false, fmt.Errorf("polygon needs at least 3 sides, got %d", sides)
+		}
+		outline = arcOutline(0.5, 0.5, math.Pi/2, math.Pi/2+2*math.Pi, sides)[:sides]
+	case PMT_STAR, PMT_STAR_BORDER:
+		points := int(params.Points)
+		if points == 0 {
+			points = 5
+		}
+		if innerRadius == 0 {
+			innerRadius = 0.5
+		}
+		if points < 2 || innerRadius < 0 || innerRadius > 1 {
+			return nil, false, fmt.Errorf("invalid star: %d points, %f inner radius", points, innerRadius)
+		}
+		outline = starOutline(points, innerRadius*0.5)
+	case PMT_TRIANGLE, PMT_TRIANGLE_BORDER:
+		outline = []shapePoint{{-0.5, -0.5}, {0.5, -0.5}, {0, 0.5}}
+	case PMT_RING, PMT_RING_BORDER:
+		if innerRadius == 0 {
+			innerRadius = 0.5
+		}
+		if innerRadius < 0 || innerRadius >= 1 {
+			return nil, false, fmt.Errorf("invalid inner radius: %f", innerRadius)
+		}
+		segments = segmentsOrDefault(segments, defaultShapeSegments)
+		outer := arcOutline(0.5, 0.5, 0, 2*math.Pi, segments)[:segments]
+		inner := arcOutline(innerRadius*0.5, innerRadius*0.5, 0, 2*math.Pi, segments)[:segments]
+		return [][]mgl32.Vec2{toVec2(outer), toVec2(inner)}, params.Type == PMT_RING_BORDER, nil
+	case PMT_ARC, PMT_ARC_BORDER:
+		start, end := float64(params.StartAngle), float64(params.EndAngle)
+		if start == end {
+			return nil, false, errors.New("arc start and end angles are the same")
+		}
+		if math.Abs(end-start) > 2*math.Pi {
+			end = start + math.Copysign(2*math.Pi, end-start)
+		}
+		if innerRadius < 0 || innerRadius >= 1 {
+			return nil, false, fmt.Errorf("invalid inner radius: %f", innerRadius)
+		}
+
+		// the segment count is for a full circle
+		arcSegments := int(math.Ceil(float64(segmentsOrDefault(segments, defaultShapeSegments)) * math.Abs(end-start) / (2 * math.Pi)))
+		arcSegments = segmentsOrDefault(arcSegments, 1)
+
+		outline = arcOutline(0.5, 0.5, start, end, arcSegments)
+		if innerRadius > 0 {
+			// the band goes along the outer arc and back along the inner one
+			inner := arcOutline(innerRadius*0.5, innerRadius*0.5, start, end, arcSegments)
+			for i := len(inner) - 1; i >= 0; i-- {
+				outline = append(outline, inner[i])
+			}
+		} else {
+			outline = append([]shapePoint{{0, 0}}, outline...)
+		}
+	default:
+		return nil, false, errors.New("unsuported procedural mesh type")
+	}
+
+	border = strings.HasSuffix(string(params.Type), "_border")
+	return [][]mgl32.Vec2{toVec2(outline)}, border, nil
+}
+
+func toVec2(points []shapePoint) []mgl32.Vec2 {
+	vectors := make([]mgl32.Vec2, len(points))
+	for i, p := range points {
+		vectors[i] = mgl32.Vec2{float32(p[0]), float32(p[1])}
+	}
+
+	return vectors
+}
+
+func segmentsOrDefault(segments, defaultSegments int) int {
+	if segments <= 0 {
+		return defaultSegments
+	}
+
+	return segments
+}
+
+// arcOutline returns segments+1 points of the ellipse arc, both ends included.
+func arcOutline(rx, ry, start, end float64, segments int) []shapePoint {
+	points := make([]shapePoint, segments+1)
+	for i := range points {
+		angle := start + (end-start)*float64(i)/float64(segments)
+		points[i] = shapePoint{rx * math.Cos(angle), ry * math.Sin(angle)}
+	}
+
+	return points
+}
+
+// roundedRectOutline returns the outline of the unit square with elliptic corners.
+func roundedRectOutline(rx, ry float64, cornerSegments int) []shapePoint {
+	corners := []shapePoint{{0.5 - rx, 0.5 - ry}, {-0.5 + rx, 0.5 - ry}, {-0.5 + rx, -0.5 + ry}, {0.5 - rx, -0.5 + ry}}
+	outline := make([]shapePoint, 0, len(corners)*(cornerSegments+1))
+	for i, corner := range corners {
+		start := float64(i) * math.Pi / 2
+		for _, p := range arcOutline(rx, ry, start, start+math.Pi/2, cornerSegments) {
+			p = shapePoint{corner[0] + p[0], corner[1] + p[1]}
+			// corners with no radius collapse to a single point
+			if len(outline) == 0 || outline[len(outline)-1] != p {
+				outline = append(outline, p)
+			}
+		}
+	}
+
+	if len(outline) > 1 && outline[0] == outline[len(outline)-1] {
+		outline = outline[:len(outline)-1]
+	}
+
+	return outline
+}
+
+func starOutline(points int, innerRadius float64) []shapePoint {
+	outline := make([]shapePoint, points*2)
+	for i := range outline {
+		radius := 0.5
+		if i%2 == 1 {
+			radius = innerRadius
+		}
+
+		angle := math.Pi/2 + math.Pi*float64(i)/float64(points)
+		outline[i] = shapePoint{radius * math.Cos(angle), radius * math.Sin(angle)}
+	}
+
+	return outline
+}
+
+// appendShapeVertex appends a position with the texture coordinates matching the quad.
+func appendShapeVertex(vertices []float32, p shapePoint) []float32 {
+	return append(vertices, float32(p[0]), float32(p[1]), 0, float32(p[0]+0.5), float32(0.5-p[1]))
+}
+
+// closedShape fills the outline as a fan around the origin, all the shapes are star shaped around it,
+// or outlines it with a closed line loop.
+func closedShape(border bool, outline []shapePoint) ([]float32, []uint32, uint32, error) {
+	vertices := make([]float32, 0, (len(outline)+1)*5)
+	indices := make([]uint32, 0, len(outline)*3)
+
+	if border {
+		for i, p := range outline {
+			vertices = appendShapeVertex(vertices, p)
+			indices = append(indices, uint32(i), uint32((i+1)%len(outline)))
+		}
+		return vertices, indices, gl.LINES, nil
+	}
+
+	vertices = appendShapeVertex(vertices, shapePoint{0, 0})
+	for i, p := range outline {
+		vertices = appendShapeVertex(vertices, p)
+		indices = append(indices, 0, uint32(i+1), uint32((i+1)%len(outline)+1))
+	}
+
+	return vertices, indices, gl.TRIANGLES, nil
+}
+
+// bandShape builds the area between the outer circle and the inner one, for rings and arc bands.
+// Borders of full rings are two loops, borders of arcs close the band ends.
+func bandShape(border bool, start, end, innerRadius float64, segments int) ([]float32, []uint32, uint32, error) {
+	if innerRadius < 0 || innerRadius >= 1 {
+		return nil, nil, 0, fmt.Errorf("invalid inner radius: %f", innerRadius)
+	}
+
+	outer := arcOutline(0.5, 0.5, start, end, segments)
+	inner := arcOutline(innerRadius*0.5, innerRadius*0.5, start, end, segments)
+	full := math.Abs(end-start) >= 2*math.Pi
+
+	vertices := make([]float32, 0, len(outer)*2*5)
+	for i := range outer {
+		vertices = appendShapeVertex(vertices, outer[i])
+		vertices = appendShapeVertex(vertices, inner[i])
+	}
+
+	indices := make([]uint32, 0, segments*6)
+	if border {
+		for i := 0; i < segments; i++ {
+			o, n := uint32(i*2), uint32(i*2+2)
+			indices = append(indices, o, n, o+1, n+1)
+		}
+		if !full {
+			last := uint32(segments * 2)
+			indices = append(indices, 0, 1, last, last+1)
+		}
+		return vertices, indices, gl.LINES, nil
+	}
+
+	for i := 0; i < segments; i++ {
+		o, n := uint32(i*2), uint32(i*2+2)
+		indices = append(indices, o, n, o+1, o+1, n, n+1)
+	}
+
+	return vertices, indices, gl.TRIANGLES, nil
+}
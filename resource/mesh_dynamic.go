@@ -0,0 +1,350 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// DynamicMeshParams creates a mesh which vertices and indices are updated at runtime.
+// Only the interleaved vertex layout is supported.
+type DynamicMeshParams struct {
+	Format  VertexFormat
+	Drawing uint32
+	// VertexCapacity and IndexCapacity are the initial buffer sizes, the buffers grow on demand
+	VertexCapacity int
+	IndexCapacity  int
+	// Vertices and Indices are the optional initial content
+	Vertices []byte
+	Indices  []uint32
+	// RingSegments > 1 keeps that many copies of the buffers, every Update writes to the next one,
+	// so per-frame data doesn't wait for the draws still using the previous frames.
+	// Without it the buffers are orphaned on every Update.
+	RingSegments int
+}
+
+// DynamicMesh is a mesh which content is replaced after creation, like trails or debug geometry.
+type DynamicMesh struct {
+	MeshData
+	vertexCapacity int
+	indexCapacity  int
+	vertexCount    int
+	segments       int
+	segment        int
+	fences         []uintptr
+	// resized reports the new buffers size to the resource manager
+	resized func(size int64)
+}
+
+// VertexCount returns the number of vertices set by the last update.
+func (m *DynamicMesh) VertexCount() int {
+	return m.vertexCount
+}
+
+// Update replaces the mesh content, growing the buffers if needed.
+// The vertices are interleaved in the mesh format.
+func (m *DynamicMesh) Update(vertices []byte, indices []uint32) error {
+	vertexCount, err := m.Format.VertexCount([][]byte{vertices})
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indices {
+		if int(index) >= vertexCount {
+			return fmt.Errorf("index %d out of range of %d vertices", index, vertexCount)
+		}
+	}
+
+	gl.BindVertexArray(0)
+	if vertexCount > m.vertexCapacity || len(indices) > m.indexCapacity {
+		m.grow(vertexCount, len(indices))
+	}
+
+	if m.segments > 1 {
+		m.writeSegment(vertices, indices)
+	} else {
+		m.orphan(vertices, indices)
+	}
+
+	m.vertexCount = vertexCount
+	m.VCount = int32(len(indices))
+	return nil
+}
+
+// UpdateVertices replaces a range of vertices starting at the first vertex, keeping the indices.
+// Ring buffered meshes can only be updated as a whole.
+func (m *DynamicMesh) UpdateVertices(first int, vertices []byte) error {
+	if m.segments > 1 {
+		return errors.New("ring buffered meshes don't support partial updates")
+	}
+
+	count, err := m.Format.VertexCount([][]byte{vertices})
+	if err != nil {
+		return err
+	}
+
+	if first < 0 || first+count > m.vertexCount {
+		return fmt.Errorf("vertex range %d..%d outside of the %d vertices", first, first+count, m.vertexCount)
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.VBO)
+	gl.BufferSubData(gl.ARRAY_BUFFER, first*m.Format.Stride(), len(vertices), gl.Ptr(vertices))
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	return nil
+}
+
+// UpdateIndices replaces a range of indices starting at the first index, keeping the vertices.
+// Ring buffered meshes can only be updated as a whole.
+func (m *DynamicMesh) UpdateIndices(first int, indices []uint32) error {
+	if m.segments > 1 {
+		return errors.New("ring buffered meshes don't support partial updates")
+	}
+
+	if first < 0 || first+len(indices) > int(m.VCount) {
+		return fmt.Errorf("index range %d..%d outside of the %d indices", first, first+len(indices), m.VCount)
+	}
+
+	for _, index := range indices {
+		if int(index) >= m.vertexCount {
+			return fmt.Errorf("index %d out of range of %d vertices", index, m.vertexCount)
+		}
+	}
+
+	if len(indices) == 0 {
+		return nil
+	}
+
+	// the element buffer binding is part of the vertex array state
+	gl.BindVertexArray(m.VAO)
+	indexSize := indexTypeSize(m.IndexType)
+	if m.IndexType == gl.UNSIGNED_SHORT {
+		gl.BufferSubData(gl.ELEMENT_ARRAY_BUFFER, first*indexSize, len(indices)*indexSize, gl.Ptr(narrowIndices(indices)))
+	} else {
+		gl.BufferSubData(gl.ELEMENT_ARRAY_BUFFER, first*indexSize, len(indices)*indexSize, gl.Ptr(indices))
+	}
+	gl.BindVertexArray(0)
+	return nil
+}
+
+// grow reallocates the buffers with at least twice the capacity, dropping their content.
+func (m *DynamicMesh) grow(vertexCount, indexCount int) {
+	for m.vertexCapacity < vertexCount {
+		m.vertexCapacity = core.IfThenElse(m.vertexCapacity > 0, m.vertexCapacity*2, 16).(int)
+	}
+	for m.indexCapacity < indexCount {
+		m.indexCapacity = core.IfThenElse(m.indexCapacity > 0, m.indexCapacity*2, 16).(int)
+	}
+
+	m.IndexType = indexTypeFor(m.vertexCapacity)
+	m.deleteFences()
+	m.segment = 0
+	m.allocate()
+
+	if m.resized != nil {
+		m.resized(m.size())
+	}
+}
+
+// allocate creates the storage of all the segments.
+func (m *DynamicMesh) allocate() {
+	usage := uint32(gl.DYNAMIC_DRAW)
+	if m.segments > 1 {
+		usage = gl.STREAM_DRAW
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.VBO)
+	gl.BufferData(gl.ARRAY_BUFFER, m.vertexCapacity*m.Format.Stride()*m.segments, nil, usage)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	gl.BindVertexArray(m.VAO)
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, m.indexCapacity*indexTypeSize(m.IndexType)*m.segments, nil, usage)
+	gl.BindVertexArray(0)
+}
+
+// orphan replaces the storage, so the driver doesn't wait for the draws using the old content.
+func (m *DynamicMesh) orphan(vertices []byte, indices []uint32) {
+	m.allocate()
+
+	if len(vertices) > 0 {
+		gl.BindBuffer(gl.ARRAY_BUFFER, m.VBO)
+		gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(vertices), gl.Ptr(vertices))
+		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	}
+
+	if len(indices) > 0 {
+		gl.BindVertexArray(m.VAO)
+		if m.IndexType == gl.UNSIGNED_SHORT {
+			gl.BufferSubData(gl.ELEMENT_ARRAY_BUFFER, 0, len(indices)*2, gl.Ptr(narrowIndices(indices)))
+		} else {
+			gl.BufferSubData(gl.ELEMENT_ARRAY_BUFFER, 0, len(indices)*4, gl.Ptr(indices))
+		}
+		gl.BindVertexArray(0)
+	}
+}
+
+// writeSegment writes the content to the next ring segment. The draws issued so far are fenced,
+// the segment is written unsynchronized once the draws which used it last time are done.
+// If they aren't done in time, all the segments are orphaned instead.
+func (m *DynamicMesh) writeSegment(vertices []byte, indices []uint32) {
+	if m.fences[m.segment] == 0 {
+		m.fences[m.segment] = gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+	}
+
+	m.segment = (m.segment + 1) % m.segments
+	if fence := m.fences[m.segment]; fence != 0 {
+		result := gl.ClientWaitSync(fence, gl.SYNC_FLUSH_COMMANDS_BIT, ringFenceTimeout)
+		gl.DeleteSync(fence)
+		m.fences[m.segment] = 0
+
+		if result != gl.ALREADY_SIGNALED && result != gl.CONDITION_SATISFIED {
+			// the segment may still be read, the new storage doesn't need to wait for the draws
+			m.deleteFences()
+			m.segment = 0
+			m.allocate()
+		}
+	}
+
+	vertexSegmentSize := m.vertexCapacity * m.Format.Stride()
+	indexSize := indexTypeSize(m.IndexType)
+	indexSegmentSize := m.indexCapacity * indexSize
+	access := uint32(gl.MAP_WRITE_BIT | gl.MAP_INVALIDATE_RANGE_BIT | gl.MAP_UNSYNCHRONIZED_BIT)
+
+	if len(vertices) > 0 {
+		gl.BindBuffer(gl.ARRAY_BUFFER, m.VBO)
+		writeMapped(gl.ARRAY_BUFFER, m.segment*vertexSegmentSize, vertices, access)
+		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	}
+
+	if len(indices) > 0 {
+		gl.BindVertexArray(m.VAO)
+		if m.IndexType == gl.UNSIGNED_SHORT {
+			writeMapped(gl.ELEMENT_ARRAY_BUFFER, m.segment*indexSegmentSize, Uint16Bytes(narrowIndices(indices)), access)
+		} else {
+			writeMapped(gl.ELEMENT_ARRAY_BUFFER, m.segment*indexSegmentSize, Uint32Bytes(indices), access)
+		}
+		gl.BindVertexArray(0)
+	}
+
+	m.IndexOffset = m.segment * indexSegmentSize
+	m.BaseVertex = int32(m.segment * m.vertexCapacity)
+}
+
+func (m *DynamicMesh) deleteFences() {
+	for i, fence := range m.fences {
+		if fence != 0 {
+			gl.DeleteSync(fence)
+			m.fences[i] = 0
+		}
+	}
+}
+
+// ringFenceTimeout is the max time in nanoseconds to wait for a ring segment to be released
+const ringFenceTimeout = 100000000
+
+// GetDynamicMesh returns a loaded dynamic mesh.
+func GetDynamicMesh(rm *core.ResourceManager, uri string) (*DynamicMesh, error) {
+	rsc, err := getTypedResource(rm, RT_MESH, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	mesh, ok := rsc.Data.(*DynamicMesh)
+	if !ok {
+		return nil, fmt.Errorf("resource %q is not a dynamic mesh", uri)
+	}
+
+	return mesh, nil
+}
+
+type DynamicMeshLoader struct {
+	rm *core.ResourceManager
+}
+
+func (l DynamicMeshLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
+	if resourceType != RT_MESH {
+		return false
+	}
+
+	_, ok := param.(DynamicMeshParams)
+	return ok
+}
+
+func (l DynamicMeshLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
+	params := param.(DynamicMeshParams)
+	if err := params.Format.Validate(); err != nil {
+		return GetEmptyMesh(uri), err
+	}
+
+	if params.Format.Layout != VL_INTERLEAVED {
+		return GetEmptyMesh(uri), errors.New("dynamic meshes only support the interleaved vertex layout")
+	}
+
+	mesh := &DynamicMesh{
+		MeshData: MeshData{
+			VBOs:       make([]uint32, 1),
+			Drawing:    params.Drawing,
+			Format:     params.Format,
+			attachment: &instanceAttachment{},
+		},
+		vertexCapacity: params.VertexCapacity,
+		indexCapacity:  params.IndexCapacity,
+		segments:       core.IfThenElse(params.RingSegments > 1, params.RingSegments, 1).(int),
+	}
+	mesh.fences = make([]uintptr, mesh.segments)
+	mesh.IndexType = indexTypeFor(mesh.vertexCapacity)
+
+	gl.GenVertexArrays(1, &mesh.VAO)
+	gl.BindVertexArray(mesh.VAO)
+
+	gl.GenBuffers(1, &mesh.VBOs[0])
+	mesh.VBO = mesh.VBOs[0]
+	gl.GenBuffers(1, &mesh.IBO)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, mesh.IBO)
+
+	params.Format.bindAttributes(mesh.VBOs)
+	gl.BindVertexArray(0)
+
+	mesh.allocate()
+
+	if len(params.Vertices) > 0 || len(params.Indices) > 0 {
+		if err := mesh.Update(params.Vertices, params.Indices); err != nil {
+			mesh.deleteFences()
+			mesh.Unload()
+			return GetEmptyMesh(uri), err
+		}
+	}
+
+	mesh.resized = func(size int64) {
+		l.rm.UpdateSize(uri, size)
+	}
+
+	return core.Resource{
+		Type:     RT_MESH,
+		Uri:      uri,
+		Empty:    false,
+		Size:     mesh.size(),
+		Volatile: true,
+		Data:     mesh,
+		Unload: func() {
+			mesh.deleteFences()
+			mesh.Unload()
+		},
+	}, nil
+}
+
+// size returns the size of the buffers of all the segments.
+func (m *DynamicMesh) size() int64 {
+	return EstimateMeshSize(m.vertexCapacity*m.Format.Stride()*m.segments, m.indexCapacity*indexTypeSize(m.IndexType)*m.segments)
+}
+
+func NewDynamicMeshLoader(rm *core.ResourceManager) DynamicMeshLoader {
+	return DynamicMeshLoader{
+		rm: rm,
+	}
+}
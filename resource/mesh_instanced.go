@@ -0,0 +1,124 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// InstanceBuffer holds per-instance attributes, like the VF_INSTANCE_2D transforms, colors and uv rects.
+// It's attached to meshes with MeshData.AttachInstances and can be shared by many meshes.
+type InstanceBuffer struct {
+	VBO    uint32
+	Format VertexFormat
+	// Count is the number of instances set by the last update
+	Count    int32
+	capacity int
+}
+
+// NewInstanceBuffer creates an empty instance buffer, the format has to be interleaved
+// and all its attributes need a divisor.
+func NewInstanceBuffer(format VertexFormat) (*InstanceBuffer, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+
+	if format.Layout != VL_INTERLEAVED {
+		return nil, errors.New("instance buffers only support the interleaved vertex layout")
+	}
+
+	for _, attribute := range format.Attributes {
+		if attribute.Divisor == 0 {
+			return nil, fmt.Errorf("instance attribute %q has no divisor", attribute.Name)
+		}
+	}
+
+	buffer := &InstanceBuffer{Format: format}
+	gl.GenBuffers(1, &buffer.VBO)
+	return buffer, nil
+}
+
+// Update replaces the instances, the buffer is orphaned so the draws still using the old content don't stall.
+func (b *InstanceBuffer) Update(data []byte) error {
+	if len(data)%b.Format.Stride() != 0 {
+		return fmt.Errorf("instance data size %d is not a multiple of the %d bytes stride", len(data), b.Format.Stride())
+	}
+
+	if len(data) > b.capacity {
+		b.capacity = len(data) * 2
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.VBO)
+	gl.BufferData(gl.ARRAY_BUFFER, b.capacity, nil, gl.STREAM_DRAW)
+	if len(data) > 0 {
+		gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(data), gl.Ptr(data))
+	}
+
+	b.Count = int32(len(data) / b.Format.Stride())
+	return nil
+}
+
+func (b *InstanceBuffer) Unload() {
+	gl.DeleteBuffers(1, &b.VBO)
+}
+
+// instanceAttachment records the instance buffer set up in a mesh vertex array.
+type instanceAttachment struct {
+	buffer *InstanceBuffer
+}
+
+// AttachInstances binds the instance attributes to the mesh vertex array. The attribute locations can't
+// overlap the mesh vertex format. The buffer isn't owned by the mesh, it's not unloaded with it.
+// The attributes are set up once per mesh and buffer, reloaded meshes get them set up again.
+// They're only enabled by DrawElementsInstanced, so the other draws of the mesh don't read them.
+func (m *MeshData) AttachInstances(buffer *InstanceBuffer) error {
+	if err := validateInstances(m.Format, buffer.Format); err != nil {
+		return err
+	}
+
+	m.Instances = buffer
+	if m.attachment != nil && m.attachment.buffer == buffer {
+		return nil
+	}
+
+	gl.BindVertexArray(m.VAO)
+	buffer.Format.bindAttributes([]uint32{buffer.VBO})
+	buffer.Format.enableAttributes(false)
+	gl.BindVertexArray(0)
+
+	if m.attachment != nil {
+		m.attachment.buffer = buffer
+	}
+	return nil
+}
+
+// validateInstances checks the instance attribute locations don't overlap the mesh ones.
+func validateInstances(meshFormat, instanceFormat VertexFormat) error {
+	for _, attribute := range instanceFormat.Attributes {
+		for _, vertexAttribute := range meshFormat.Attributes {
+			if attribute.Location == vertexAttribute.Location {
+				return fmt.Errorf("instance attribute %q overlaps the mesh attribute %q", attribute.Name, vertexAttribute.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// DrawElementsInstanced draws the attached instances, the vertex array has to be bound.
+func (m MeshData) DrawElementsInstanced() {
+	if m.Instances == nil || m.Instances.Count == 0 {
+		return
+	}
+
+	m.Instances.Format.enableAttributes(true)
+	defer m.Instances.Format.enableAttributes(false)
+
+	if m.BaseVertex != 0 {
+		gl.DrawElementsInstancedBaseVertex(m.Drawing, m.VCount, m.indexType(), gl.PtrOffset(m.IndexOffset), m.Instances.Count, m.BaseVertex)
+		return
+	}
+
+	gl.DrawElementsInstanced(m.Drawing, m.VCount, m.indexType(), gl.PtrOffset(m.IndexOffset), m.Instances.Count)
+}
@@ -0,0 +1,62 @@
+package resource
+
+import "testing"
+
+func TestInstanceBufferUpdate(t *testing.T) {
+	buffer := &InstanceBuffer{Format: VF_INSTANCE_2D, Count: 3}
+	stride := VF_INSTANCE_2D.Stride()
+
+	for _, size := range []int{1, stride - 1, stride + 4, stride*2 + 1} {
+		if err := buffer.Update(make([]byte, size)); err == nil {
+			t.Errorf("%d bytes: expected an error", size)
+		}
+	}
+
+	if buffer.Count != 3 || buffer.capacity != 0 {
+		t.Errorf("expected the rejected updates to keep the buffer, got %d instances and %d bytes", buffer.Count, buffer.capacity)
+	}
+}
+
+func TestNewInstanceBufferErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format VertexFormat
+	}{
+		{"no attributes", VertexFormat{}},
+		{"separate layout", VertexFormat{Layout: VL_SEPARATE, Attributes: VF_INSTANCE_2D.Attributes}},
+		{"no divisor", VertexFormat{Attributes: []VertexAttribute{VA_INSTANCE_COLOR, {Name: "aOffset", Location: 12, Components: 2, Type: AT_FLOAT}}}},
+	}
+
+	for _, test := range tests {
+		if _, err := NewInstanceBuffer(test.format); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestAttachInstancesOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		mesh     VertexFormat
+		overlaps bool
+	}{
+		{"quad", VF_POS3_UV2, false},
+		{"colored", VF_POS3_UV2_COLOR4, false},
+		{"overlapping", VertexFormat{Attributes: []VertexAttribute{VA_POSITION3, {Name: "aWeight", Location: VA_INSTANCE_COLOR.Location, Components: 1, Type: AT_FLOAT}}}, true},
+	}
+
+	for _, test := range tests {
+		err := validateInstances(test.mesh, VF_INSTANCE_2D)
+		if (err != nil) != test.overlaps {
+			t.Errorf("%s: expected the overlap %v, got %v", test.name, test.overlaps, err)
+		}
+
+		// the overlap is rejected before the vertex array is touched
+		if test.overlaps {
+			mesh := MeshData{Format: test.mesh}
+			if err := mesh.AttachInstances(&InstanceBuffer{Format: VF_INSTANCE_2D}); err == nil || mesh.Instances != nil {
+				t.Errorf("%s: expected the attachment to fail, got %v", test.name, err)
+			}
+		}
+	}
+}
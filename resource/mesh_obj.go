@@ -0,0 +1,214 @@
+package resource
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// ObjMeshSource loads the geometry of a Wavefront OBJ file.
+// Positions, texture coordinates and normals are used, polygons are triangulated as fans.
+// Meshes with normals use the VF_POS3_UV2_NORMAL3 format, the other ones VF_POS3_UV2.
+type ObjMeshSource struct {
+	FilePath string
+}
+
+type ObjMeshLoader struct {
+	vfs *core.VFS
+}
+
+func (l ObjMeshLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
+	if resourceType != RT_MESH {
+		return false
+	}
+
+	_, ok := param.(ObjMeshSource)
+	return ok
+}
+
+func (l ObjMeshLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
+	source := param.(ObjMeshSource)
+
+	text, err := l.vfs.ReadFile(source.FilePath)
+	if err != nil {
+		return GetEmptyMesh(uri), err
+	}
+
+	format, verticesData, indices, err := parseObj(text)
+	if err != nil {
+		return GetEmptyMesh(uri), fmt.Errorf("%s: %w", source.FilePath, err)
+	}
+
+	return CreateMeshResource(uri, format, [][]byte{Float32Bytes(verticesData)}, indices, gl.TRIANGLES)
+}
+
+func NewObjMeshLoader(vfs *core.VFS) ObjMeshLoader {
+	return ObjMeshLoader{
+		vfs: vfs,
+	}
+}
+
+func parseObj(text []byte) (VertexFormat, []float32, []uint32, error) {
+	positions := make([][3]float32, 0)
+	uvs := make([][2]float32, 0)
+	normals := make([][3]float32, 0)
+
+	vertices := make([][3]int, 0)
+	indices := make([]uint32, 0)
+	vertexIndices := make(map[[3]int]uint32)
+
+	scanner := bufio.NewScanner(bytes.NewReader(text))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "v":
+			values, err := parseObjFloats(fields[1:], 3)
+			if err != nil {
+				return VertexFormat{}, nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			positions = append(positions, [3]float32{values[0], values[1], values[2]})
+		case "vt":
+			values, err := parseObjFloats(fields[1:], 2)
+			if err != nil {
+				return VertexFormat{}, nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			// OBJ texture space starts at the bottom, textures are uploaded top row first
+			uvs = append(uvs, [2]float32{values[0], 1 - values[1]})
+		case "vn":
+			values, err := parseObjFloats(fields[1:], 3)
+			if err != nil {
+				return VertexFormat{}, nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			normals = append(normals, [3]float32{values[0], values[1], values[2]})
+		case "f":
+			if len(fields) < 4 {
+				return VertexFormat{}, nil, nil, fmt.Errorf("line %d: face with less than 3 vertices", lineNo)
+			}
+
+			face := make([]uint32, 0, len(fields)-1)
+			for _, field := range fields[1:] {
+				key, err := parseObjFaceVertex(field, len(positions), len(uvs), len(normals))
+				if err != nil {
+					return VertexFormat{}, nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+
+				index, ok := vertexIndices[key]
+				if !ok {
+					index = uint32(len(vertices))
+					vertexIndices[key] = index
+					vertices = append(vertices, key)
+				}
+
+				face = append(face, index)
+			}
+
+			for i := 1; i < len(face)-1; i++ {
+				indices = append(indices, face[0], face[i], face[i+1])
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return VertexFormat{}, nil, nil, err
+	}
+
+	format := VF_POS3_UV2
+	if len(normals) > 0 {
+		format = VF_POS3_UV2_NORMAL3
+	}
+
+	verticesData := make([]float32, 0, len(vertices)*format.Stride()/4)
+	for _, key := range vertices {
+		pos := positions[key[0]]
+		var uv [2]float32
+		if key[1] >= 0 {
+			uv = uvs[key[1]]
+		}
+		verticesData = append(verticesData, pos[0], pos[1], pos[2], uv[0], uv[1])
+
+		if len(normals) > 0 {
+			var normal [3]float32
+			if key[2] >= 0 {
+				normal = normals[key[2]]
+			}
+			verticesData = append(verticesData, normal[0], normal[1], normal[2])
+		}
+	}
+
+	return format, verticesData, indices, nil
+}
+
+func parseObjFloats(fields []string, count int) ([]float32, error) {
+	if len(fields) < count {
+		return nil, fmt.Errorf("expected %d values, got %d", count, len(fields))
+	}
+
+	values := make([]float32, count)
+	for i := 0; i < count; i++ {
+		value, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = float32(value)
+	}
+
+	return values, nil
+}
+
+// parseObjFaceVertex parses "v", "v/vt", "v//vn" or "v/vt/vn" into zero based position, uv and normal indices.
+// A missing uv or normal is returned as -1.
+func parseObjFaceVertex(field string, positionCount, uvCount, normalCount int) ([3]int, error) {
+	parts := strings.Split(field, "/")
+
+	position, err := resolveObjIndex(parts[0], positionCount)
+	if err != nil {
+		return [3]int{}, err
+	}
+
+	uv := -1
+	if len(parts) > 1 && parts[1] != "" {
+		uv, err = resolveObjIndex(parts[1], uvCount)
+		if err != nil {
+			return [3]int{}, err
+		}
+	}
+
+	normal := -1
+	if len(parts) > 2 && parts[2] != "" {
+		normal, err = resolveObjIndex(parts[2], normalCount)
+		if err != nil {
+			return [3]int{}, err
+		}
+	}
+
+	return [3]int{position, uv, normal}, nil
+}
+
+func resolveObjIndex(value string, count int) (int, error) {
+	index, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	// negative indices are relative to the end of the list
+	if index < 0 {
+		index = count + index
+	} else {
+		index--
+	}
+
+	if index < 0 || index >= count {
+		return 0, fmt.Errorf("index out of range: %s", value)
+	}
+
+	return index, nil
+}
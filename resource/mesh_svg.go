@@ -0,0 +1,688 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/ddomurad/goCraft/geometry"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SvgMeshSource loads an SVG file as a VF_POS3_UV2_COLOR4 triangle mesh, with the fill and stroke colors
+// in the vertices. The view box is mapped to the unit square centered at (0, 0), like the 2D shapes,
+// so the mesh is drawn with the aspect ratio of the view box when the draw size matches it.
+// The supported subset: paths, basic shapes, groups with transforms, solid fills and strokes, and opacity.
+// Fills follow the fill-rule, intersecting subpaths are filled as separate shapes, and strokes have no anti-aliasing.
+// Gradient and pattern paints are skipped, as are the paths that can't be triangulated.
+type SvgMeshSource struct {
+	FilePath string
+	// Tolerance is the max distance of the flattened curves from the real ones,
+	// as a fraction of the view box size (0.001 by default)
+	Tolerance float32
+}
+
+type SvgMeshLoader struct {
+	vfs *core.VFS
+}
+
+func (l SvgMeshLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
+	if resourceType != RT_MESH {
+		return false
+	}
+
+	_, ok := param.(SvgMeshSource)
+	return ok
+}
+
+func (l SvgMeshLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
+	source := param.(SvgMeshSource)
+
+	text, err := l.vfs.ReadFile(source.FilePath)
+	if err != nil {
+		return GetEmptyMesh(uri), err
+	}
+
+	verticesData, indices, err := ParseSvgMesh(text, source.Tolerance)
+	if err != nil {
+		return GetEmptyMesh(uri), fmt.Errorf("%s: %w", source.FilePath, err)
+	}
+
+	return CreateMeshResource(uri, VF_POS3_UV2_COLOR4, [][]byte{Float32Bytes(verticesData)}, indices, gl.TRIANGLES)
+}
+
+func NewSvgMeshLoader(vfs *core.VFS) SvgMeshLoader {
+	return SvgMeshLoader{
+		vfs: vfs,
+	}
+}
+
+// svgStyle holds the inherited presentation attributes.
+type svgStyle struct {
+	fill   svgPaint
+	stroke svgPaint
+	// color is the current color property, used by the currentColor paints
+	color         core.Color
+	fillRule      geometry.FillRule
+	fillOpacity   float32
+	strokeOpacity float32
+	strokeStyle   geometry.StrokeStyle
+}
+
+// svgPaint is a fill or stroke paint, nothing is painted without a color.
+// The current color paint follows the color property of the element it's used by.
+type svgPaint struct {
+	color        *core.Color
+	currentColor bool
+}
+
+// resolve returns the paint color, nil for none.
+func (p svgPaint) resolve(current core.Color) *core.Color {
+	if p.currentColor {
+		return &current
+	}
+
+	return p.color
+}
+
+// svgState is the state of an element, opacity isn't inherited but multiplies down the tree.
+type svgState struct {
+	transform mgl32.Mat3
+	style     svgStyle
+	opacity   float32
+}
+
+type svgMeshBuilder struct {
+	tolerance float32
+	vertices  []float32
+	indices   []uint32
+}
+
+// ParseSvgMesh builds the interleaved VF_POS3_UV2_COLOR4 vertices of the SVG document, see SvgMeshSource.
+func ParseSvgMesh(text []byte, tolerance float32) ([]float32, []uint32, error) {
+	if tolerance <= 0 {
+		tolerance = 0.001
+	}
+
+	builder := svgMeshBuilder{tolerance: tolerance}
+	decoder := xml.NewDecoder(bytes.NewReader(text))
+	// skips the DTD entities of the files exported by editors
+	decoder.Strict = false
+
+	black := core.Color{0, 0, 0, 1}
+	stack := []svgState{{
+		transform: mgl32.Ident3(),
+		style: svgStyle{
+			fill:          svgPaint{color: &black},
+			color:         black,
+			fillRule:      geometry.FR_NONZERO,
+			fillOpacity:   1,
+			strokeOpacity: 1,
+			strokeStyle:   geometry.StrokeStyle{Width: 1, MiterLimit: 4},
+		},
+		opacity: 1,
+	}}
+	rootFound := false
+	// skipped counts the depth inside of elements which content isn't drawn, like defs
+	skipped := 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if skipped > 0 {
+				skipped++
+				continue
+			}
+
+			attributes := svgAttributes(element.Attr)
+			state, err := stack[len(stack)-1].child(attributes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("<%s>: %w", element.Name.Local, err)
+			}
+
+			if element.Name.Local == "svg" && !rootFound {
+				rootFound = true
+				viewBox, err := svgViewBox(attributes)
+				if err != nil {
+					return nil, nil, err
+				}
+				state.transform = viewBox.Mul3(state.transform)
+			}
+
+			switch element.Name.Local {
+			case "defs", "symbol", "clipPath", "mask", "marker", "pattern", "linearGradient", "radialGradient", "style", "title", "desc", "metadata":
+				skipped = 1
+				continue
+			}
+
+			stack = append(stack, state)
+
+			path, err := svgShapePath(element.Name.Local, attributes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("<%s>: %w", element.Name.Local, err)
+			}
+			if path != nil {
+				builder.addPath(path, state)
+			}
+		case xml.EndElement:
+			if skipped > 0 {
+				skipped--
+				continue
+			}
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if !rootFound {
+		return nil, nil, errors.New("no svg element")
+	}
+
+	return builder.vertices, builder.indices, nil
+}
+
+// svgAttributes collects the element attributes, the declarations of the style attribute override them.
+func svgAttributes(attrs []xml.Attr) map[string]string {
+	attributes := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		attributes[attr.Name.Local] = strings.TrimSpace(attr.Value)
+	}
+
+	for _, declaration := range strings.Split(attributes["style"], ";") {
+		parts := strings.SplitN(declaration, ":", 2)
+		if len(parts) == 2 {
+			attributes[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	return attributes
+}
+
+// child returns the state of a child element with the attributes.
+func (s svgState) child(attributes map[string]string) (svgState, error) {
+	child := s
+	child.style.strokeStyle.Dashes = append([]float32{}, s.style.strokeStyle.Dashes...)
+
+	if value, ok := attributes["transform"]; ok {
+		transform, err := parseSvgTransform(value)
+		if err != nil {
+			return child, err
+		}
+		child.transform = s.transform.Mul3(transform)
+	}
+
+	if value, ok := attributes["color"]; ok && value != "inherit" {
+		paint, err := parseSvgPaint(value)
+		if err != nil {
+			return child, err
+		}
+		// currentColor keeps the inherited color
+		if paint.color == nil && !paint.currentColor {
+			return child, fmt.Errorf("invalid color: %q", value)
+		}
+		if paint.color != nil {
+			child.style.color = *paint.color
+		}
+	}
+
+	for _, paint := range []struct {
+		name  string
+		paint *svgPaint
+	}{{"fill", &child.style.fill}, {"stroke", &child.style.stroke}} {
+		value, ok := attributes[paint.name]
+		if !ok || value == "inherit" {
+			continue
+		}
+
+		parsed, err := parseSvgPaint(value)
+		if err != nil {
+			return child, err
+		}
+		*paint.paint = parsed
+	}
+
+	switch attributes["fill-rule"] {
+	case "nonzero":
+		child.style.fillRule = geometry.FR_NONZERO
+	case "evenodd":
+		child.style.fillRule = geometry.FR_EVEN_ODD
+	}
+
+	numbers := []struct {
+		name  string
+		value *float32
+	}{
+		{"fill-opacity", &child.style.fillOpacity},
+		{"stroke-opacity", &child.style.strokeOpacity},
+		{"stroke-width", &child.style.strokeStyle.Width},
+		{"stroke-miterlimit", &child.style.strokeStyle.MiterLimit},
+		{"stroke-dashoffset", &child.style.strokeStyle.DashOffset},
+	}
+	for _, number := range numbers {
+		if value, ok := attributes[number.name]; ok && value != "inherit" {
+			parsed, err := parseSvgLength(value)
+			if err != nil {
+				return child, fmt.Errorf("%s: %w", number.name, err)
+			}
+			*number.value = parsed
+		}
+	}
+
+	if value, ok := attributes["opacity"]; ok {
+		opacity, err := parseSvgLength(value)
+		if err != nil {
+			return child, fmt.Errorf("opacity: %w", err)
+		}
+		child.opacity *= opacity
+	}
+
+	switch attributes["stroke-linejoin"] {
+	case "miter", "miter-clip", "arcs":
+		child.style.strokeStyle.Join = geometry.LJ_MITER
+	case "bevel":
+		child.style.strokeStyle.Join = geometry.LJ_BEVEL
+	case "round":
+		child.style.strokeStyle.Join = geometry.LJ_ROUND
+	}
+
+	switch attributes["stroke-linecap"] {
+	case "butt":
+		child.style.strokeStyle.Cap = geometry.LC_BUTT
+	case "square":
+		child.style.strokeStyle.Cap = geometry.LC_SQUARE
+	case "round":
+		child.style.strokeStyle.Cap = geometry.LC_ROUND
+	}
+
+	if value, ok := attributes["stroke-dasharray"]; ok && value != "inherit" {
+		child.style.strokeStyle.Dashes = nil
+		if value != "none" {
+			dashes, err := parseSvgNumbers(value)
+			if err != nil {
+				return child, fmt.Errorf("stroke-dasharray: %w", err)
+			}
+			child.style.strokeStyle.Dashes = dashes
+		}
+	}
+
+	return child, nil
+}
+
+// svgViewBox returns the transformation of the view box, or of the document size without it, to the unit square.
+func svgViewBox(attributes map[string]string) (mgl32.Mat3, error) {
+	var box []float32
+	if value, ok := attributes["viewBox"]; ok {
+		var err error
+		if box, err = parseSvgNumbers(value); err != nil || len(box) != 4 {
+			return mgl32.Mat3{}, fmt.Errorf("invalid view box: %q", value)
+		}
+	} else {
+		width, err := parseSvgLength(attributes["width"])
+		if err != nil {
+			return mgl32.Mat3{}, fmt.Errorf("invalid svg width: %w", err)
+		}
+		height, err := parseSvgLength(attributes["height"])
+		if err != nil {
+			return mgl32.Mat3{}, fmt.Errorf("invalid svg height: %w", err)
+		}
+		box = []float32{0, 0, width, height}
+	}
+
+	if box[2] <= 0 || box[3] <= 0 {
+		return mgl32.Mat3{}, fmt.Errorf("invalid svg size: %f x %f", box[2], box[3])
+	}
+
+	// the y axis is flipped, SVG has it pointing down
+	return mgl32.Translate2D(-0.5, 0.5).
+		Mul3(mgl32.Scale2D(1/box[2], -1/box[3])).
+		Mul3(mgl32.Translate2D(-box[0], -box[1])), nil
+}
+
+// svgShapePath builds the path of the drawable elements, nil for the other ones.
+func svgShapePath(name string, attributes map[string]string) (*geometry.Path, error) {
+	number := func(names ...string) ([]float32, error) {
+		values := make([]float32, len(names))
+		for i, name := range names {
+			value, ok := attributes[name]
+			if !ok {
+				continue
+			}
+
+			parsed, err := parseSvgLength(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			values[i] = parsed
+		}
+		return values, nil
+	}
+
+	switch name {
+	case "path":
+		return geometry.ParseSVGPath(attributes["d"])
+	case "rect":
+		values, err := number("x", "y", "width", "height", "rx", "ry")
+		if err != nil {
+			return nil, err
+		}
+		x, y, w, h, rx, ry := values[0], values[1], values[2], values[3], values[4], values[5]
+		if w <= 0 || h <= 0 {
+			return geometry.NewPath(), nil
+		}
+
+		// a single radius applies to both axes
+		if _, ok := attributes["ry"]; !ok {
+			ry = rx
+		}
+		if _, ok := attributes["rx"]; !ok {
+			rx = ry
+		}
+		rx, ry = float32(math.Min(float64(rx), float64(w/2))), float32(math.Min(float64(ry), float64(h/2)))
+
+		path := geometry.NewPath()
+		if rx <= 0 || ry <= 0 {
+			return path.MoveTo(x, y).LineTo(x+w, y).LineTo(x+w, y+h).LineTo(x, y+h).Close(), nil
+		}
+		return path.MoveTo(x+rx, y).
+			LineTo(x+w-rx, y).ArcTo(rx, ry, 0, false, true, x+w, y+ry).
+			LineTo(x+w, y+h-ry).ArcTo(rx, ry, 0, false, true, x+w-rx, y+h).
+			LineTo(x+rx, y+h).ArcTo(rx, ry, 0, false, true, x, y+h-ry).
+			LineTo(x, y+ry).ArcTo(rx, ry, 0, false, true, x+rx, y).
+			Close(), nil
+	case "circle", "ellipse":
+		values, err := number("cx", "cy", "r", "rx", "ry")
+		if err != nil {
+			return nil, err
+		}
+		cx, cy, rx, ry := values[0], values[1], values[3], values[4]
+		if name == "circle" {
+			rx, ry = values[2], values[2]
+		}
+		if rx <= 0 || ry <= 0 {
+			return geometry.NewPath(), nil
+		}
+
+		return geometry.NewPath().
+			MoveTo(cx+rx, cy).
+			ArcTo(rx, ry, 0, false, true, cx-rx, cy).
+			ArcTo(rx, ry, 0, false, true, cx+rx, cy).
+			Close(), nil
+	case "line":
+		values, err := number("x1", "y1", "x2", "y2")
+		if err != nil {
+			return nil, err
+		}
+		return geometry.NewPath().MoveTo(values[0], values[1]).LineTo(values[2], values[3]), nil
+	case "polyline", "polygon":
+		points, err := parseSvgNumbers(attributes["points"])
+		if err != nil {
+			return nil, fmt.Errorf("points: %w", err)
+		}
+
+		path := geometry.NewPath()
+		for i := 0; i+1 < len(points); i += 2 {
+			if i == 0 {
+				path.MoveTo(points[i], points[i+1])
+			} else {
+				path.LineTo(points[i], points[i+1])
+			}
+		}
+		if name == "polygon" && !path.Empty() {
+			path.Close()
+		}
+		return path, nil
+	default:
+		return nil, nil
+	}
+}
+
+// addPath fills and then strokes the path, the polygons that can't be triangulated are skipped.
+func (b *svgMeshBuilder) addPath(path *geometry.Path, state svgState) {
+	if path.Empty() {
+		return
+	}
+
+	if fill := state.style.fill.resolve(state.style.color); fill != nil {
+		color := *fill
+		color[3] *= state.style.fillOpacity * state.opacity
+
+		for _, polygon := range path.Transformed(state.transform).Polygons(b.tolerance, state.style.fillRule) {
+			indices, err := geometry.Triangulate(polygon)
+			if err != nil {
+				log.Printf("FAILED! svg path triangulation failed: %q\n", err)
+				continue
+			}
+
+			b.addTriangles(polygon.Points(), indices, color)
+		}
+	}
+
+	if stroke := state.style.stroke.resolve(state.style.color); stroke != nil && state.style.strokeStyle.Width > 0 {
+		color := *stroke
+		color[3] *= state.style.strokeOpacity * state.opacity
+
+		// strokes are built in the element space, so they follow the transform scale
+		scale := float32(math.Sqrt(math.Abs(float64(state.transform.Mat2().Det()))))
+		if scale == 0 {
+			return
+		}
+
+		vertices, indices := path.Stroke(b.tolerance/scale, state.style.strokeStyle)
+		points := make([]mgl32.Vec2, len(vertices)/geometry.StrokeVertexSize)
+		for i := range points {
+			offset := i * geometry.StrokeVertexSize
+			points[i] = state.transform.Mul3x1(mgl32.Vec3{vertices[offset], vertices[offset+1], 1}).Vec2()
+		}
+
+		b.addTriangles(points, indices, color)
+	}
+}
+
+// addTriangles appends the triangles, the winding is made counter clockwise when the transform mirrors them.
+func (b *svgMeshBuilder) addTriangles(points []mgl32.Vec2, indices []uint32, color core.Color) {
+	base := uint32(len(b.vertices) / (VF_POS3_UV2_COLOR4.Stride() / 4))
+	for _, p := range points {
+		b.vertices = append(b.vertices, p[0], p[1], 0, p[0]+0.5, 0.5-p[1], color[0], color[1], color[2], color[3])
+	}
+
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, c := points[indices[i]], points[indices[i+2]]
+		m := points[indices[i+1]]
+		if (m[0]-a[0])*(c[1]-a[1])-(c[0]-a[0])*(m[1]-a[1]) < 0 {
+			b.indices = append(b.indices, base+indices[i], base+indices[i+2], base+indices[i+1])
+		} else {
+			b.indices = append(b.indices, base+indices[i], base+indices[i+1], base+indices[i+2])
+		}
+	}
+}
+
+// parseSvgPaint parses a fill or stroke color, without a color for none.
+func parseSvgPaint(value string) (svgPaint, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case value == "none" || value == "transparent" || strings.HasPrefix(value, "url("):
+		return svgPaint{}, nil
+	case value == "currentcolor":
+		return svgPaint{currentColor: true}, nil
+	case strings.HasPrefix(value, "#"):
+		hex := value[1:]
+		if len(hex) == 3 || len(hex) == 4 {
+			expanded := make([]byte, 0, len(hex)*2)
+			for i := range hex {
+				expanded = append(expanded, hex[i], hex[i])
+			}
+			hex = string(expanded)
+		}
+		if len(hex) == 6 {
+			hex += "ff"
+		}
+
+		rgba, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 8 {
+			return svgPaint{}, fmt.Errorf("invalid color: %q", value)
+		}
+		return svgPaint{color: &core.Color{
+			float32(rgba>>24&0xff) / 255,
+			float32(rgba>>16&0xff) / 255,
+			float32(rgba>>8&0xff) / 255,
+			float32(rgba&0xff) / 255,
+		}}, nil
+	case strings.HasPrefix(value, "rgb(") || strings.HasPrefix(value, "rgba("):
+		arguments := value[strings.Index(value, "(")+1 : len(value)-1]
+		parts := strings.FieldsFunc(arguments, func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+		if len(parts) < 3 || len(parts) > 4 || !strings.HasSuffix(value, ")") {
+			return svgPaint{}, fmt.Errorf("invalid color: %q", value)
+		}
+
+		color := core.Color{0, 0, 0, 1}
+		for i, part := range parts {
+			scale := float32(255)
+			if i == 3 {
+				scale = 1
+			}
+			if strings.HasSuffix(part, "%") {
+				part, scale = strings.TrimSuffix(part, "%"), 100
+			}
+
+			component, err := strconv.ParseFloat(part, 32)
+			if err != nil {
+				return svgPaint{}, fmt.Errorf("invalid color: %q", value)
+			}
+			color[i] = float32(math.Max(0, math.Min(1, component/float64(scale))))
+		}
+		return svgPaint{color: &color}, nil
+	default:
+		color, ok := svgNamedColors[value]
+		if !ok {
+			return svgPaint{}, fmt.Errorf("unsuported color: %q", value)
+		}
+		return svgPaint{color: &color}, nil
+	}
+}
+
+var svgNamedColors = map[string]core.Color{
+	"black":   {0, 0, 0, 1},
+	"white":   {1, 1, 1, 1},
+	"red":     {1, 0, 0, 1},
+	"lime":    {0, 1, 0, 1},
+	"green":   {0, 128. / 255, 0, 1},
+	"blue":    {0, 0, 1, 1},
+	"yellow":  {1, 1, 0, 1},
+	"cyan":    {0, 1, 1, 1},
+	"aqua":    {0, 1, 1, 1},
+	"magenta": {1, 0, 1, 1},
+	"fuchsia": {1, 0, 1, 1},
+	"gray":    {128. / 255, 128. / 255, 128. / 255, 1},
+	"grey":    {128. / 255, 128. / 255, 128. / 255, 1},
+	"silver":  {192. / 255, 192. / 255, 192. / 255, 1},
+	"maroon":  {128. / 255, 0, 0, 1},
+	"olive":   {128. / 255, 128. / 255, 0, 1},
+	"navy":    {0, 0, 128. / 255, 1},
+	"purple":  {128. / 255, 0, 128. / 255, 1},
+	"teal":    {0, 128. / 255, 128. / 255, 1},
+	"orange":  {1, 165. / 255, 0, 1},
+	"brown":   {165. / 255, 42. / 255, 42. / 255, 1},
+	"pink":    {1, 192. / 255, 203. / 255, 1},
+}
+
+// parseSvgLength parses a number with an optional px unit, percentages are fractions.
+func parseSvgLength(value string) (float32, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	scale := 1.0
+	if strings.HasSuffix(value, "%") {
+		value, scale = strings.TrimSuffix(value, "%"), 0.01
+	}
+
+	number, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number: %q", value)
+	}
+
+	return float32(number * scale), nil
+}
+
+// parseSvgNumbers parses a comma or space separated number list.
+func parseSvgNumbers(value string) ([]float32, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	numbers := make([]float32, len(fields))
+	for i, field := range fields {
+		number, err := parseSvgLength(field)
+		if err != nil {
+			return nil, err
+		}
+		numbers[i] = number
+	}
+
+	return numbers, nil
+}
+
+// parseSvgTransform parses the transform list, like "translate(10 20) rotate(45)".
+func parseSvgTransform(value string) (mgl32.Mat3, error) {
+	transform := mgl32.Ident3()
+	for rest := strings.TrimSpace(value); rest != ""; rest = strings.TrimLeft(rest, " ,\t\n\r") {
+		open, end := strings.Index(rest, "("), strings.Index(rest, ")")
+		if open < 0 || end < open {
+			return transform, fmt.Errorf("invalid transform: %q", value)
+		}
+
+		name := strings.TrimSpace(rest[:open])
+		arguments, err := parseSvgNumbers(rest[open+1 : end])
+		if err != nil {
+			return transform, fmt.Errorf("invalid transform: %q", value)
+		}
+		rest = rest[end+1:]
+
+		argument := func(i int, defaultValue float32) float32 {
+			if i < len(arguments) {
+				return arguments[i]
+			}
+			return defaultValue
+		}
+		toRadians := func(degrees float32) float32 {
+			return degrees * math.Pi / 180
+		}
+
+		var step mgl32.Mat3
+		switch {
+		case name == "matrix" && len(arguments) == 6:
+			step = mgl32.Mat3{arguments[0], arguments[1], 0, arguments[2], arguments[3], 0, arguments[4], arguments[5], 1}
+		case name == "translate" && len(arguments) >= 1:
+			step = mgl32.Translate2D(arguments[0], argument(1, 0))
+		case name == "scale" && len(arguments) >= 1:
+			step = mgl32.Scale2D(arguments[0], argument(1, arguments[0]))
+		case name == "rotate" && len(arguments) >= 1:
+			cx, cy := argument(1, 0), argument(2, 0)
+			step = mgl32.Translate2D(cx, cy).Mul3(mgl32.HomogRotate2D(toRadians(arguments[0]))).Mul3(mgl32.Translate2D(-cx, -cy))
+		case name == "skewX" && len(arguments) == 1:
+			step = mgl32.Mat3{1, 0, 0, float32(math.Tan(float64(toRadians(arguments[0])))), 1, 0, 0, 0, 1}
+		case name == "skewY" && len(arguments) == 1:
+			step = mgl32.Mat3{1, float32(math.Tan(float64(toRadians(arguments[0])))), 0, 0, 1, 0, 0, 0, 1}
+		default:
+			return transform, fmt.Errorf("unsuported transform: %q", name)
+		}
+
+		transform = transform.Mul3(step)
+	}
+
+	return transform, nil
+}
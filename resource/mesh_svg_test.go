@@ -0,0 +1,218 @@
+package resource
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// svgVertexSize is the float count of a VF_POS3_UV2_COLOR4 vertex
+const svgVertexSize = 9
+
+// svgMeshArea sums the triangle areas and checks their winding.
+func svgMeshArea(t *testing.T, vertices []float32, indices []uint32) float32 {
+	t.Helper()
+
+	total := float32(0)
+	for i := 0; i+2 < len(indices); i += 3 {
+		var points [3]mgl32.Vec2
+		for j := range points {
+			offset := int(indices[i+j]) * svgVertexSize
+			points[j] = mgl32.Vec2{vertices[offset], vertices[offset+1]}
+		}
+
+		area := ((points[1][0]-points[0][0])*(points[2][1]-points[0][1]) - (points[2][0]-points[0][0])*(points[1][1]-points[0][1])) / 2
+		if area < 0 {
+			t.Errorf("triangle %d is clockwise", i/3)
+		}
+		total += area
+	}
+
+	return total
+}
+
+func TestParseSvgMesh(t *testing.T) {
+	circleArea := float32(math.Pi / 4)
+	tests := []struct {
+		name  string
+		svg   string
+		area  float32
+		color core.Color
+	}{
+		{"rect", `<svg viewBox="0 0 10 10"><rect width="10" height="10"/></svg>`, 1, core.Color{0, 0, 0, 1}},
+		{"size without a view box", `<svg width="20px" height="10"><rect width="10" height="10" fill="red"/></svg>`, 0.5, core.Color{1, 0, 0, 1}},
+		{"circle", `<svg viewBox="-1 -1 2 2"><circle r="1" fill="#00f"/></svg>`, circleArea, core.Color{0, 0, 1, 1}},
+		{
+			"group transform and opacity",
+			`<svg viewBox="0 0 10 10"><g transform="scale(0.5)" opacity="0.5"><rect width="10" height="10" style="fill: lime; fill-opacity: 0.5"/></g></svg>`,
+			0.25, core.Color{0, 1, 0, 0.25},
+		},
+		{"no fill", `<svg viewBox="0 0 10 10"><rect width="10" height="10" fill="none"/></svg>`, 0, core.Color{}},
+		{"defs are skipped", `<svg viewBox="0 0 10 10"><defs><rect width="10" height="10"/></defs></svg>`, 0, core.Color{}},
+		{
+			"nonzero by default",
+			`<svg viewBox="0 0 10 10"><path d="M0 0 H10 V10 H0 Z M2 2 H8 V8 H2 Z"/></svg>`,
+			1, core.Color{0, 0, 0, 1},
+		},
+		{
+			"nonzero with a reversed subpath",
+			`<svg viewBox="0 0 10 10"><path d="M0 0 H10 V10 H0 Z M2 2 V8 H8 V2 Z"/></svg>`,
+			0.64, core.Color{0, 0, 0, 1},
+		},
+		{
+			"evenodd",
+			`<svg viewBox="0 0 10 10"><g fill-rule="evenodd"><path d="M0 0 H10 V10 H0 Z M2 2 H8 V8 H2 Z"/></g></svg>`,
+			0.64, core.Color{0, 0, 0, 1},
+		},
+		{
+			"overlapping subpaths",
+			`<svg viewBox="0 0 40 20"><path d="M0 10 A10 10 0 1 0 20 10 A10 10 0 1 0 0 10 Z M10 10 A10 10 0 1 0 30 10 A10 10 0 1 0 10 10 Z"/></svg>`,
+			circleArea, core.Color{0, 0, 0, 1},
+		},
+		{
+			"current color",
+			`<svg viewBox="0 0 10 10" color="blue"><rect width="10" height="10" fill="currentColor"/></svg>`,
+			1, core.Color{0, 0, 1, 1},
+		},
+		{
+			"inherited current color",
+			`<svg viewBox="0 0 10 10" color="blue"><g fill="currentColor"><rect width="10" height="10" color="red"/></g></svg>`,
+			1, core.Color{1, 0, 0, 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vertices, indices, err := ParseSvgMesh([]byte(test.svg), 0.0001)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if area := svgMeshArea(t, vertices, indices); math.Abs(float64(area-test.area)) > 1e-3 {
+				t.Errorf("expected the area %v, got %v", test.area, area)
+			}
+
+			for i := 0; i < len(vertices); i += svgVertexSize {
+				color := core.Color{vertices[i+5], vertices[i+6], vertices[i+7], vertices[i+8]}
+				if color != test.color {
+					t.Fatalf("expected the color %v, got %v", test.color, color)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSvgMeshStroke(t *testing.T) {
+	svg := `<svg viewBox="0 0 10 10"><line x1="0" y1="5" x2="10" y2="5" stroke="red" stroke-width="2"/></svg>`
+	vertices, indices, err := ParseSvgMesh([]byte(svg), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if area := svgMeshArea(t, vertices, indices); math.Abs(float64(area-0.2)) > 1e-3 {
+		t.Errorf("expected the area 0.2, got %v", area)
+	}
+}
+
+func TestParseSvgMeshErrors(t *testing.T) {
+	tests := []string{
+		`<rect width="10" height="10"/>`,
+		`<svg viewBox="0 0 10"></svg>`,
+		`<svg viewBox="0 0 0 10"></svg>`,
+		`<svg viewBox="0 0 10 10"><rect width="10" height="10" fill="banana"/></svg>`,
+		`<svg viewBox="0 0 10 10"><path d="M0 0 L"/></svg>`,
+		`<svg viewBox="0 0 10 10"><g transform="spin(10)"/></svg>`,
+		`<svg viewBox="0 0 10 10" color="none"></svg>`,
+	}
+
+	for _, svg := range tests {
+		if _, _, err := ParseSvgMesh([]byte(svg), 0); err == nil {
+			t.Errorf("%s: expected an error", svg)
+		}
+	}
+}
+
+func TestParseSvgPaint(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected svgPaint
+	}{
+		{"none", svgPaint{}},
+		{"transparent", svgPaint{}},
+		{"url(#gradient)", svgPaint{}},
+		{"currentColor", svgPaint{currentColor: true}},
+		{"#fff", svgPaint{color: &core.Color{1, 1, 1, 1}}},
+		{"#ff000080", svgPaint{color: &core.Color{1, 0, 0, 128. / 255}}},
+		{"#0f08", svgPaint{color: &core.Color{0, 1, 0, 136. / 255}}},
+		{" RED ", svgPaint{color: &core.Color{1, 0, 0, 1}}},
+		{"rgb(255, 0, 51)", svgPaint{color: &core.Color{1, 0, 0.2, 1}}},
+		{"rgba(0 0 255 / 0.5)", svgPaint{color: &core.Color{0, 0, 1, 0.5}}},
+		{"rgb(100%,0%,50%)", svgPaint{color: &core.Color{1, 0, 0.5, 1}}},
+		{"rgb(300,-5,0)", svgPaint{color: &core.Color{1, 0, 0, 1}}},
+	}
+
+	for _, test := range tests {
+		paint, err := parseSvgPaint(test.value)
+		if err != nil {
+			t.Errorf("%q: %v", test.value, err)
+			continue
+		}
+
+		if paint.currentColor != test.expected.currentColor || (paint.color == nil) != (test.expected.color == nil) {
+			t.Errorf("%q: expected %+v, got %+v", test.value, test.expected, paint)
+			continue
+		}
+		if paint.color != nil && !mgl32.Vec4(*paint.color).ApproxEqual(mgl32.Vec4(*test.expected.color)) {
+			t.Errorf("%q: expected the color %v, got %v", test.value, *test.expected.color, *paint.color)
+		}
+	}
+
+	for _, value := range []string{"#12", "#gggggg", "rgb(1,2)", "rgb(1,2,3", "rgb(a,b,c)", "banana"} {
+		if _, err := parseSvgPaint(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+func TestParseSvgTransform(t *testing.T) {
+	tests := []struct {
+		value    string
+		point    mgl32.Vec2
+		expected mgl32.Vec2
+	}{
+		{"", mgl32.Vec2{1, 2}, mgl32.Vec2{1, 2}},
+		{"translate(10 20)", mgl32.Vec2{1, 2}, mgl32.Vec2{11, 22}},
+		{"translate(10)", mgl32.Vec2{1, 2}, mgl32.Vec2{11, 2}},
+		{"scale(2)", mgl32.Vec2{1, 2}, mgl32.Vec2{2, 4}},
+		{"scale(2, -1)", mgl32.Vec2{1, 2}, mgl32.Vec2{2, -2}},
+		{"rotate(90)", mgl32.Vec2{1, 0}, mgl32.Vec2{0, 1}},
+		{"rotate(90 1 1)", mgl32.Vec2{2, 1}, mgl32.Vec2{1, 2}},
+		{"matrix(1 2 3 4 5 6)", mgl32.Vec2{1, 1}, mgl32.Vec2{9, 12}},
+		{"skewX(45)", mgl32.Vec2{0, 1}, mgl32.Vec2{1, 1}},
+		{"skewY(45)", mgl32.Vec2{1, 0}, mgl32.Vec2{1, 1}},
+		// the transforms apply right to left
+		{"translate(10,0) scale(2)", mgl32.Vec2{1, 1}, mgl32.Vec2{12, 2}},
+		{"scale(2),translate(10 0)", mgl32.Vec2{1, 1}, mgl32.Vec2{22, 2}},
+	}
+
+	for _, test := range tests {
+		transform, err := parseSvgTransform(test.value)
+		if err != nil {
+			t.Errorf("%q: %v", test.value, err)
+			continue
+		}
+
+		point := transform.Mul3x1(mgl32.Vec3{test.point[0], test.point[1], 1}).Vec2()
+		if point.Sub(test.expected).Len() > 1e-5 {
+			t.Errorf("%q: expected %v, got %v", test.value, test.expected, point)
+		}
+	}
+
+	for _, value := range []string{"spin(10)", "translate(", "scale()", "matrix(1 2 3)", "rotate(a)", "translate(1) x"} {
+		if _, err := parseSvgTransform(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
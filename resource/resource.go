@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/ddomurad/goCraft/core"
+)
+
+// getTypedResource looks up a resource and checks its type.
+// On failure the placeholder of the requested type (if any) is returned together with the error.
+func getTypedResource(rm *core.ResourceManager, resourceType core.ResourceType, uri string) (core.Resource, error) {
+	rsc, err := rm.GetResource(uri)
+	if err == nil && rsc.Type != resourceType {
+		err = fmt.Errorf("resource %q is of type %q, expected %q", uri, rsc.Type, resourceType)
+	}
+
+	if err != nil {
+		placeholder, _ := rm.GetPlaceholder(resourceType)
+		return placeholder, err
+	}
+
+	return rsc, nil
+}
+
+// resolveTypedResource resolves a handle and checks the resource type.
+// On failure the placeholder of the requested type (if any) is returned together with the error.
+func resolveTypedResource(rm *core.ResourceManager, resourceType core.ResourceType, handle core.Handle) (core.ResourceData, error) {
+	rsc, err := rm.Resolve(handle)
+	if err == nil && rsc.Type != resourceType {
+		err = fmt.Errorf("resource %q is of type %q, expected %q", rsc.Uri, rsc.Type, resourceType)
+	}
+
+	if err != nil {
+		placeholder, _ := rm.GetPlaceholder(resourceType)
+		return placeholder.Data, err
+	}
+
+	return rsc.Data, nil
+}
+
+func getTypedHandle(rm *core.ResourceManager, resourceType core.ResourceType, uri string) (core.Handle, error) {
+	rsc, err := rm.GetResource(uri)
+	if err != nil {
+		return core.Handle{}, err
+	}
+
+	if rsc.Type != resourceType {
+		return core.Handle{}, fmt.Errorf("resource %q is of type %q, expected %q", uri, rsc.Type, resourceType)
+	}
+
+	return rm.GetHandle(uri)
+}
@@ -2,7 +2,8 @@ package resource
 
 import (
 	"errors"
-	"io/ioutil"
+	"fmt"
+	"strings"
 
 	"github.com/ddomurad/goCraft/core"
 	"github.com/go-gl/gl/v3.3-core/gl"
@@ -19,6 +20,12 @@ type ShaderFileSource struct {
 	FragmentShaderPath string
 }
 
+// ShaderCombinedFileSource is a single file holding both shader stages.
+// Stages start with a "#type vertex" or "#type fragment" line ("#shader" works as well).
+type ShaderCombinedFileSource struct {
+	ShaderPath string
+}
+
 type EmbededShaderSource struct {
 	ShaderName string
 }
@@ -30,6 +37,14 @@ const (
 type ShaderData struct {
 	ProgramId        uint32
 	uniformLocations map[string]int32
+	// uvRectLocation is looked up at link time, the texture region is set on every texture bind
+	uvRectLocation int32
+	textured       bool
+}
+
+// Textured reports whether the shader samples the bound texture through the textSampler uniform.
+func (s *ShaderData) Textured() bool {
+	return s.textured
 }
 
 func (s *ShaderData) GetUniformLocation(name string) int32 {
@@ -48,6 +63,44 @@ func (s *ShaderData) GetUniformLocation(name string) int32 {
 	return loc
 }
 
+// GetAttributeLocation returns the location of the vertex shader input, or -1 if it's not used by the shader.
+func (s *ShaderData) GetAttributeLocation(name string) int32 {
+	return gl.GetAttribLocation(s.ProgramId, gl.Str(name+"\x00"))
+}
+
+// CheckVertexFormat checks the inputs of the shader are provided by the vertex format, at the same locations.
+func (s *ShaderData) CheckVertexFormat(format VertexFormat) error {
+	var count, maxLength int32
+	gl.GetProgramiv(s.ProgramId, gl.ACTIVE_ATTRIBUTES, &count)
+	gl.GetProgramiv(s.ProgramId, gl.ACTIVE_ATTRIBUTE_MAX_LENGTH, &maxLength)
+	if maxLength == 0 {
+		return nil
+	}
+
+	nameBuffer := make([]uint8, maxLength)
+	for i := int32(0); i < count; i++ {
+		var length, size int32
+		var attributeType uint32
+		gl.GetActiveAttrib(s.ProgramId, uint32(i), maxLength, &length, &size, &attributeType, &nameBuffer[0])
+
+		name := string(nameBuffer[:length])
+		if strings.HasPrefix(name, "gl_") {
+			continue
+		}
+
+		attribute, ok := format.Attribute(name)
+		if !ok {
+			return fmt.Errorf("shader input %q is missing in the vertex format", name)
+		}
+
+		if location := s.GetAttributeLocation(name); location != int32(attribute.Location) {
+			return fmt.Errorf("shader input %q is at location %d, the vertex format uses %d", name, location, attribute.Location)
+		}
+	}
+
+	return nil
+}
+
 func (s *ShaderData) SetTransformationMat(transformation mgl32.Mat4) {
 	transLocation := s.GetUniformLocation("uTrans")
 	gl.UniformMatrix4fv(transLocation, 1, false, &transformation[0])
@@ -63,6 +116,16 @@ func (s *ShaderData) SetViewMat(view mgl32.Mat4) {
 	gl.UniformMatrix4fv(viewLocation, 1, false, &view[0])
 }
 
+// SetUVRect sets the (u, v, width, height) region the texture coordinates are mapped to.
+func (s *ShaderData) SetUVRect(uvRect [4]float32) {
+	gl.Uniform4fv(s.uvRectLocation, 1, &uvRect[0])
+}
+
+// SetSampler points the sampler uniform, like a sampler2DArray or a samplerCube, to the texture unit.
+func (s *ShaderData) SetSampler(name string, unit int32) {
+	gl.Uniform1i(s.GetUniformLocation(name), unit)
+}
+
 func (s *ShaderData) SetColor(color core.Color) {
 	transLocation := s.GetUniformLocation("uColor")
 	gl.Uniform4fv(transLocation, 1, &color[0])
@@ -73,12 +136,84 @@ func GetEmptyShader(uri string) core.Resource {
 		Type:   RT_SHADER,
 		Uri:    uri,
 		Empty:  true,
-		Data:   ShaderData{},
+		Data:   ShaderData{uvRectLocation: -1},
 		Unload: nil,
 	}
 }
 
-type ShaderLoader struct{}
+// GetShader returns the shader data of a loaded shader resource.
+// If the resource is missing or has a different type, the shader placeholder is returned along with the error.
+func GetShader(rm *core.ResourceManager, uri string) (ShaderData, error) {
+	rsc, err := getTypedResource(rm, RT_SHADER, uri)
+
+	data, ok := rsc.Data.(ShaderData)
+	if err == nil && !ok {
+		err = fmt.Errorf("resource %q does not hold shader data", uri)
+	}
+
+	return data, err
+}
+
+type ShaderHandle struct {
+	core.Handle
+}
+
+// LoadShader returns a handle to the shader, loading it first if it's not registered yet.
+func LoadShader(rm *core.ResourceManager, uri string, source core.LoaderParam) (ShaderHandle, error) {
+	handle, err := rm.LoadResource(RT_SHADER, uri, source)
+	return ShaderHandle{handle}, err
+}
+
+func GetShaderHandle(rm *core.ResourceManager, uri string) (ShaderHandle, error) {
+	handle, err := getTypedHandle(rm, RT_SHADER, uri)
+	return ShaderHandle{handle}, err
+}
+
+// ResolveShader returns the shader data referenced by the handle.
+// For stale handles the shader placeholder is returned along with the error.
+func ResolveShader(rm *core.ResourceManager, handle ShaderHandle) (ShaderData, error) {
+	rscData, err := resolveTypedResource(rm, RT_SHADER, handle.Handle)
+
+	data, ok := rscData.(ShaderData)
+	if err == nil && !ok {
+		err = errors.New("resource does not hold shader data")
+	}
+
+	return data, err
+}
+
+// CreateErrorShader creates a shader drawing a magenta checkerboard, used in place of shaders that failed to load.
+// It is read straight from the embeded resources, so it can't be broken by mounted overrides.
+func CreateErrorShader() (core.Resource, error) {
+	fsSrc, err := embededResources.ReadFile("embeded/error.fs")
+	if err != nil {
+		return GetEmptyShader("placeholder_shader"), err
+	}
+
+	vsSrc, err := embededResources.ReadFile("embeded/error.vs")
+	if err != nil {
+		return GetEmptyShader("placeholder_shader"), err
+	}
+
+	shaderData, err := loadShadersFromString(string(fsSrc), string(vsSrc))
+	if err != nil {
+		return GetEmptyShader("placeholder_shader"), err
+	}
+
+	return core.Resource{
+		Type:  RT_SHADER,
+		Uri:   "placeholder_shader",
+		Empty: false,
+		Data:  shaderData,
+		Unload: func() {
+			gl.DeleteProgram(shaderData.ProgramId)
+		},
+	}, nil
+}
+
+type ShaderLoader struct {
+	vfs *core.VFS
+}
 
 func (l ShaderLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
 	if resourceType != RT_SHADER {
@@ -90,6 +225,8 @@ func (l ShaderLoader) CanLoad(resourceType core.ResourceType, uri string, param
 		return true
 	case ShaderFileSource:
 		return true
+	case ShaderCombinedFileSource:
+		return true
 	case EmbededShaderSource:
 		return true
 	default:
@@ -105,9 +242,11 @@ func (l ShaderLoader) Load(uri string, param core.LoaderParam) (core.Resource, e
 	case ShaderStringSource:
 		shaderData, loadError = loadShadersFromString(source.FragmentShader, source.VertexShader)
 	case ShaderFileSource:
-		shaderData, loadError = loadShadersFromFiles(source.FragmentShaderPath, source.VertexShaderPath)
+		shaderData, loadError = loadShadersFromFiles(l.vfs, source.FragmentShaderPath, source.VertexShaderPath)
+	case ShaderCombinedFileSource:
+		shaderData, loadError = loadShadersFromCombinedFile(l.vfs, source.ShaderPath)
 	case EmbededShaderSource:
-		shaderData, loadError = loadShadersFromEmbededResources(source.ShaderName)
+		shaderData, loadError = loadShadersFromEmbededResources(l.vfs, source.ShaderName)
 	default:
 		return GetEmptyShader(uri), errors.New("unsuported shader source")
 	}
@@ -127,17 +266,19 @@ func (l ShaderLoader) Load(uri string, param core.LoaderParam) (core.Resource, e
 	}, nil
 }
 
-func NewShaderLoader() ShaderLoader {
-	return ShaderLoader{}
+func NewShaderLoader(vfs *core.VFS) ShaderLoader {
+	return ShaderLoader{
+		vfs: vfs,
+	}
 }
 
-func loadShadersFromFiles(fsPath string, vsPath string) (ShaderData, error) {
-	fs_text, err := ioutil.ReadFile(fsPath)
+func loadShadersFromFiles(vfs *core.VFS, fsPath string, vsPath string) (ShaderData, error) {
+	fs_text, err := vfs.ReadFile(fsPath)
 	if err != nil {
 		return ShaderData{}, err
 	}
 
-	vs_text, err := ioutil.ReadFile(vsPath)
+	vs_text, err := vfs.ReadFile(vsPath)
 
 	if err != nil {
 		return ShaderData{}, err
@@ -146,13 +287,53 @@ func loadShadersFromFiles(fsPath string, vsPath string) (ShaderData, error) {
 	return loadShadersFromString(string(fs_text), string(vs_text))
 }
 
-func loadShadersFromEmbededResources(name string) (ShaderData, error) {
-	fsSrc := readEmbededFileAsString(name + ".fs")
-	vsSrc := readEmbededFileAsString(name + ".vs")
+func loadShadersFromCombinedFile(vfs *core.VFS, path string) (ShaderData, error) {
+	text, err := vfs.ReadFile(path)
+	if err != nil {
+		return ShaderData{}, err
+	}
+
+	vsSrc, fsSrc, err := splitShaderStages(string(text))
+	if err != nil {
+		return ShaderData{}, fmt.Errorf("%s: %w", path, err)
+	}
 
 	return loadShadersFromString(fsSrc, vsSrc)
 }
 
+func splitShaderStages(src string) (vs string, fs string, err error) {
+	stages := make(map[string]*strings.Builder)
+	var current *strings.Builder
+
+	for _, line := range strings.SplitAfter(src, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && (fields[0] == "#type" || fields[0] == "#shader") {
+			stage := strings.ToLower(fields[1])
+			if stage != "vertex" && stage != "fragment" {
+				return "", "", fmt.Errorf("unsuported shader stage: %q", fields[1])
+			}
+
+			current = &strings.Builder{}
+			stages[stage] = current
+			continue
+		}
+
+		if current != nil {
+			current.WriteString(line)
+		}
+	}
+
+	if stages["vertex"] == nil || stages["fragment"] == nil {
+		return "", "", errors.New("missing vertex or fragment stage marker")
+	}
+
+	return stages["vertex"].String(), stages["fragment"].String(), nil
+}
+
+func loadShadersFromEmbededResources(vfs *core.VFS, name string) (ShaderData, error) {
+	return loadShadersFromFiles(vfs, "embeded/"+name+".fs", "embeded/"+name+".vs")
+}
+
 func loadShadersFromString(fs string, vs string) (ShaderData, error) {
 	fragment_shader := gl.CreateShader(gl.FRAGMENT_SHADER)
 	vertex_shader := gl.CreateShader(gl.VERTEX_SHADER)
@@ -188,6 +369,12 @@ func loadShadersFromString(fs string, vs string) (ShaderData, error) {
 	shader_program := gl.CreateProgram()
 	gl.AttachShader(shader_program, vertex_shader)
 	gl.AttachShader(shader_program, fragment_shader)
+
+	// layout qualifiers in the shader take precedence over these bindings
+	for _, attribute := range standardAttributes {
+		gl.BindAttribLocation(shader_program, attribute.Location, gl.Str(attribute.Name+"\x00"))
+	}
+
 	gl.LinkProgram(shader_program)
 
 	gl.GetProgramiv(shader_program, gl.LINK_STATUS, &status)
@@ -197,8 +384,26 @@ func loadShadersFromString(fs string, vs string) (ShaderData, error) {
 		return ShaderData{}, errors.New("failed to link shader program")
 	}
 
-	return ShaderData{
-		ProgramId:        shader_program,
+	return newShaderData(shader_program), nil
+}
+
+// newShaderData looks up the uniforms set on every bind and maps the texture coordinates
+// to the whole texture, so shaders used outside of the renderer sample the full texture.
+func newShaderData(program uint32) ShaderData {
+	shaderData := ShaderData{
+		ProgramId:        program,
 		uniformLocations: make(map[string]int32),
-	}, nil
+		uvRectLocation:   gl.GetUniformLocation(program, gl.Str("uUVRect\x00")),
+		textured:         gl.GetUniformLocation(program, gl.Str("textSampler\x00")) >= 0,
+	}
+
+	if shaderData.uvRectLocation >= 0 {
+		var current int32
+		gl.GetIntegerv(gl.CURRENT_PROGRAM, &current)
+		gl.UseProgram(program)
+		gl.Uniform4f(shaderData.uvRectLocation, 0, 0, 1, 1)
+		gl.UseProgram(uint32(current))
+	}
+
+	return shaderData
 }
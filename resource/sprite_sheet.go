@@ -0,0 +1,606 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ddomurad/goCraft/core"
+)
+
+const (
+	RT_SPRITE_SHEET core.ResourceType = "sprite_sheet"
+)
+
+// DefaultFrameDuration is used for frames without a duration, like the ones exported by TexturePacker.
+const DefaultFrameDuration = 100 * time.Millisecond
+
+type AnimationDirection uint8
+
+const (
+	AD_FORWARD AnimationDirection = iota
+	AD_REVERSE
+	AD_PINGPONG
+	AD_PINGPONG_REVERSE
+)
+
+// SpriteSheetSource loads a TexturePacker (JSON hash or array) or Aseprite JSON sheet.
+// The sheet image is loaded as a texture dependency, its uri is the image path relative to the sheet file.
+// Animated GIF files are loaded as a sheet too, with a single clip named after the file.
+type SpriteSheetSource struct {
+	FilePath           string
+	NearestFiltering   bool
+	Sampling           TextureSampling
+	PremultipliedAlpha bool
+	SRGB               bool
+}
+
+type SpriteFrame struct {
+	Name    string
+	Texture TextureData
+	Region  image.Rectangle
+	// SourceOffset and SourceSize place trimmed frames in the original sprite
+	SourceOffset image.Point
+	SourceSize   image.Point
+	Duration     time.Duration
+}
+
+// AnimationClip is a named range of frames, from Aseprite tags or from numbered TexturePacker frames.
+type AnimationClip struct {
+	Name      string
+	From      int
+	To        int
+	Direction AnimationDirection
+	// Repeat is the number of loops, 0 loops forever
+	Repeat int
+}
+
+type SliceKey struct {
+	Frame  int
+	Bounds image.Rectangle
+	// Center is the 9-slice center, empty if not set
+	Center   image.Rectangle
+	Pivot    image.Point
+	HasPivot bool
+}
+
+type SpriteSlice struct {
+	Name string
+	Keys []SliceKey
+}
+
+type SpriteSheetData struct {
+	Texture    string
+	Frames     []SpriteFrame
+	FrameIndex map[string]int
+	Clips      map[string]AnimationClip
+	Slices     map[string]SpriteSlice
+}
+
+func GetSpriteSheet(rm *core.ResourceManager, uri string) (SpriteSheetData, error) {
+	rsc, err := getTypedResource(rm, RT_SPRITE_SHEET, uri)
+
+	data, ok := rsc.Data.(SpriteSheetData)
+	if err == nil && !ok {
+		err = fmt.Errorf("resource %q does not hold sprite sheet data", uri)
+	}
+
+	return data, err
+}
+
+func (s SpriteSheetData) GetFrame(name string) (SpriteFrame, bool) {
+	index, ok := s.FrameIndex[name]
+	if !ok {
+		return SpriteFrame{}, false
+	}
+
+	return s.Frames[index], true
+}
+
+// Sequence returns the frame indices of a single loop, in play order.
+func (c AnimationClip) Sequence() []int {
+	forward := make([]int, 0, c.To-c.From+1)
+	for i := c.From; i <= c.To; i++ {
+		forward = append(forward, i)
+	}
+
+	backward := make([]int, len(forward))
+	for i, frame := range forward {
+		backward[len(forward)-1-i] = frame
+	}
+
+	switch c.Direction {
+	case AD_REVERSE:
+		return backward
+	case AD_PINGPONG:
+		if len(forward) > 2 {
+			return append(forward, backward[1:len(backward)-1]...)
+		}
+		return forward
+	case AD_PINGPONG_REVERSE:
+		if len(backward) > 2 {
+			return append(backward, forward[1:len(forward)-1]...)
+		}
+		return backward
+	default:
+		return forward
+	}
+}
+
+// ClipFrameAt returns the frame of the clip shown after the elapsed time.
+// Clips with a limited repeat count stop at their last frame.
+func (s SpriteSheetData) ClipFrameAt(clipName string, elapsed time.Duration) (SpriteFrame, error) {
+	clip, ok := s.Clips[clipName]
+	if !ok {
+		return SpriteFrame{}, fmt.Errorf("animation clip not found: %q", clipName)
+	}
+
+	sequence := clip.Sequence()
+	if len(sequence) == 0 {
+		return SpriteFrame{}, fmt.Errorf("animation clip has no frames: %q", clipName)
+	}
+
+	var loop time.Duration
+	for _, frame := range sequence {
+		loop += s.frameDuration(frame)
+	}
+
+	if clip.Repeat > 0 && elapsed >= loop*time.Duration(clip.Repeat) {
+		return s.Frames[sequence[len(sequence)-1]], nil
+	}
+
+	elapsed %= loop
+	for _, frame := range sequence {
+		elapsed -= s.frameDuration(frame)
+		if elapsed < 0 {
+			return s.Frames[frame], nil
+		}
+	}
+
+	return s.Frames[sequence[len(sequence)-1]], nil
+}
+
+func (s SpriteSheetData) frameDuration(frame int) time.Duration {
+	if s.Frames[frame].Duration <= 0 {
+		return DefaultFrameDuration
+	}
+
+	return s.Frames[frame].Duration
+}
+
+type SpriteSheetLoader struct {
+	rm *core.ResourceManager
+}
+
+func (l SpriteSheetLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
+	if resourceType != RT_SPRITE_SHEET {
+		return false
+	}
+
+	_, ok := param.(SpriteSheetSource)
+	return ok
+}
+
+func (l SpriteSheetLoader) Dependencies(uri string, param core.LoaderParam) []core.Dependency {
+	source := param.(SpriteSheetSource)
+
+	texturePath := source.FilePath
+	if !isGifSheet(source.FilePath) {
+		sheet, err := l.readSheet(source.FilePath)
+		if err != nil {
+			// the error is reported by Load
+			return nil
+		}
+		texturePath = sheetImagePath(source.FilePath, sheet.Meta.Image)
+	}
+
+	return []core.Dependency{{
+		Type: RT_TEXTURE,
+		Uri:  texturePath,
+		Param: TextureParams{
+			FilePath:           texturePath,
+			NearestFiltering:   source.NearestFiltering,
+			Sampling:           source.Sampling,
+			AllFrames:          isGifSheet(source.FilePath),
+			PremultipliedAlpha: source.PremultipliedAlpha,
+			SRGB:               source.SRGB,
+		},
+	}}
+}
+
+func (l SpriteSheetLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
+	source := param.(SpriteSheetSource)
+	if isGifSheet(source.FilePath) {
+		return l.loadGifSheet(uri, source)
+	}
+
+	sheet, err := l.readSheet(source.FilePath)
+	if err != nil {
+		return GetEmptySpriteSheet(uri), fmt.Errorf("%s: %w", source.FilePath, err)
+	}
+
+	texturePath := sheetImagePath(source.FilePath, sheet.Meta.Image)
+	texture, err := GetTexture(l.rm, l.rm.ResolveDependency(uri, texturePath))
+	if err != nil {
+		return GetEmptySpriteSheet(uri), err
+	}
+
+	data, err := sheet.toSpriteSheetData(texturePath, texture)
+	if err != nil {
+		return GetEmptySpriteSheet(uri), fmt.Errorf("%s: %w", source.FilePath, err)
+	}
+
+	return core.Resource{
+		Type:  RT_SPRITE_SHEET,
+		Uri:   uri,
+		Empty: false,
+		Data:  data,
+	}, nil
+}
+
+func (l SpriteSheetLoader) loadGifSheet(uri string, source SpriteSheetSource) (core.Resource, error) {
+	texture, err := GetTexture(l.rm, l.rm.ResolveDependency(uri, source.FilePath))
+	if err != nil {
+		return GetEmptySpriteSheet(uri), err
+	}
+
+	animation, err := decodeGifFile(l.rm.VFS(), source.FilePath)
+	if err != nil {
+		return GetEmptySpriteSheet(uri), err
+	}
+
+	name := strings.TrimSuffix(path.Base(source.FilePath), path.Ext(source.FilePath))
+	data := SpriteSheetData{
+		Texture:    source.FilePath,
+		Frames:     make([]SpriteFrame, len(animation.Frames)),
+		FrameIndex: make(map[string]int),
+		Clips:      make(map[string]AnimationClip),
+		Slices:     make(map[string]SpriteSlice),
+	}
+
+	for i := range animation.Frames {
+		region := animation.FrameRect(i)
+		frameTexture, err := CreateSubTexture(texture, region)
+		if err != nil {
+			return GetEmptySpriteSheet(uri), err
+		}
+
+		frameName := strconv.Itoa(i)
+		data.Frames[i] = SpriteFrame{
+			Name:       frameName,
+			Texture:    frameTexture,
+			Region:     region,
+			SourceSize: region.Size(),
+			Duration:   animation.Delays[i],
+		}
+		data.FrameIndex[frameName] = i
+	}
+
+	clip := AnimationClip{
+		Name: name,
+		From: 0,
+		To:   len(data.Frames) - 1,
+	}
+	switch {
+	case animation.LoopCount < 0:
+		clip.Repeat = 1
+	case animation.LoopCount > 0:
+		clip.Repeat = animation.LoopCount + 1
+	}
+	data.Clips[name] = clip
+
+	return core.Resource{
+		Type:  RT_SPRITE_SHEET,
+		Uri:   uri,
+		Empty: false,
+		Data:  data,
+	}, nil
+}
+
+func NewSpriteSheetLoader(rm *core.ResourceManager) SpriteSheetLoader {
+	return SpriteSheetLoader{
+		rm: rm,
+	}
+}
+
+func GetEmptySpriteSheet(uri string) core.Resource {
+	return core.Resource{
+		Type:  RT_SPRITE_SHEET,
+		Uri:   uri,
+		Empty: true,
+		Data:  SpriteSheetData{},
+	}
+}
+
+// JSON layout shared by TexturePacker and Aseprite exports
+
+type sheetRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type sheetSize struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type sheetPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type sheetFrame struct {
+	Filename         string    `json:"filename"`
+	Frame            sheetRect `json:"frame"`
+	Rotated          bool      `json:"rotated"`
+	Trimmed          bool      `json:"trimmed"`
+	SpriteSourceSize sheetRect `json:"spriteSourceSize"`
+	SourceSize       sheetSize `json:"sourceSize"`
+	Duration         int       `json:"duration"`
+}
+
+type sheetFrameTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+	Repeat    string `json:"repeat"`
+}
+
+type sheetSliceKey struct {
+	Frame  int         `json:"frame"`
+	Bounds sheetRect   `json:"bounds"`
+	Center *sheetRect  `json:"center"`
+	Pivot  *sheetPoint `json:"pivot"`
+}
+
+type sheetSlice struct {
+	Name string          `json:"name"`
+	Keys []sheetSliceKey `json:"keys"`
+}
+
+type sheetMeta struct {
+	Image     string          `json:"image"`
+	FrameTags []sheetFrameTag `json:"frameTags"`
+	Slices    []sheetSlice    `json:"slices"`
+}
+
+type sheetFile struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   sheetMeta       `json:"meta"`
+
+	frames []sheetFrame
+}
+
+var numberedFrameName = regexp.MustCompile(`^(.*?)[_\-. ]?(\d+)(\.[A-Za-z0-9]+)?$`)
+
+func (l SpriteSheetLoader) readSheet(filePath string) (*sheetFile, error) {
+	data, err := l.rm.VFS().ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &sheetFile{}
+	if err = json.Unmarshal(data, sheet); err != nil {
+		return nil, err
+	}
+
+	if sheet.Meta.Image == "" {
+		return nil, errors.New("sprite sheet has no image")
+	}
+
+	sheet.frames, err = decodeSheetFrames(sheet.Frames)
+	return sheet, err
+}
+
+// decodeSheetFrames decodes the JSON array or hash frames, keeping the hash order
+// since Aseprite tags refer to frames by their position.
+func decodeSheetFrames(raw json.RawMessage) ([]sheetFrame, error) {
+	raw = bytes.TrimSpace(raw)
+	frames := make([]sheetFrame, 0)
+
+	if len(raw) > 0 && raw[0] == '[' {
+		err := json.Unmarshal(raw, &frames)
+		return frames, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, errors.New("sprite sheet frames must be an array or an object")
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var frame sheetFrame
+		if err = decoder.Decode(&frame); err != nil {
+			return nil, err
+		}
+
+		frame.Filename = token.(string)
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+func (s *sheetFile) toSpriteSheetData(texturePath string, texture TextureData) (SpriteSheetData, error) {
+	data := SpriteSheetData{
+		Texture:    texturePath,
+		Frames:     make([]SpriteFrame, len(s.frames)),
+		FrameIndex: make(map[string]int),
+		Clips:      make(map[string]AnimationClip),
+		Slices:     make(map[string]SpriteSlice),
+	}
+
+	for i, frame := range s.frames {
+		if frame.Rotated {
+			return SpriteSheetData{}, fmt.Errorf("rotated frames are not supported: %q", frame.Filename)
+		}
+
+		region := frame.Frame.rectangle()
+		frameTexture, err := CreateSubTexture(texture, region)
+		if err != nil {
+			return SpriteSheetData{}, fmt.Errorf("frame %q: %w", frame.Filename, err)
+		}
+
+		sourceSize := image.Pt(frame.SourceSize.W, frame.SourceSize.H)
+		if sourceSize == (image.Point{}) {
+			sourceSize = region.Size()
+		}
+
+		data.Frames[i] = SpriteFrame{
+			Name:         frame.Filename,
+			Texture:      frameTexture,
+			Region:       region,
+			SourceOffset: image.Pt(frame.SpriteSourceSize.X, frame.SpriteSourceSize.Y),
+			SourceSize:   sourceSize,
+			Duration:     time.Duration(frame.Duration) * time.Millisecond,
+		}
+		data.FrameIndex[frame.Filename] = i
+	}
+
+	if len(s.Meta.FrameTags) > 0 {
+		for _, tag := range s.Meta.FrameTags {
+			clip, err := tag.toAnimationClip(len(data.Frames))
+			if err != nil {
+				return SpriteSheetData{}, err
+			}
+			data.Clips[clip.Name] = clip
+		}
+	} else {
+		data.Clips = numberedFrameClips(data.Frames)
+	}
+
+	for _, slice := range s.Meta.Slices {
+		spriteSlice := SpriteSlice{
+			Name: slice.Name,
+			Keys: make([]SliceKey, len(slice.Keys)),
+		}
+
+		for i, key := range slice.Keys {
+			spriteSlice.Keys[i] = SliceKey{
+				Frame:  key.Frame,
+				Bounds: key.Bounds.rectangle(),
+			}
+
+			if key.Center != nil {
+				spriteSlice.Keys[i].Center = key.Center.rectangle()
+			}
+
+			if key.Pivot != nil {
+				spriteSlice.Keys[i].Pivot = image.Pt(key.Pivot.X, key.Pivot.Y)
+				spriteSlice.Keys[i].HasPivot = true
+			}
+		}
+
+		data.Slices[slice.Name] = spriteSlice
+	}
+
+	return data, nil
+}
+
+func (t sheetFrameTag) toAnimationClip(frameCount int) (AnimationClip, error) {
+	if t.From < 0 || t.To >= frameCount || t.From > t.To {
+		return AnimationClip{}, fmt.Errorf("animation tag %q has invalid frame range %d-%d", t.Name, t.From, t.To)
+	}
+
+	clip := AnimationClip{
+		Name: t.Name,
+		From: t.From,
+		To:   t.To,
+	}
+
+	switch t.Direction {
+	case "", "forward":
+		clip.Direction = AD_FORWARD
+	case "reverse":
+		clip.Direction = AD_REVERSE
+	case "pingpong":
+		clip.Direction = AD_PINGPONG
+	case "pingpong_reverse":
+		clip.Direction = AD_PINGPONG_REVERSE
+	default:
+		return AnimationClip{}, fmt.Errorf("animation tag %q has unsuported direction: %q", t.Name, t.Direction)
+	}
+
+	if t.Repeat != "" {
+		repeat, err := strconv.Atoi(t.Repeat)
+		if err != nil {
+			return AnimationClip{}, fmt.Errorf("animation tag %q has invalid repeat: %q", t.Name, t.Repeat)
+		}
+		clip.Repeat = repeat
+	}
+
+	return clip, nil
+}
+
+// numberedFrameClips groups consecutive frames named like "walk_01.png", "walk_02.png" into clips.
+func numberedFrameClips(frames []SpriteFrame) map[string]AnimationClip {
+	type numberedFrame struct {
+		index  int
+		number int
+	}
+
+	groups := make(map[string][]numberedFrame)
+	for i, frame := range frames {
+		match := numberedFrameName.FindStringSubmatch(frame.Name)
+		if match == nil || match[1] == "" {
+			continue
+		}
+
+		number, _ := strconv.Atoi(match[2])
+		groups[match[1]] = append(groups[match[1]], numberedFrame{i, number})
+	}
+
+	clips := make(map[string]AnimationClip)
+	for name, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].number < group[j].number
+		})
+
+		// clips are frame ranges, so the frames must be stored in order
+		contiguous := true
+		for i := 1; i < len(group); i++ {
+			if group[i].index != group[i-1].index+1 {
+				contiguous = false
+				break
+			}
+		}
+
+		if contiguous {
+			clips[name] = AnimationClip{
+				Name: name,
+				From: group[0].index,
+				To:   group[len(group)-1].index,
+			}
+		}
+	}
+
+	return clips
+}
+
+func (r sheetRect) rectangle() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H)
+}
+
+func isGifSheet(filePath string) bool {
+	return strings.EqualFold(path.Ext(filePath), ".gif")
+}
+
+func sheetImagePath(sheetPath, imageName string) string {
+	return path.Join(path.Dir(sheetPath), imageName)
+}
@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/ddomurad/goCraft/core"
+)
+
+// SubTextureParams describes a region, in pixels, of an already loaded texture.
+// The texture may be a sub texture itself.
+type SubTextureParams struct {
+	Texture string
+	Region  image.Rectangle
+}
+
+// SubTextureLoader creates textures referencing a region of another texture, like atlas or sprite sheet frames.
+// They share the parent texture object and are reloaded whenever the parent is.
+type SubTextureLoader struct {
+	rm *core.ResourceManager
+}
+
+func (l SubTextureLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
+	if resourceType != RT_TEXTURE {
+		return false
+	}
+
+	_, ok := param.(SubTextureParams)
+	return ok
+}
+
+func (l SubTextureLoader) Dependencies(uri string, param core.LoaderParam) []core.Dependency {
+	return []core.Dependency{{
+		Type: RT_TEXTURE,
+		Uri:  param.(SubTextureParams).Texture,
+	}}
+}
+
+func (l SubTextureLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
+	params := param.(SubTextureParams)
+
+	parent, err := GetTexture(l.rm, l.rm.ResolveDependency(uri, params.Texture))
+	if err != nil {
+		return GetEmptyTexture(uri), err
+	}
+
+	textureData, err := CreateSubTexture(parent, params.Region)
+	if err != nil {
+		return GetEmptyTexture(uri), err
+	}
+
+	// the texture object is owned by the parent
+	return core.Resource{
+		Type:  RT_TEXTURE,
+		Uri:   uri,
+		Empty: false,
+		Data:  textureData,
+	}, nil
+}
+
+func NewSubTextureLoader(rm *core.ResourceManager) SubTextureLoader {
+	return SubTextureLoader{
+		rm: rm,
+	}
+}
+
+// CreateSubTexture returns texture data referencing a region of the parent texture, in pixels.
+func CreateSubTexture(parent TextureData, region image.Rectangle) (TextureData, error) {
+	bounds := image.Rect(0, 0, int(parent.Width), int(parent.Height))
+	if region.Empty() || !region.In(bounds) {
+		return TextureData{}, fmt.Errorf("sub texture region %v outside of the %dx%d texture", region, parent.Width, parent.Height)
+	}
+
+	parentUV := parent.GetUVRect()
+	scaleU := parentUV[2] / float32(parent.Width)
+	scaleV := parentUV[3] / float32(parent.Height)
+
+	return TextureData{
+		Id:            parent.Id,
+		Width:         int32(region.Dx()),
+		Height:        int32(region.Dy()),
+		SubTexture:    true,
+		Premultiplied: parent.Premultiplied,
+		SRGB:          parent.SRGB,
+		UVRect: [4]float32{
+			parentUV[0] + float32(region.Min.X)*scaleU,
+			parentUV[1] + float32(region.Min.Y)*scaleV,
+			float32(region.Dx()) * scaleU,
+			float32(region.Dy()) * scaleV,
+		},
+	}, nil
+}
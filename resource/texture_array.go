@@ -0,0 +1,274 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+const (
+	RT_TEXTURE_ARRAY core.ResourceType = "texture_array"
+)
+
+// TextureArrayParams builds a texture array from same sized images, one layer per file.
+type TextureArrayParams struct {
+	FilePaths          []string
+	NearestFiltering   bool
+	Sampling           TextureSampling
+	PremultipliedAlpha bool
+	SRGB               bool
+}
+
+// TextureArrayGridParams builds a texture array from a tile sheet,
+// one layer per tile, left to right and top to bottom.
+type TextureArrayGridParams struct {
+	FilePath           string
+	TileWidth          int
+	TileHeight         int
+	NearestFiltering   bool
+	Sampling           TextureSampling
+	PremultipliedAlpha bool
+	SRGB               bool
+}
+
+// GifTextureArrayParams builds a texture array from the frames of an animated GIF, one layer per frame.
+type GifTextureArrayParams struct {
+	FilePath           string
+	NearestFiltering   bool
+	Sampling           TextureSampling
+	PremultipliedAlpha bool
+	SRGB               bool
+}
+
+type TextureArrayData struct {
+	Id            uint32
+	Width         int32
+	Height        int32
+	Layers        int32
+	Premultiplied bool
+	SRGB          bool
+}
+
+// Bind binds the texture array to the texture unit, to be used with a sampler2DArray uniform.
+func (t TextureArrayData) Bind(unit uint32) {
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, t.Id)
+	gl.ActiveTexture(gl.TEXTURE0)
+}
+
+func GetEmptyTextureArray(uri string) core.Resource {
+	return core.Resource{
+		Type:  RT_TEXTURE_ARRAY,
+		Uri:   uri,
+		Empty: true,
+		Data:  TextureArrayData{},
+	}
+}
+
+func GetTextureArray(rm *core.ResourceManager, uri string) (TextureArrayData, error) {
+	rsc, err := getTypedResource(rm, RT_TEXTURE_ARRAY, uri)
+
+	data, ok := rsc.Data.(TextureArrayData)
+	if err == nil && !ok {
+		err = fmt.Errorf("resource %q does not hold texture array data", uri)
+	}
+
+	return data, err
+}
+
+type TextureArrayLoader struct {
+	vfs *core.VFS
+}
+
+func (l TextureArrayLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
+	if resourceType != RT_TEXTURE_ARRAY {
+		return false
+	}
+
+	switch param.(type) {
+	case TextureArrayParams, TextureArrayGridParams, GifTextureArrayParams:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l TextureArrayLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
+	var layers []image.Image
+	var textureParams TextureParams
+
+	switch params := param.(type) {
+	case TextureArrayParams:
+		if len(params.FilePaths) == 0 {
+			return GetEmptyTextureArray(uri), errors.New("texture array has no images")
+		}
+
+		for _, filePath := range params.FilePaths {
+			img, err := decodeImageFile(l.vfs, filePath)
+			if err != nil {
+				return GetEmptyTextureArray(uri), err
+			}
+			layers = append(layers, img)
+		}
+		textureParams = TextureParams{
+			NearestFiltering:   params.NearestFiltering,
+			Sampling:           params.Sampling,
+			PremultipliedAlpha: params.PremultipliedAlpha,
+			SRGB:               params.SRGB,
+		}
+	case TextureArrayGridParams:
+		img, err := decodeImageFile(l.vfs, params.FilePath)
+		if err != nil {
+			return GetEmptyTextureArray(uri), err
+		}
+
+		if layers, err = sliceGrid(img, params.TileWidth, params.TileHeight); err != nil {
+			return GetEmptyTextureArray(uri), fmt.Errorf("%s: %w", params.FilePath, err)
+		}
+		textureParams = TextureParams{
+			NearestFiltering:   params.NearestFiltering,
+			Sampling:           params.Sampling,
+			PremultipliedAlpha: params.PremultipliedAlpha,
+			SRGB:               params.SRGB,
+		}
+	case GifTextureArrayParams:
+		animation, err := decodeGifFile(l.vfs, params.FilePath)
+		if err != nil {
+			return GetEmptyTextureArray(uri), err
+		}
+
+		if len(animation.Frames) == 0 {
+			return GetEmptyTextureArray(uri), fmt.Errorf("%s: gif has no frames", params.FilePath)
+		}
+
+		for _, frame := range animation.Frames {
+			layers = append(layers, frame)
+		}
+		textureParams = TextureParams{
+			NearestFiltering:   params.NearestFiltering,
+			Sampling:           params.Sampling,
+			PremultipliedAlpha: params.PremultipliedAlpha,
+			SRGB:               params.SRGB,
+		}
+	}
+
+	size := layers[0].Bounds().Size()
+	for i, layer := range layers {
+		if layer.Bounds().Size() != size {
+			return GetEmptyTextureArray(uri), fmt.Errorf("texture array layer %d is %v, expected %v", i, layer.Bounds().Size(), size)
+		}
+	}
+
+	sampling := textureParams.Sampling.withDefaults(textureParams.NearestFiltering)
+	internalFormat := int32(gl.RGBA8)
+	if textureParams.SRGB {
+		internalFormat = gl.SRGB8_ALPHA8
+	}
+
+	width, height, layerCount := int32(size.X), int32(size.Y), int32(len(layers))
+
+	var maxLayers int32
+	gl.GetIntegerv(gl.MAX_ARRAY_TEXTURE_LAYERS, &maxLayers)
+	if layerCount > maxLayers {
+		return GetEmptyTextureArray(uri), fmt.Errorf("texture array has %d layers, the limit is %d", layerCount, maxLayers)
+	}
+
+	var textureId uint32
+	gl.GenTextures(1, &textureId)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, textureId)
+	defer gl.BindTexture(gl.TEXTURE_2D_ARRAY, 0)
+
+	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, internalFormat, width, height, layerCount, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	for i, layer := range layers {
+		pixels := colorImagePixels(layer, textureParams.PremultipliedAlpha, textureParams.SRGB)
+		gl.PixelStorei(gl.UNPACK_ROW_LENGTH, pixels.rowLength)
+		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(i), width, height, 1,
+			gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels.pix))
+	}
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+
+	mipLevels := 1
+	if sampling.GenerateMipmaps {
+		mipLevels = MipLevelCount(width, height)
+		gl.GenerateMipmap(gl.TEXTURE_2D_ARRAY)
+	}
+
+	applyTextureSampling(gl.TEXTURE_2D_ARRAY, sampling, mipLevels)
+
+	return core.Resource{
+		Type:  RT_TEXTURE_ARRAY,
+		Uri:   uri,
+		Empty: false,
+		Size:  EstimateTextureSize(width, height, 4, mipLevels) * int64(layerCount),
+		Data: TextureArrayData{
+			Id:            textureId,
+			Width:         width,
+			Height:        height,
+			Layers:        layerCount,
+			Premultiplied: textureParams.PremultipliedAlpha,
+			SRGB:          textureParams.SRGB,
+		},
+		Unload: func() {
+			gl.DeleteTextures(1, &textureId)
+		},
+	}, nil
+}
+
+func NewTextureArrayLoader(vfs *core.VFS) TextureArrayLoader {
+	return TextureArrayLoader{
+		vfs: vfs,
+	}
+}
+
+func decodeImageFile(vfs *core.VFS, filePath string) (image.Image, error) {
+	file, err := vfs.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	return img, nil
+}
+
+// subImager is implemented by all the image types of the standard library
+type subImager interface {
+	SubImage(r image.Rectangle) image.Image
+}
+
+func sliceGrid(img image.Image, tileWidth, tileHeight int) ([]image.Image, error) {
+	if tileWidth <= 0 || tileHeight <= 0 {
+		return nil, fmt.Errorf("invalid tile size: %dx%d", tileWidth, tileHeight)
+	}
+
+	source, ok := img.(subImager)
+	if !ok {
+		return nil, fmt.Errorf("unsuported image type: %T", img)
+	}
+
+	bounds := img.Bounds()
+	columns, rows := bounds.Dx()/tileWidth, bounds.Dy()/tileHeight
+	if columns == 0 || rows == 0 {
+		return nil, fmt.Errorf("image is smaller than a %dx%d tile", tileWidth, tileHeight)
+	}
+
+	tiles := make([]image.Image, 0, columns*rows)
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			rect := image.Rect(column*tileWidth, row*tileHeight, (column+1)*tileWidth, (row+1)*tileHeight)
+			tiles = append(tiles, source.SubImage(rect.Add(bounds.Min)))
+		}
+	}
+
+	return tiles, nil
+}
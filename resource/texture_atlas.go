@@ -0,0 +1,139 @@
+package resource
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/ddomurad/goCraft/atlas"
+	"github.com/ddomurad/goCraft/core"
+)
+
+type AtlasOptions struct {
+	// PageWidth and PageHeight default to 1024
+	PageWidth  int
+	PageHeight int
+	Padding    int
+	Extrude    int
+
+	NearestFiltering   bool
+	Sampling           TextureSampling
+	PremultipliedAlpha bool
+	SRGB               bool
+}
+
+// AtlasBuilder packs many images into one or more atlas pages at runtime.
+// Every image becomes a sub texture named "<atlas uri>/<image name>",
+// usable anywhere a texture uri is accepted. Pages are named "<atlas uri>/page-<n>".
+type AtlasBuilder struct {
+	vfs     *core.VFS
+	options AtlasOptions
+	sprites []atlas.Sprite
+	names   map[string]bool
+}
+
+func NewAtlasBuilder(vfs *core.VFS, options AtlasOptions) *AtlasBuilder {
+	if options.PageWidth <= 0 {
+		options.PageWidth = 1024
+	}
+
+	if options.PageHeight <= 0 {
+		options.PageHeight = 1024
+	}
+
+	return &AtlasBuilder{
+		vfs:     vfs,
+		options: options,
+		sprites: make([]atlas.Sprite, 0),
+		names:   make(map[string]bool),
+	}
+}
+
+func (b *AtlasBuilder) AddImage(name string, img image.Image) error {
+	if b.names[name] {
+		return fmt.Errorf("atlas image already added: %q", name)
+	}
+
+	b.names[name] = true
+	b.sprites = append(b.sprites, atlas.Sprite{
+		Name:  name,
+		Image: img,
+	})
+
+	return nil
+}
+
+func (b *AtlasBuilder) AddFile(name string, filePath string) error {
+	file, err := b.vfs.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	return b.AddImage(name, img)
+}
+
+// Build packs the images and registers the pages and sub textures in the resource manager.
+// Building again with the same uri replaces the pages, the sub textures follow them.
+// Pages left over from a previous build with more pages are unloaded.
+// Requires the SubTextureLoader to be registered.
+func (b *AtlasBuilder) Build(rm *core.ResourceManager, uri string) error {
+	packed, err := atlas.Pack(b.sprites, atlas.Options{
+		PageWidth:  b.options.PageWidth,
+		PageHeight: b.options.PageHeight,
+		Padding:    b.options.Padding,
+		Extrude:    b.options.Extrude,
+	})
+	if err != nil {
+		return err
+	}
+
+	pageParams := TextureParams{
+		NearestFiltering:   b.options.NearestFiltering,
+		Sampling:           b.options.Sampling,
+		PremultipliedAlpha: b.options.PremultipliedAlpha,
+		SRGB:               b.options.SRGB,
+	}
+	pageUris := make([]string, len(packed.Pages))
+
+	for i, page := range packed.Pages {
+		pageUris[i] = fmt.Sprintf("%s/page-%d", uri, i)
+		rm.AddResource(createImageTexture(pageUris[i], page, pageParams))
+	}
+
+	for _, region := range packed.Regions {
+		regionUri := uri + "/" + region.Name
+		_, err := rm.ReplaceResource(RT_TEXTURE, regionUri, SubTextureParams{
+			Texture: pageUris[region.Page],
+			Region:  region.Rect,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := len(packed.Pages); ; i++ {
+		pageUri := fmt.Sprintf("%s/page-%d", uri, i)
+		if _, err := rm.GetHandle(pageUri); err != nil {
+			break
+		}
+
+		// sub textures of images packed into the old page only
+		for _, dependent := range rm.Dependents(pageUri) {
+			if strings.HasPrefix(dependent, uri+"/") {
+				rm.UnloadResource(dependent)
+			}
+		}
+
+		if err := rm.UnloadResource(pageUri); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package resource
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/ddomurad/goCraft/gctex"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// containerDecoders decode the texture containers holding data ready for upload, recognized by their header.
+var containerDecoders = []struct {
+	match  func(header []byte) bool
+	decode func(reader io.Reader) (*gctex.Texture, error)
+}{
+	{gctex.IsContainer, gctex.Decode},
+	{gctex.IsKTX, gctex.DecodeKTX},
+	{gctex.IsDDS, gctex.DecodeDDS},
+}
+
+// loadContainerTexture uploads a gctex, KTX or DDS texture if the header matches one of them.
+func loadContainerTexture(uri string, header []byte, reader io.Reader, textureParams TextureParams) (core.Resource, bool, error) {
+	for _, container := range containerDecoders {
+		if !container.match(header) {
+			continue
+		}
+
+		tex, err := container.decode(reader)
+		if err != nil {
+			return GetEmptyTexture(uri), true, err
+		}
+
+		rsc, err := loadBakedTexture(uri, tex, textureParams)
+		return rsc, true, err
+	}
+
+	return core.Resource{}, false, nil
+}
+
+// sRGB S3TC formats from EXT_texture_sRGB, missing in the gl bindings
+const (
+	compressedSrgbAlphaS3tcDxt1 = 0x8C4D
+	compressedSrgbAlphaS3tcDxt3 = 0x8C4E
+	compressedSrgbAlphaS3tcDxt5 = 0x8C4F
+)
+
+// bakedInternalFormats maps the container formats to the linear and sRGB internal formats.
+var bakedInternalFormats = map[gctex.Format][2]uint32{
+	gctex.FORMAT_RGBA8:      {gl.RGBA8, gl.SRGB8_ALPHA8},
+	gctex.FORMAT_BC1:        {gl.COMPRESSED_RGBA_S3TC_DXT1_EXT, compressedSrgbAlphaS3tcDxt1},
+	gctex.FORMAT_BC2:        {gl.COMPRESSED_RGBA_S3TC_DXT3_EXT, compressedSrgbAlphaS3tcDxt3},
+	gctex.FORMAT_BC3:        {gl.COMPRESSED_RGBA_S3TC_DXT5_EXT, compressedSrgbAlphaS3tcDxt5},
+	gctex.FORMAT_ETC2_RGB8:  {gl.COMPRESSED_RGB8_ETC2, gl.COMPRESSED_SRGB8_ETC2},
+	gctex.FORMAT_ETC2_RGBA8: {gl.COMPRESSED_RGBA8_ETC2_EAC, gl.COMPRESSED_SRGB8_ALPHA8_ETC2_EAC},
+}
+
+// loadBakedTexture uploads a decoded texture container, with all its mip levels.
+func loadBakedTexture(uri string, tex *gctex.Texture, textureParams TextureParams) (core.Resource, error) {
+	internalFormats, ok := bakedInternalFormats[tex.Format]
+	if !ok {
+		return GetEmptyTexture(uri), fmt.Errorf("unsuported baked texture format: %s", tex.Format)
+	}
+
+	internalFormat := internalFormats[0]
+	if textureParams.SRGB {
+		internalFormat = internalFormats[1]
+	}
+
+	if !compressedFormatSupported(tex.Format) {
+		return GetEmptyTexture(uri), fmt.Errorf("%s textures are not supported by the driver", tex.Format)
+	}
+
+	var textureId uint32
+	gl.GenTextures(1, &textureId)
+	gl.BindTexture(gl.TEXTURE_2D, textureId)
+	defer gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	var size int64
+	for level, data := range tex.Levels {
+		w, h := gctex.LevelDimensions(tex.Width, tex.Height, level)
+
+		if tex.Format.Compressed() {
+			gl.CompressedTexImage2D(gl.TEXTURE_2D, int32(level), internalFormat,
+				int32(w), int32(h), 0, int32(len(data)), gl.Ptr(data))
+		} else {
+			gl.TexImage2D(gl.TEXTURE_2D, int32(level), int32(internalFormat),
+				int32(w), int32(h), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(data))
+		}
+
+		size += int64(len(data))
+	}
+
+	sampling := textureParams.Sampling
+	mipLevels := len(tex.Levels)
+
+	// baked mipmaps are used as they are, generating is only possible for raw single level textures
+	if sampling.GenerateMipmaps && mipLevels == 1 && !tex.Format.Compressed() {
+		mipLevels = MipLevelCount(int32(tex.Width), int32(tex.Height))
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+		size = EstimateTextureSize(int32(tex.Width), int32(tex.Height), 4, mipLevels)
+	}
+
+	sampling.GenerateMipmaps = mipLevels > 1
+	applyTextureSampling(gl.TEXTURE_2D, sampling.withDefaults(textureParams.NearestFiltering), mipLevels)
+
+	return core.Resource{
+		Type:  RT_TEXTURE,
+		Uri:   uri,
+		Empty: false,
+		Size:  size,
+		Data: TextureData{
+			Id:            textureId,
+			Width:         int32(tex.Width),
+			Height:        int32(tex.Height),
+			Premultiplied: textureParams.PremultipliedAlpha,
+			SRGB:          textureParams.SRGB,
+		},
+		Unload: func() {
+			gl.DeleteTextures(1, &textureId)
+		},
+	}, nil
+}
+
+// compressedFormatSupported checks the extensions required by the compressed formats.
+// ETC2 is core since OpenGL 4.3 and available earlier through ARB_ES3_compatibility.
+func compressedFormatSupported(format gctex.Format) bool {
+	switch format {
+	case gctex.FORMAT_BC1, gctex.FORMAT_BC2, gctex.FORMAT_BC3:
+		return HasGlExtension("GL_EXT_texture_compression_s3tc")
+	case gctex.FORMAT_ETC2_RGB8, gctex.FORMAT_ETC2_RGBA8:
+		return HasGlExtension("GL_ARB_ES3_compatibility")
+	default:
+		return true
+	}
+}
@@ -0,0 +1,255 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"unsafe"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+type PixelFormat uint8
+
+const (
+	PF_RGBA8 PixelFormat = iota
+	// PF_R8 is a single channel format, sampled as (r, 0, 0, 1)
+	PF_R8
+)
+
+// BytesPerPixel returns the size of a single pixel.
+func (f PixelFormat) BytesPerPixel() int {
+	switch f {
+	case PF_R8:
+		return 1
+	default:
+		return 4
+	}
+}
+
+// glFormats returns the internal and pixel formats, single channel textures are never sRGB.
+func (f PixelFormat) glFormats(srgb bool) (internalFormat int32, format uint32) {
+	switch {
+	case f == PF_R8:
+		return gl.R8, gl.RED
+	case srgb:
+		return gl.SRGB8_ALPHA8, gl.RGBA
+	default:
+		return gl.RGBA8, gl.RGBA
+	}
+}
+
+// DynamicTextureParams creates an empty texture updated at runtime.
+type DynamicTextureParams struct {
+	Width            int32
+	Height           int32
+	Format           PixelFormat
+	NearestFiltering bool
+	Sampling         TextureSampling
+	// DoubleBuffered copies the updates into two alternating, mapped pixel buffer objects and
+	// uploads from them, so the upload runs asynchronously and the next update doesn't wait for it
+	DoubleBuffered bool
+	// PremultipliedAlpha tells the updated pixels have premultiplied alpha
+	PremultipliedAlpha bool
+	SRGB               bool
+}
+
+// DynamicTexture is a texture which content is updated after creation, like video frames or a minimap.
+type DynamicTexture struct {
+	TextureData
+	Format   PixelFormat
+	sampling TextureSampling
+	pbos     []uint32
+	pboIndex int
+}
+
+// Update replaces the pixels in the rectangle. The pixels are tightly packed rows of the texture format.
+func (t *DynamicTexture) Update(rect image.Rectangle, pixels []byte) error {
+	bounds := image.Rect(0, 0, int(t.Width), int(t.Height))
+	if rect.Empty() || !rect.In(bounds) {
+		return fmt.Errorf("update region %v outside of the %dx%d texture", rect, t.Width, t.Height)
+	}
+
+	size := rect.Dx() * rect.Dy() * t.Format.BytesPerPixel()
+	if len(pixels) < size {
+		return fmt.Errorf("update region %v needs %d bytes, got %d", rect, size, len(pixels))
+	}
+
+	_, format := t.Format.glFormats(t.SRGB)
+
+	gl.BindTexture(gl.TEXTURE_2D, t.Id)
+	defer gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	defer gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+
+	if len(t.pbos) == 0 {
+		gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(rect.Min.X), int32(rect.Min.Y), int32(rect.Dx()), int32(rect.Dy()),
+			format, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+	} else {
+		pbo := t.pbos[t.pboIndex]
+		t.pboIndex = (t.pboIndex + 1) % len(t.pbos)
+
+		gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, pbo)
+		// invalidating the buffer lets the driver hand out new storage if the previous upload is still pending
+		writeMapped(gl.PIXEL_UNPACK_BUFFER, 0, pixels[:size], gl.MAP_WRITE_BIT|gl.MAP_INVALIDATE_BUFFER_BIT)
+		gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(rect.Min.X), int32(rect.Min.Y), int32(rect.Dx()), int32(rect.Dy()),
+			format, gl.UNSIGNED_BYTE, gl.PtrOffset(0))
+		gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, 0)
+	}
+
+	if t.sampling.GenerateMipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+
+	return nil
+}
+
+// UpdateImage replaces the pixels covered by the image bounds,
+// converting them to the alpha mode of the texture.
+func (t *DynamicTexture) UpdateImage(img image.Image) error {
+	if t.Format != PF_RGBA8 {
+		return errors.New("images can only update rgba textures")
+	}
+
+	pixels := colorImagePixels(img, t.Premultiplied, false)
+
+	// Update expects tightly packed rows
+	bounds := img.Bounds()
+	pix := pixels.pix
+	if rowSize := bounds.Dx() * 4; int(pixels.rowLength)*4 != rowSize {
+		pix = make([]byte, rowSize*bounds.Dy())
+		for y := 0; y < bounds.Dy(); y++ {
+			copy(pix[y*rowSize:(y+1)*rowSize], pixels.pix[y*int(pixels.rowLength)*4:])
+		}
+	}
+
+	return t.Update(bounds, pix)
+}
+
+// ReadImage reads the texture content back. Single channel textures are expanded to opaque red.
+func (t *DynamicTexture) ReadImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, int(t.Width), int(t.Height)))
+
+	gl.BindTexture(gl.TEXTURE_2D, t.Id)
+	gl.GetTexImage(gl.TEXTURE_2D, 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	return img
+}
+
+// GetDynamicTexture returns a loaded dynamic texture.
+func GetDynamicTexture(rm *core.ResourceManager, uri string) (*DynamicTexture, error) {
+	rsc, err := getTypedResource(rm, RT_TEXTURE, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	texture, ok := rsc.Data.(*DynamicTexture)
+	if !ok {
+		return nil, fmt.Errorf("resource %q is not a dynamic texture", uri)
+	}
+
+	return texture, nil
+}
+
+type DynamicTextureLoader struct {
+}
+
+func (l DynamicTextureLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
+	if resourceType != RT_TEXTURE {
+		return false
+	}
+
+	_, ok := param.(DynamicTextureParams)
+	return ok
+}
+
+func (l DynamicTextureLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
+	params := param.(DynamicTextureParams)
+	if params.Width <= 0 || params.Height <= 0 {
+		return GetEmptyTexture(uri), fmt.Errorf("invalid dynamic texture size: %dx%d", params.Width, params.Height)
+	}
+
+	sampling := params.Sampling.withDefaults(params.NearestFiltering)
+	internalFormat, format := params.Format.glFormats(params.SRGB)
+
+	texture := &DynamicTexture{
+		TextureData: TextureData{
+			Width:         params.Width,
+			Height:        params.Height,
+			Premultiplied: params.PremultipliedAlpha,
+			SRGB:          params.SRGB && params.Format != PF_R8,
+		},
+		Format:   params.Format,
+		sampling: sampling,
+	}
+
+	gl.GenTextures(1, &texture.Id)
+	gl.BindTexture(gl.TEXTURE_2D, texture.Id)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, internalFormat, params.Width, params.Height, 0, format, gl.UNSIGNED_BYTE, nil)
+
+	mipLevels := 1
+	if sampling.GenerateMipmaps {
+		mipLevels = MipLevelCount(params.Width, params.Height)
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+
+	applyTextureSampling(gl.TEXTURE_2D, sampling, mipLevels)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	size := EstimateTextureSize(params.Width, params.Height, params.Format.BytesPerPixel(), mipLevels)
+
+	if params.DoubleBuffered {
+		texture.pbos = make([]uint32, 2)
+		gl.GenBuffers(int32(len(texture.pbos)), &texture.pbos[0])
+
+		// sized for the whole texture, so any update region fits
+		pboSize := int(params.Width) * int(params.Height) * params.Format.BytesPerPixel()
+		for _, pbo := range texture.pbos {
+			gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, pbo)
+			gl.BufferData(gl.PIXEL_UNPACK_BUFFER, pboSize, nil, gl.STREAM_DRAW)
+		}
+		gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, 0)
+		size += 2 * int64(pboSize)
+	}
+
+	return core.Resource{
+		Type:     RT_TEXTURE,
+		Uri:      uri,
+		Empty:    false,
+		Size:     size,
+		Volatile: true,
+		Data:     texture,
+		Unload: func() {
+			gl.DeleteTextures(1, &texture.Id)
+			if len(texture.pbos) > 0 {
+				gl.DeleteBuffers(int32(len(texture.pbos)), &texture.pbos[0])
+			}
+		},
+	}, nil
+}
+
+func NewDynamicTextureLoader() DynamicTextureLoader {
+	return DynamicTextureLoader{}
+}
+
+// writeMapped writes the data to the bound buffer through a mapping. If the buffer can't be mapped,
+// or its content got lost while mapped, the data is written with BufferSubData, which may stall.
+func writeMapped(target uint32, offset int, data []byte, access uint32) {
+	mapped := gl.MapBufferRange(target, offset, len(data), access)
+	if mapped == nil {
+		gl.BufferSubData(target, offset, len(data), gl.Ptr(data))
+		return
+	}
+
+	copy(mappedBytes(mapped, len(data)), data)
+	if !gl.UnmapBuffer(target) {
+		gl.BufferSubData(target, offset, len(data), gl.Ptr(data))
+	}
+}
+
+func mappedBytes(mapped unsafe.Pointer, size int) []byte {
+	return (*[1 << 30]byte)(mapped)[:size:size]
+}
@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+	"time"
+
+	"github.com/ddomurad/goCraft/core"
+)
+
+// GifAnimation holds the fully composed frames of an animated GIF.
+type GifAnimation struct {
+	Frames []*image.RGBA
+	Delays []time.Duration
+	// LoopCount is 0 for endless animations, -1 to play once, n to play n+1 times
+	LoopCount int
+}
+
+func isGif(header []byte) bool {
+	return bytes.HasPrefix(header, []byte("GIF87a")) || bytes.HasPrefix(header, []byte("GIF89a"))
+}
+
+// DecodeGifAnimation decodes all GIF frames, applying the frame disposal methods,
+// so every frame can be shown on its own.
+func DecodeGifAnimation(reader io.Reader) (GifAnimation, error) {
+	decoded, err := gif.DecodeAll(reader)
+	if err != nil {
+		return GifAnimation{}, err
+	}
+
+	bounds := image.Rect(0, 0, decoded.Config.Width, decoded.Config.Height)
+	canvas := image.NewRGBA(bounds)
+
+	animation := GifAnimation{
+		Frames:    make([]*image.RGBA, len(decoded.Image)),
+		Delays:    make([]time.Duration, len(decoded.Image)),
+		LoopCount: decoded.LoopCount,
+	}
+
+	for i, frame := range decoded.Image {
+		var previous *image.RGBA
+		disposal := byte(0)
+		if i < len(decoded.Disposal) {
+			disposal = decoded.Disposal[i]
+		}
+
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		animation.Frames[i] = cloneRGBA(canvas)
+		animation.Delays[i] = time.Duration(decoded.Delay[i]) * 10 * time.Millisecond
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return animation, nil
+}
+
+func decodeGifFile(vfs *core.VFS, filePath string) (GifAnimation, error) {
+	file, err := vfs.Open(filePath)
+	if err != nil {
+		return GifAnimation{}, err
+	}
+	defer file.Close()
+
+	animation, err := DecodeGifAnimation(file)
+	if err != nil {
+		return GifAnimation{}, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	return animation, nil
+}
+
+// Sheet places the frames in a single image, in a grid as close to a square as possible,
+// left to right and top to bottom. A single row would quickly exceed the maximum texture size.
+func (a GifAnimation) Sheet() *image.RGBA {
+	if len(a.Frames) == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+
+	frameSize := a.Frames[0].Rect.Size()
+	columns := a.columns()
+	rows := (len(a.Frames) + columns - 1) / columns
+	sheet := image.NewRGBA(image.Rect(0, 0, frameSize.X*columns, frameSize.Y*rows))
+	for i, frame := range a.Frames {
+		draw.Draw(sheet, a.FrameRect(i), frame, image.Point{}, draw.Src)
+	}
+
+	return sheet
+}
+
+// FrameRect returns the region of the frame in the sheet.
+func (a GifAnimation) FrameRect(frame int) image.Rectangle {
+	frameSize := a.Frames[0].Rect.Size()
+	columns := a.columns()
+	min := image.Pt(frame%columns*frameSize.X, frame/columns*frameSize.Y)
+	return image.Rectangle{Min: min, Max: min.Add(frameSize)}
+}
+
+func (a GifAnimation) columns() int {
+	frameSize := a.Frames[0].Rect.Size()
+	columns := int(math.Ceil(math.Sqrt(float64(len(a.Frames)*frameSize.Y) / float64(frameSize.X))))
+	if columns < 1 {
+		return 1
+	}
+	if columns > len(a.Frames) {
+		return len(a.Frames)
+	}
+	return columns
+}
+
+func cloneRGBA(src *image.RGBA) *image.RGBA {
+	dst := image.NewRGBA(src.Rect)
+	copy(dst.Pix, src.Pix)
+	return dst
+}
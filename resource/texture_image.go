@@ -0,0 +1,251 @@
+package resource
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// ImageTextureSource creates a texture from an image in memory, like a procedurally generated one.
+type ImageTextureSource struct {
+	Image              image.Image
+	NearestFiltering   bool
+	Sampling           TextureSampling
+	PremultipliedAlpha bool
+	SRGB               bool
+}
+
+// BytesTextureSource decodes a texture from an encoded image in memory, in any of the supported formats.
+type BytesTextureSource struct {
+	Data               []byte
+	NearestFiltering   bool
+	Sampling           TextureSampling
+	AllFrames          bool
+	PremultipliedAlpha bool
+	SRGB               bool
+}
+
+// ReaderTextureSource decodes a texture read from the reader, like a downloaded image.
+// The reader is consumed by the first load, so these textures are never evicted.
+type ReaderTextureSource struct {
+	Reader             io.Reader
+	NearestFiltering   bool
+	Sampling           TextureSampling
+	AllFrames          bool
+	PremultipliedAlpha bool
+	SRGB               bool
+}
+
+type MemoryTextureLoader struct {
+}
+
+func (l MemoryTextureLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
+	if resourceType != RT_TEXTURE {
+		return false
+	}
+
+	switch param.(type) {
+	case ImageTextureSource, BytesTextureSource, ReaderTextureSource:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l MemoryTextureLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
+	switch source := param.(type) {
+	case ImageTextureSource:
+		return loadTextureImage(uri, source.Image, TextureParams{
+			NearestFiltering:   source.NearestFiltering,
+			Sampling:           source.Sampling,
+			PremultipliedAlpha: source.PremultipliedAlpha,
+			SRGB:               source.SRGB,
+		})
+	case BytesTextureSource:
+		return loadTextureFromReader(uri, bytes.NewReader(source.Data), TextureParams{
+			NearestFiltering:   source.NearestFiltering,
+			Sampling:           source.Sampling,
+			AllFrames:          source.AllFrames,
+			PremultipliedAlpha: source.PremultipliedAlpha,
+			SRGB:               source.SRGB,
+		})
+	case ReaderTextureSource:
+		if source.Reader == nil {
+			return GetEmptyTexture(uri), errors.New("texture reader is nil")
+		}
+
+		rsc, err := loadTextureFromReader(uri, source.Reader, TextureParams{
+			NearestFiltering:   source.NearestFiltering,
+			Sampling:           source.Sampling,
+			AllFrames:          source.AllFrames,
+			PremultipliedAlpha: source.PremultipliedAlpha,
+			SRGB:               source.SRGB,
+		})
+		rsc.Volatile = true
+		return rsc, err
+	default:
+		return GetEmptyTexture(uri), errors.New("unsuported texture source")
+	}
+}
+
+func NewMemoryTextureLoader() MemoryTextureLoader {
+	return MemoryTextureLoader{}
+}
+
+// loadTextureFromReader decodes and uploads a texture container or an image.
+func loadTextureFromReader(uri string, reader io.Reader, textureParams TextureParams) (core.Resource, error) {
+	bufferedReader := bufio.NewReader(reader)
+	header, _ := bufferedReader.Peek(16)
+	if rsc, ok, err := loadContainerTexture(uri, header, bufferedReader, textureParams); ok {
+		return rsc, err
+	}
+
+	var img image.Image
+	var err error
+	if textureParams.AllFrames && isGif(header) {
+		var animation GifAnimation
+		animation, err = DecodeGifAnimation(bufferedReader)
+		img = animation.Sheet()
+	} else {
+		img, _, err = image.Decode(bufferedReader)
+	}
+
+	if err != nil {
+		return GetEmptyTexture(uri), err
+	}
+
+	return loadTextureImage(uri, img, textureParams)
+}
+
+func loadTextureImage(uri string, img image.Image, textureParams TextureParams) (core.Resource, error) {
+	if img == nil || img.Bounds().Empty() {
+		return GetEmptyTexture(uri), errors.New("texture image is empty")
+	}
+
+	return createImageTexture(uri, img, textureParams), nil
+}
+
+// texturePixels are image pixels in a layout OpenGL can upload directly.
+type texturePixels struct {
+	internalFormat int32
+	format         uint32
+	pix            []byte
+	// rowLength is the row stride in pixels
+	rowLength     int32
+	bytesPerPixel int
+	gray          bool
+}
+
+// imagePixels uploads images already in the requested alpha mode, and gray images, as they are,
+// with any stride and bounds. All other formats, like paletted or 16 bit images, are converted.
+// Gray images keep a single channel, unless they are sRGB encoded.
+func imagePixels(img image.Image, premultiplied bool, srgb bool) texturePixels {
+	internalFormat := int32(gl.RGBA8)
+	if srgb {
+		internalFormat = gl.SRGB8_ALPHA8
+	}
+
+	switch src := img.(type) {
+	case *image.RGBA:
+		if premultiplied {
+			return texturePixels{internalFormat, gl.RGBA, src.Pix, int32(src.Stride / 4), 4, false}
+		}
+	case *image.NRGBA:
+		if !premultiplied {
+			return texturePixels{internalFormat, gl.RGBA, src.Pix, int32(src.Stride / 4), 4, false}
+		}
+	case *image.Gray:
+		if !srgb {
+			return texturePixels{gl.R8, gl.RED, src.Pix, int32(src.Stride), 1, true}
+		}
+	}
+
+	return convertImagePixels(img, premultiplied, internalFormat)
+}
+
+// colorImagePixels is imagePixels without the single channel layout for gray images,
+// for uploads where all images have to share the RGBA layout, like texture array layers.
+func colorImagePixels(img image.Image, premultiplied bool, srgb bool) texturePixels {
+	pixels := imagePixels(img, premultiplied, srgb)
+	if pixels.gray {
+		return convertImagePixels(img, premultiplied, gl.RGBA8)
+	}
+
+	return pixels
+}
+
+// convertImagePixels draws the image into tightly packed RGBA pixels, with premultiplied or straight alpha.
+func convertImagePixels(img image.Image, premultiplied bool, internalFormat int32) texturePixels {
+	bounds := img.Bounds()
+	var converted draw.Image
+	var pix []byte
+	if premultiplied {
+		rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		converted, pix = rgba, rgba.Pix
+	} else {
+		nrgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		converted, pix = nrgba, nrgba.Pix
+	}
+
+	draw.Draw(converted, converted.Bounds(), img, bounds.Min, draw.Src)
+	return texturePixels{internalFormat, gl.RGBA, pix, int32(bounds.Dx()), 4, false}
+}
+
+// upload uploads the pixels to the bound texture target, like TEXTURE_2D or a cubemap face.
+func (p texturePixels) upload(target uint32, width, height int32) {
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, p.rowLength)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexImage2D(target, 0, p.internalFormat, width, height, 0, p.format, gl.UNSIGNED_BYTE, gl.Ptr(p.pix))
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+}
+
+// createImageTexture uploads the image, generating mipmaps if requested.
+func createImageTexture(uri string, img image.Image, textureParams TextureParams) core.Resource {
+	sampling := textureParams.Sampling.withDefaults(textureParams.NearestFiltering)
+	pixels := imagePixels(img, textureParams.PremultipliedAlpha, textureParams.SRGB)
+	width, height := int32(img.Bounds().Dx()), int32(img.Bounds().Dy())
+
+	var textureId uint32
+	gl.GenTextures(1, &textureId)
+	gl.BindTexture(gl.TEXTURE_2D, textureId)
+	defer gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	pixels.upload(gl.TEXTURE_2D, width, height)
+
+	if pixels.gray {
+		swizzle := []int32{gl.RED, gl.RED, gl.RED, gl.ONE}
+		gl.TexParameteriv(gl.TEXTURE_2D, gl.TEXTURE_SWIZZLE_RGBA, &swizzle[0])
+	}
+
+	mipLevels := 1
+	if sampling.GenerateMipmaps {
+		mipLevels = MipLevelCount(width, height)
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+
+	applyTextureSampling(gl.TEXTURE_2D, sampling, mipLevels)
+
+	return core.Resource{
+		Type:  RT_TEXTURE,
+		Uri:   uri,
+		Empty: false,
+		Size:  EstimateTextureSize(width, height, pixels.bytesPerPixel, mipLevels),
+		Data: TextureData{
+			Id:            textureId,
+			Width:         width,
+			Height:        height,
+			Premultiplied: textureParams.PremultipliedAlpha,
+			SRGB:          textureParams.SRGB,
+		},
+		Unload: func() {
+			gl.DeleteTextures(1, &textureId)
+		},
+	}
+}
@@ -0,0 +1,395 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// WhiteTextureParams creates a 1x1 white texture, to draw untextured shapes with textured shaders.
+type WhiteTextureParams struct{}
+
+type SolidTextureParams struct {
+	Width  int
+	Height int
+	Color  core.Color
+}
+
+type CheckerTextureParams struct {
+	Width  int
+	Height int
+	// CellSize is the size of a single checker cell in pixels
+	CellSize         int
+	Color1           core.Color
+	Color2           core.Color
+	NearestFiltering bool
+	Sampling         TextureSampling
+}
+
+type GradientType uint8
+
+const (
+	GT_LINEAR GradientType = iota
+	GT_RADIAL
+)
+
+type GradientStop struct {
+	// Offset is the stop position in the 0..1 range, stops have to be sorted by offset
+	Offset float32
+	Color  core.Color
+}
+
+// GradientTextureParams creates a gradient texture. Points are in texture coordinates,
+// (0, 0) is the top left corner and (1, 1) the bottom right one.
+type GradientTextureParams struct {
+	Width  int
+	Height int
+	Type   GradientType
+	Stops  []GradientStop
+	// Start and End are the linear gradient endpoints
+	Start mgl32.Vec2
+	End   mgl32.Vec2
+	// Center and Radius describe the radial gradient circle
+	Center           mgl32.Vec2
+	Radius           float32
+	NearestFiltering bool
+	Sampling         TextureSampling
+}
+
+type NoiseType uint8
+
+const (
+	NT_PERLIN NoiseType = iota
+	NT_SIMPLEX
+	// NT_WORLEY is the distance to the closest cell feature point
+	NT_WORLEY
+)
+
+// NoiseTextureParams creates a single channel noise texture, sampled as gray.
+// Octaves are summed as fractal noise, each with Lacunarity times the frequency
+// and Persistence times the amplitude of the previous one.
+type NoiseTextureParams struct {
+	Width  int
+	Height int
+	Type   NoiseType
+	Seed   int64
+	// Scale is the number of noise cells across the texture width, 4 by default
+	Scale float32
+	// Octaves is 1 by default
+	Octaves int
+	// Persistence is 0.5 by default
+	Persistence float32
+	// Lacunarity is 2 by default
+	Lacunarity       float32
+	NearestFiltering bool
+	Sampling         TextureSampling
+}
+
+type ProceduralTextureLoader struct{}
+
+func (l ProceduralTextureLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
+	if resourceType != RT_TEXTURE {
+		return false
+	}
+
+	switch param.(type) {
+	case WhiteTextureParams, SolidTextureParams, CheckerTextureParams, GradientTextureParams, NoiseTextureParams:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l ProceduralTextureLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
+	var img image.Image
+	var textureParams TextureParams
+	var err error
+
+	switch params := param.(type) {
+	case WhiteTextureParams:
+		img = solidImage(1, 1, core.Color{1, 1, 1, 1})
+		textureParams.NearestFiltering = true
+	case SolidTextureParams:
+		if err = checkProceduralSize(params.Width, params.Height); err == nil {
+			img = solidImage(params.Width, params.Height, params.Color)
+		}
+		textureParams.NearestFiltering = true
+	case CheckerTextureParams:
+		if err = checkProceduralSize(params.Width, params.Height); err == nil {
+			img, err = checkerImage(params)
+		}
+		textureParams.NearestFiltering, textureParams.Sampling = params.NearestFiltering, params.Sampling
+	case GradientTextureParams:
+		if err = checkProceduralSize(params.Width, params.Height); err == nil {
+			img, err = gradientImage(params)
+		}
+		textureParams.NearestFiltering, textureParams.Sampling = params.NearestFiltering, params.Sampling
+	case NoiseTextureParams:
+		if err = checkProceduralSize(params.Width, params.Height); err == nil {
+			img, err = noiseImage(params)
+		}
+		textureParams.NearestFiltering, textureParams.Sampling = params.NearestFiltering, params.Sampling
+	default:
+		err = errors.New("unsuported procedural texture")
+	}
+
+	if err != nil {
+		return GetEmptyTexture(uri), err
+	}
+
+	return createImageTexture(uri, img, textureParams), nil
+}
+
+func NewProceduralTextureLoader() ProceduralTextureLoader {
+	return ProceduralTextureLoader{}
+}
+
+func checkProceduralSize(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("invalid procedural texture size: %dx%d", width, height)
+	}
+
+	return nil
+}
+
+func toNRGBA(c core.Color) color.NRGBA {
+	channel := func(value float32) uint8 {
+		return uint8(math.Round(float64(mgl32.Clamp(value, 0, 1)) * 255))
+	}
+
+	return color.NRGBA{channel(c[0]), channel(c[1]), channel(c[2]), channel(c[3])}
+}
+
+func solidImage(width, height int, c core.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	pixel := toNRGBA(c)
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = pixel.R, pixel.G, pixel.B, pixel.A
+	}
+
+	return img
+}
+
+func checkerImage(params CheckerTextureParams) (*image.NRGBA, error) {
+	if params.CellSize <= 0 {
+		return nil, fmt.Errorf("invalid checker cell size: %d", params.CellSize)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, params.Width, params.Height))
+	colors := [2]color.NRGBA{toNRGBA(params.Color1), toNRGBA(params.Color2)}
+	for y := 0; y < params.Height; y++ {
+		for x := 0; x < params.Width; x++ {
+			img.SetNRGBA(x, y, colors[(x/params.CellSize+y/params.CellSize)%2])
+		}
+	}
+
+	return img, nil
+}
+
+func gradientImage(params GradientTextureParams) (*image.NRGBA, error) {
+	if len(params.Stops) == 0 {
+		return nil, errors.New("gradient has no stops")
+	}
+
+	var position func(p mgl32.Vec2) float32
+	switch params.Type {
+	case GT_LINEAR:
+		direction := params.End.Sub(params.Start)
+		length := direction.Dot(direction)
+		if length == 0 {
+			return nil, errors.New("linear gradient start and end are the same point")
+		}
+		position = func(p mgl32.Vec2) float32 {
+			return p.Sub(params.Start).Dot(direction) / length
+		}
+	case GT_RADIAL:
+		if params.Radius <= 0 {
+			return nil, fmt.Errorf("invalid radial gradient radius: %f", params.Radius)
+		}
+		position = func(p mgl32.Vec2) float32 {
+			return p.Sub(params.Center).Len() / params.Radius
+		}
+	default:
+		return nil, errors.New("unsuported gradient type")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, params.Width, params.Height))
+	for y := 0; y < params.Height; y++ {
+		for x := 0; x < params.Width; x++ {
+			// sampled at the pixel centers
+			p := mgl32.Vec2{(float32(x) + 0.5) / float32(params.Width), (float32(y) + 0.5) / float32(params.Height)}
+			img.SetNRGBA(x, y, toNRGBA(gradientColor(params.Stops, position(p))))
+		}
+	}
+
+	return img, nil
+}
+
+// gradientColor interpolates the stops, positions outside of the stops get the first or the last color.
+func gradientColor(stops []GradientStop, t float32) core.Color {
+	if t <= stops[0].Offset {
+		return stops[0].Color
+	}
+
+	for i := 1; i < len(stops); i++ {
+		if t > stops[i].Offset {
+			continue
+		}
+
+		from, to := stops[i-1], stops[i]
+		span := to.Offset - from.Offset
+		if span <= 0 {
+			return to.Color
+		}
+
+		f := (t - from.Offset) / span
+		var c core.Color
+		for ch := range c {
+			c[ch] = from.Color[ch] + (to.Color[ch]-from.Color[ch])*f
+		}
+		return c
+	}
+
+	return stops[len(stops)-1].Color
+}
+
+func noiseImage(params NoiseTextureParams) (*image.Gray, error) {
+	scale := float64(core.IfThenElse(params.Scale > 0, params.Scale, float32(4)).(float32))
+	octaves := core.IfThenElse(params.Octaves > 0, params.Octaves, 1).(int)
+	persistence := float64(core.IfThenElse(params.Persistence > 0, params.Persistence, float32(0.5)).(float32))
+	lacunarity := float64(core.IfThenElse(params.Lacunarity > 0, params.Lacunarity, float32(2)).(float32))
+
+	n := newNoise(params.Seed)
+	var sample func(x, y float64) float64
+	switch params.Type {
+	case NT_PERLIN:
+		sample = n.perlin
+	case NT_SIMPLEX:
+		sample = n.simplex
+	case NT_WORLEY:
+		sample = n.worley
+	default:
+		return nil, errors.New("unsuported noise type")
+	}
+
+	img := image.NewGray(image.Rect(0, 0, params.Width, params.Height))
+	cellSize := float64(params.Width) / scale
+	for y := 0; y < params.Height; y++ {
+		for x := 0; x < params.Width; x++ {
+			value, amplitude, frequency, total := 0.0, 1.0, 1.0, 0.0
+			for o := 0; o < octaves; o++ {
+				value += sample(float64(x)/cellSize*frequency, float64(y)/cellSize*frequency) * amplitude
+				total += amplitude
+				amplitude *= persistence
+				frequency *= lacunarity
+			}
+
+			img.Pix[y*img.Stride+x] = uint8(math.Round(math.Max(0, math.Min(1, value/total)) * 255))
+		}
+	}
+
+	return img, nil
+}
+
+// noise holds the seeded permutation table shared by the noise functions, all of them return values in the 0..1 range.
+type noise struct {
+	perm [512]int
+}
+
+func newNoise(seed int64) *noise {
+	n := &noise{}
+	for i, p := range rand.New(rand.NewSource(seed)).Perm(256) {
+		n.perm[i], n.perm[i+256] = p, p
+	}
+
+	return n
+}
+
+func (n *noise) hash(x, y int) int {
+	return n.perm[n.perm[x&255]+(y&255)]
+}
+
+var noiseGradients = [8][2]float64{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {-1, 1}, {1, -1}, {-1, -1}}
+
+func (n *noise) gradient(hash int, x, y float64) float64 {
+	g := noiseGradients[hash&7]
+	return g[0]*x + g[1]*y
+}
+
+func (n *noise) perlin(x, y float64) float64 {
+	fade := func(t float64) float64 {
+		return t * t * t * (t*(t*6-15) + 10)
+	}
+	lerp := func(a, b, t float64) float64 {
+		return a + (b-a)*t
+	}
+
+	x0, y0 := math.Floor(x), math.Floor(y)
+	ix, iy := int(x0), int(y0)
+	fx, fy := x-x0, y-y0
+	u, v := fade(fx), fade(fy)
+
+	value := lerp(
+		lerp(n.gradient(n.hash(ix, iy), fx, fy), n.gradient(n.hash(ix+1, iy), fx-1, fy), u),
+		lerp(n.gradient(n.hash(ix, iy+1), fx, fy-1), n.gradient(n.hash(ix+1, iy+1), fx-1, fy-1), u),
+		v)
+
+	// the values span -1..1, the extremes are reached at the cell centers with the diagonal gradients
+	return math.Max(0, math.Min(1, value*0.5+0.5))
+}
+
+func (n *noise) simplex(x, y float64) float64 {
+	f2 := 0.5 * (math.Sqrt(3) - 1)
+	g2 := (3 - math.Sqrt(3)) / 6
+
+	s := (x + y) * f2
+	i, j := math.Floor(x+s), math.Floor(y+s)
+	t := (i + j) * g2
+	x0, y0 := x-(i-t), y-(j-t)
+
+	// the simplex triangle containing the point
+	i1, j1 := 0, 1
+	if x0 > y0 {
+		i1, j1 = 1, 0
+	}
+
+	x1, y1 := x0-float64(i1)+g2, y0-float64(j1)+g2
+	x2, y2 := x0-1+2*g2, y0-1+2*g2
+	ii, jj := int(i), int(j)
+
+	corner := func(hash int, x, y float64) float64 {
+		t := 0.5 - x*x - y*y
+		if t < 0 {
+			return 0
+		}
+		t *= t
+		return t * t * n.gradient(hash, x, y)
+	}
+
+	value := corner(n.hash(ii, jj), x0, y0) +
+		corner(n.hash(ii+i1, jj+j1), x1, y1) +
+		corner(n.hash(ii+1, jj+1), x2, y2)
+
+	return value*70*0.5 + 0.5
+}
+
+func (n *noise) worley(x, y float64) float64 {
+	cx, cy := int(math.Floor(x)), int(math.Floor(y))
+	closest := math.MaxFloat64
+	for oy := -1; oy <= 1; oy++ {
+		for ox := -1; ox <= 1; ox++ {
+			h := n.hash(cx+ox, cy+oy)
+			px := float64(cx+ox) + float64(h)/255
+			py := float64(cy+oy) + float64(n.perm[h+1])/255
+			closest = math.Min(closest, math.Hypot(px-x, py-y))
+		}
+	}
+
+	return closest
+}
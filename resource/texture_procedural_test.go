@@ -0,0 +1,30 @@
+package resource
+
+import "testing"
+
+func TestPerlinRange(t *testing.T) {
+	for seed := int64(0); seed < 4; seed++ {
+		n := newNoise(seed)
+		min, max := 1.0, 0.0
+
+		for y := 0; y < 200; y++ {
+			for x := 0; x < 200; x++ {
+				value := n.perlin(float64(x)*0.093, float64(y)*0.087)
+				if value < 0 || value > 1 {
+					t.Fatalf("seed %d: value %f outside of the 0..1 range", seed, value)
+				}
+
+				if value < min {
+					min = value
+				}
+				if value > max {
+					max = value
+				}
+			}
+		}
+
+		if min > 0.15 || max < 0.85 {
+			t.Errorf("seed %d: values span %f..%f, expected close to 0..1", seed, min, max)
+		}
+	}
+}
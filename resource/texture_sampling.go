@@ -0,0 +1,169 @@
+package resource
+
+import (
+	"math/bits"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+type TextureFilter uint8
+
+const (
+	// TF_DEFAULT is linear filtering, or nearest when NearestFiltering is set.
+	// With mipmaps the minification filter becomes trilinear (or nearest mipmap nearest).
+	TF_DEFAULT TextureFilter = iota
+	TF_NEAREST
+	TF_LINEAR
+	TF_NEAREST_MIPMAP_NEAREST
+	TF_LINEAR_MIPMAP_NEAREST
+	TF_NEAREST_MIPMAP_LINEAR
+	TF_TRILINEAR
+)
+
+type TextureWrap uint8
+
+const (
+	TW_REPEAT TextureWrap = iota
+	TW_MIRRORED_REPEAT
+	TW_CLAMP_TO_EDGE
+	TW_CLAMP_TO_BORDER
+)
+
+type TextureSampling struct {
+	GenerateMipmaps bool
+	MinFilter       TextureFilter
+	MagFilter       TextureFilter
+	WrapS           TextureWrap
+	WrapT           TextureWrap
+	// BorderColor is used by the TW_CLAMP_TO_BORDER wrap mode
+	BorderColor core.Color
+	// Anisotropy is the max anisotropy level, values <= 1 disable anisotropic filtering.
+	// It's clamped to the driver limit and ignored if the extension is missing.
+	Anisotropy float32
+}
+
+var anisotropyChecked bool
+var maxAnisotropy float32
+
+// withDefaults replaces the default filters with the ones selected by the nearest filtering flag.
+func (s TextureSampling) withDefaults(nearestFiltering bool) TextureSampling {
+	if s.MagFilter == TF_DEFAULT {
+		s.MagFilter = core.IfThenElse(nearestFiltering, TF_NEAREST, TF_LINEAR).(TextureFilter)
+	}
+
+	if s.MinFilter == TF_DEFAULT {
+		if s.GenerateMipmaps {
+			s.MinFilter = core.IfThenElse(nearestFiltering, TF_NEAREST_MIPMAP_NEAREST, TF_TRILINEAR).(TextureFilter)
+		} else {
+			s.MinFilter = s.MagFilter
+		}
+	}
+
+	return s
+}
+
+func (f TextureFilter) withoutMipmaps() TextureFilter {
+	switch f {
+	case TF_NEAREST_MIPMAP_NEAREST, TF_NEAREST_MIPMAP_LINEAR:
+		return TF_NEAREST
+	case TF_LINEAR_MIPMAP_NEAREST, TF_TRILINEAR:
+		return TF_LINEAR
+	default:
+		return f
+	}
+}
+
+func (f TextureFilter) glFilter() int32 {
+	switch f {
+	case TF_NEAREST:
+		return gl.NEAREST
+	case TF_NEAREST_MIPMAP_NEAREST:
+		return gl.NEAREST_MIPMAP_NEAREST
+	case TF_LINEAR_MIPMAP_NEAREST:
+		return gl.LINEAR_MIPMAP_NEAREST
+	case TF_NEAREST_MIPMAP_LINEAR:
+		return gl.NEAREST_MIPMAP_LINEAR
+	case TF_TRILINEAR:
+		return gl.LINEAR_MIPMAP_LINEAR
+	default:
+		return gl.LINEAR
+	}
+}
+
+func (w TextureWrap) glWrap() int32 {
+	switch w {
+	case TW_MIRRORED_REPEAT:
+		return gl.MIRRORED_REPEAT
+	case TW_CLAMP_TO_EDGE:
+		return gl.CLAMP_TO_EDGE
+	case TW_CLAMP_TO_BORDER:
+		return gl.CLAMP_TO_BORDER
+	default:
+		return gl.REPEAT
+	}
+}
+
+// MipLevelCount returns the number of levels of a full mip chain.
+func MipLevelCount(width, height int32) int {
+	return bits.Len32(uint32(core.MaxOfInt32(width, height, 1)))
+}
+
+// applyTextureSampling sets the sampling parameters of the texture bound to target.
+// Mipmap filters are downgraded when the texture has a single level, so it stays complete.
+func applyTextureSampling(target uint32, sampling TextureSampling, mipLevels int) {
+	minFilter := sampling.MinFilter
+	if mipLevels <= 1 {
+		minFilter = minFilter.withoutMipmaps()
+	}
+
+	gl.TexParameteri(target, gl.TEXTURE_BASE_LEVEL, 0)
+	gl.TexParameteri(target, gl.TEXTURE_MAX_LEVEL, int32(core.MaxOfInt32(int32(mipLevels)-1, 0)))
+
+	gl.TexParameteri(target, gl.TEXTURE_MIN_FILTER, minFilter.glFilter())
+	gl.TexParameteri(target, gl.TEXTURE_MAG_FILTER, sampling.MagFilter.withoutMipmaps().glFilter())
+
+	gl.TexParameteri(target, gl.TEXTURE_WRAP_S, sampling.WrapS.glWrap())
+	gl.TexParameteri(target, gl.TEXTURE_WRAP_T, sampling.WrapT.glWrap())
+
+	if sampling.WrapS == TW_CLAMP_TO_BORDER || sampling.WrapT == TW_CLAMP_TO_BORDER {
+		gl.TexParameterfv(target, gl.TEXTURE_BORDER_COLOR, &sampling.BorderColor[0])
+	}
+
+	if sampling.Anisotropy > 1 {
+		if limit := getMaxAnisotropy(); limit > 1 {
+			anisotropy := sampling.Anisotropy
+			if anisotropy > limit {
+				anisotropy = limit
+			}
+			gl.TexParameterf(target, gl.TEXTURE_MAX_ANISOTROPY, anisotropy)
+		}
+	}
+}
+
+// getMaxAnisotropy returns the driver anisotropy limit, or 0 when anisotropic filtering isn't available.
+func getMaxAnisotropy() float32 {
+	if anisotropyChecked {
+		return maxAnisotropy
+	}
+
+	anisotropyChecked = true
+	if HasGlExtension("GL_EXT_texture_filter_anisotropic") || HasGlExtension("GL_ARB_texture_filter_anisotropic") {
+		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &maxAnisotropy)
+	}
+
+	return maxAnisotropy
+}
+
+func HasGlExtension(name string) bool {
+	var count int32
+	gl.GetIntegerv(gl.NUM_EXTENSIONS, &count)
+
+	for i := int32(0); i < count; i++ {
+		if gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i))) == name {
+			return true
+		}
+	}
+
+	return false
+}
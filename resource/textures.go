@@ -2,14 +2,17 @@ package resource
 
 import (
 	"errors"
+	"fmt"
 	"image"
-	"image/draw"
+	"image/color"
+	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
-	"os"
 
 	"github.com/ddomurad/goCraft/core"
-	"github.com/go-gl/gl/v3.3-core/gl"
+	_ "github.com/ddomurad/goCraft/tga"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
 )
 
 const (
@@ -19,10 +22,49 @@ const (
 type TextureParams struct {
 	FilePath         string
 	NearestFiltering bool
+	Sampling         TextureSampling
+	// AllFrames loads every frame of an animated GIF into a grid, see GifAnimation.Sheet, instead of the first frame only.
+	// Use GifTextureArrayParams to load the frames as texture array layers.
+	AllFrames bool
+	// PremultipliedAlpha uploads the colors multiplied by alpha, which avoids dark fringes on filtered edges.
+	// Baked and compressed containers are uploaded as stored, the flag tells how they were authored.
+	PremultipliedAlpha bool
+	// SRGB marks the pixels as sRGB encoded, so sampling returns linear colors
+	SRGB bool
 }
 
 type TextureData struct {
-	Id uint32
+	Id     uint32
+	Width  int32
+	Height int32
+	// SubTexture marks a region of a larger texture (like an atlas page) described by UVRect.
+	// Width and Height are the region size in pixels.
+	SubTexture bool
+	UVRect     [4]float32
+	// Premultiplied textures are drawn with premultiplied alpha blending
+	Premultiplied bool
+	SRGB          bool
+}
+
+// GetUVRect returns the (u, v, width, height) rectangle of the texture coordinates space used by the texture.
+func (t TextureData) GetUVRect() [4]float32 {
+	if !t.SubTexture {
+		return [4]float32{0, 0, 1, 1}
+	}
+
+	return t.UVRect
+}
+
+// EstimateTextureSize returns the memory used by a texture with the given number of mip levels.
+func EstimateTextureSize(width, height int32, bytesPerPixel int, mipLevels int) int64 {
+	var size int64
+	for level := 0; level < mipLevels; level++ {
+		w := int64(core.MaxOfInt32(width>>level, 1))
+		h := int64(core.MaxOfInt32(height>>level, 1))
+		size += w * h * int64(bytesPerPixel)
+	}
+
+	return size
 }
 
 func GetEmptyTexture(uri string) core.Resource {
@@ -36,7 +78,82 @@ func GetEmptyTexture(uri string) core.Resource {
 	}
 }
 
-type FileTextureLoader struct{}
+// GetTexture returns the texture data of a loaded texture resource.
+// If the resource is missing or has a different type, the texture placeholder is returned along with the error.
+func GetTexture(rm *core.ResourceManager, uri string) (TextureData, error) {
+	rsc, err := getTypedResource(rm, RT_TEXTURE, uri)
+
+	data, ok := textureData(rsc.Data)
+	if err == nil && !ok {
+		err = fmt.Errorf("resource %q does not hold texture data", uri)
+	}
+
+	return data, err
+}
+
+// textureData returns the texture data of static and dynamic textures.
+func textureData(data core.ResourceData) (TextureData, bool) {
+	switch texture := data.(type) {
+	case TextureData:
+		return texture, true
+	case *DynamicTexture:
+		return texture.TextureData, true
+	default:
+		return TextureData{}, false
+	}
+}
+
+type TextureHandle struct {
+	core.Handle
+}
+
+// LoadTexture returns a handle to the texture, loading it first if it's not registered yet.
+func LoadTexture(rm *core.ResourceManager, uri string, params TextureParams) (TextureHandle, error) {
+	handle, err := rm.LoadResource(RT_TEXTURE, uri, params)
+	return TextureHandle{handle}, err
+}
+
+func GetTextureHandle(rm *core.ResourceManager, uri string) (TextureHandle, error) {
+	handle, err := getTypedHandle(rm, RT_TEXTURE, uri)
+	return TextureHandle{handle}, err
+}
+
+// ResolveTexture returns the texture data referenced by the handle.
+// For stale handles the texture placeholder is returned along with the error.
+func ResolveTexture(rm *core.ResourceManager, handle TextureHandle) (TextureData, error) {
+	rscData, err := resolveTypedResource(rm, RT_TEXTURE, handle.Handle)
+
+	data, ok := textureData(rscData)
+	if err == nil && !ok {
+		err = errors.New("resource does not hold texture data")
+	}
+
+	return data, err
+}
+
+// CreateErrorTexture creates a magenta checkerboard texture used in place of textures that failed to load.
+func CreateErrorTexture() (core.Resource, error) {
+	const size = 8
+	magenta := color.RGBA{R: 255, G: 0, B: 255, A: 255}
+	black := color.RGBA{R: 0, G: 0, B: 0, A: 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if (x/2+y/2)%2 == 0 {
+				img.SetRGBA(x, y, magenta)
+			} else {
+				img.SetRGBA(x, y, black)
+			}
+		}
+	}
+
+	return createImageTexture("placeholder_texture", img, TextureParams{NearestFiltering: true}), nil
+}
+
+type FileTextureLoader struct {
+	vfs *core.VFS
+}
 
 func (l FileTextureLoader) CanLoad(resourceType core.ResourceType, uri string, param core.LoaderParam) bool {
 	if resourceType != RT_TEXTURE {
@@ -53,58 +170,18 @@ func (l FileTextureLoader) CanLoad(resourceType core.ResourceType, uri string, p
 
 func (l FileTextureLoader) Load(uri string, param core.LoaderParam) (core.Resource, error) {
 	textureParams := param.(TextureParams)
-	textureFile, err := os.Open(textureParams.FilePath)
+	textureFile, err := l.vfs.Open(textureParams.FilePath)
 
 	if err != nil {
 		return GetEmptyTexture(uri), err
 	}
 	defer textureFile.Close()
 
-	img, _, err := image.Decode(textureFile)
-	if err != nil {
-		return GetEmptyTexture(uri), err
-	}
-
-	rgba := image.NewRGBA(img.Bounds())
-	draw.Draw(rgba, rgba.Bounds(), img, image.Pt(0, 0), draw.Src)
-
-	if rgba.Stride != rgba.Rect.Size().X*4 { // TODO-cs: why?
-		return GetEmptyTexture(uri), errors.New("unsported stride")
-	}
-
-	var textureId uint32
-	gl.GenTextures(1, &textureId)
-	gl.BindTexture(gl.TEXTURE_2D, textureId)
-	defer gl.BindTexture(gl.TEXTURE_2D, 0)
-
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 0)
-
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA,
-		int32(rgba.Rect.Size().X), int32(rgba.Rect.Size().Y),
-		0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
-
-	if textureParams.NearestFiltering {
-		gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-		gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
-	} else {
-		gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-		gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	}
-
-	return core.Resource{
-		Type:  RT_TEXTURE,
-		Uri:   uri,
-		Empty: false,
-		Data: TextureData{
-			Id: textureId,
-		},
-		Unload: func() {
-			gl.DeleteTextures(1, &textureId)
-		},
-	}, nil
+	return loadTextureFromReader(uri, textureFile, textureParams)
 }
 
-func NewFileTextureLoader() FileTextureLoader {
-	return FileTextureLoader{}
+func NewFileTextureLoader(vfs *core.VFS) FileTextureLoader {
+	return FileTextureLoader{
+		vfs: vfs,
+	}
 }
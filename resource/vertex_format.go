@@ -0,0 +1,267 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+type AttributeType uint8
+
+const (
+	AT_FLOAT AttributeType = iota
+	AT_HALF_FLOAT
+	AT_BYTE
+	AT_UNSIGNED_BYTE
+	AT_SHORT
+	AT_UNSIGNED_SHORT
+	AT_INT
+	AT_UNSIGNED_INT
+)
+
+// Size returns the size of a single component in bytes.
+func (t AttributeType) Size() int {
+	switch t {
+	case AT_BYTE, AT_UNSIGNED_BYTE:
+		return 1
+	case AT_HALF_FLOAT, AT_SHORT, AT_UNSIGNED_SHORT:
+		return 2
+	default:
+		return 4
+	}
+}
+
+func (t AttributeType) glType() uint32 {
+	switch t {
+	case AT_HALF_FLOAT:
+		return gl.HALF_FLOAT
+	case AT_BYTE:
+		return gl.BYTE
+	case AT_UNSIGNED_BYTE:
+		return gl.UNSIGNED_BYTE
+	case AT_SHORT:
+		return gl.SHORT
+	case AT_UNSIGNED_SHORT:
+		return gl.UNSIGNED_SHORT
+	case AT_INT:
+		return gl.INT
+	case AT_UNSIGNED_INT:
+		return gl.UNSIGNED_INT
+	default:
+		return gl.FLOAT
+	}
+}
+
+// VertexAttribute describes a single vertex shader input.
+type VertexAttribute struct {
+	// Name is the shader input name, shaders get it bound to Location when linked
+	Name       string
+	Location   uint32
+	Components int32
+	Type       AttributeType
+	// Normalized maps integer components to the 0..1 (or -1..1 for signed types) range
+	Normalized bool
+	// Integer attributes are read as ints by the shader, instead of being converted to floats
+	Integer bool
+	// Divisor > 0 makes the attribute advance once per that many instances instead of once per vertex
+	Divisor uint32
+}
+
+// Size returns the size of the attribute in bytes.
+func (a VertexAttribute) Size() int {
+	return int(a.Components) * a.Type.Size()
+}
+
+// Standard attributes, the default shaders use the position and texture coordinates.
+var (
+	VA_POSITION2 = VertexAttribute{Name: "aPos", Location: 0, Components: 2, Type: AT_FLOAT}
+	VA_POSITION3 = VertexAttribute{Name: "aPos", Location: 0, Components: 3, Type: AT_FLOAT}
+	VA_UV        = VertexAttribute{Name: "aTex", Location: 1, Components: 2, Type: AT_FLOAT}
+	VA_COLOR     = VertexAttribute{Name: "aColor", Location: 2, Components: 4, Type: AT_FLOAT}
+	// VA_COLOR_RGBA8 is a color packed into 4 normalized bytes
+	VA_COLOR_RGBA8 = VertexAttribute{Name: "aColor", Location: 2, Components: 4, Type: AT_UNSIGNED_BYTE, Normalized: true}
+	VA_NORMAL      = VertexAttribute{Name: "aNormal", Location: 3, Components: 3, Type: AT_FLOAT}
+	VA_TANGENT     = VertexAttribute{Name: "aTangent", Location: 4, Components: 4, Type: AT_FLOAT}
+	VA_UV2         = VertexAttribute{Name: "aTex2", Location: 5, Components: 2, Type: AT_FLOAT}
+	// VA_COVERAGE is the anti-aliasing coverage of the stroke vertices
+	VA_COVERAGE = VertexAttribute{Name: "aCoverage", Location: 6, Components: 1, Type: AT_FLOAT}
+)
+
+// Standard per-instance attributes, read by the instanced variants of the default shaders.
+// The transform is a 2D affine matrix, split into its two rows.
+var (
+	VA_INSTANCE_TRANSFORM0 = VertexAttribute{Name: "aInstanceTransform0", Location: 8, Components: 3, Type: AT_FLOAT, Divisor: 1}
+	VA_INSTANCE_TRANSFORM1 = VertexAttribute{Name: "aInstanceTransform1", Location: 9, Components: 3, Type: AT_FLOAT, Divisor: 1}
+	VA_INSTANCE_COLOR      = VertexAttribute{Name: "aInstanceColor", Location: 10, Components: 4, Type: AT_FLOAT, Divisor: 1}
+	VA_INSTANCE_UV_RECT    = VertexAttribute{Name: "aInstanceUVRect", Location: 11, Components: 4, Type: AT_FLOAT, Divisor: 1}
+)
+
+// standardAttributes are bound to their locations when shaders are linked,
+// so shaders without layout qualifiers work with the standard formats.
+var standardAttributes = []VertexAttribute{
+	VA_POSITION3, VA_UV, VA_COLOR, VA_NORMAL, VA_TANGENT, VA_UV2, VA_COVERAGE,
+	VA_INSTANCE_TRANSFORM0, VA_INSTANCE_TRANSFORM1, VA_INSTANCE_COLOR, VA_INSTANCE_UV_RECT,
+}
+
+type VertexLayout uint8
+
+const (
+	// VL_INTERLEAVED stores all the attributes of a vertex next to each other, in a single buffer
+	VL_INTERLEAVED VertexLayout = iota
+	// VL_SEPARATE stores every attribute in its own buffer
+	VL_SEPARATE
+)
+
+type VertexFormat struct {
+	Attributes []VertexAttribute
+	Layout     VertexLayout
+}
+
+// Standard vertex formats, VF_POS3_UV2 is the layout of the default meshes.
+var (
+	VF_POS3_UV2         = VertexFormat{Attributes: []VertexAttribute{VA_POSITION3, VA_UV}}
+	VF_POS3_UV2_COLOR4  = VertexFormat{Attributes: []VertexAttribute{VA_POSITION3, VA_UV, VA_COLOR}}
+	VF_POS3_UV2_NORMAL3 = VertexFormat{Attributes: []VertexAttribute{VA_POSITION3, VA_UV, VA_NORMAL}}
+	// VF_POS2_UV2_COVERAGE is the layout of the polyline strokes built by geometry.Stroke
+	VF_POS2_UV2_COVERAGE = VertexFormat{Attributes: []VertexAttribute{VA_POSITION2, VA_UV, VA_COVERAGE}}
+	// VF_INSTANCE_2D is the per-instance layout of the instanced default shaders
+	VF_INSTANCE_2D = VertexFormat{Attributes: []VertexAttribute{VA_INSTANCE_TRANSFORM0, VA_INSTANCE_TRANSFORM1, VA_INSTANCE_COLOR, VA_INSTANCE_UV_RECT}}
+)
+
+// Stride returns the size of a vertex in bytes.
+func (f VertexFormat) Stride() int {
+	stride := 0
+	for _, attribute := range f.Attributes {
+		stride += attribute.Size()
+	}
+
+	return stride
+}
+
+// Attribute returns the attribute with the name.
+func (f VertexFormat) Attribute(name string) (VertexAttribute, bool) {
+	for _, attribute := range f.Attributes {
+		if attribute.Name == name {
+			return attribute, true
+		}
+	}
+
+	return VertexAttribute{}, false
+}
+
+// Validate checks the format has attributes with unique locations and valid component counts.
+func (f VertexFormat) Validate() error {
+	if len(f.Attributes) == 0 {
+		return errors.New("vertex format has no attributes")
+	}
+
+	locations := make(map[uint32]string)
+	for _, attribute := range f.Attributes {
+		if attribute.Components < 1 || attribute.Components > 4 {
+			return fmt.Errorf("attribute %q has %d components", attribute.Name, attribute.Components)
+		}
+
+		if attribute.Integer && (attribute.Type == AT_FLOAT || attribute.Type == AT_HALF_FLOAT) {
+			return fmt.Errorf("integer attribute %q has a float type", attribute.Name)
+		}
+
+		if other, ok := locations[attribute.Location]; ok {
+			return fmt.Errorf("attributes %q and %q share location %d", other, attribute.Name, attribute.Location)
+		}
+		locations[attribute.Location] = attribute.Name
+	}
+
+	return nil
+}
+
+// VertexCount returns the number of vertices in the buffers, they have to match the format layout.
+func (f VertexFormat) VertexCount(buffers [][]byte) (int, error) {
+	switch f.Layout {
+	case VL_INTERLEAVED:
+		if len(buffers) != 1 {
+			return 0, fmt.Errorf("interleaved vertex format expects 1 buffer, got %d", len(buffers))
+		}
+		if len(buffers[0])%f.Stride() != 0 {
+			return 0, fmt.Errorf("vertex buffer size %d is not a multiple of the %d bytes stride", len(buffers[0]), f.Stride())
+		}
+		return len(buffers[0]) / f.Stride(), nil
+	case VL_SEPARATE:
+		if len(buffers) != len(f.Attributes) {
+			return 0, fmt.Errorf("separate vertex format expects %d buffers, got %d", len(f.Attributes), len(buffers))
+		}
+
+		count := -1
+		for i, attribute := range f.Attributes {
+			if len(buffers[i])%attribute.Size() != 0 || (count >= 0 && len(buffers[i])/attribute.Size() != count) {
+				return 0, fmt.Errorf("buffer of attribute %q doesn't match the vertex count", attribute.Name)
+			}
+			count = len(buffers[i]) / attribute.Size()
+		}
+		return count, nil
+	default:
+		return 0, errors.New("unsuported vertex layout")
+	}
+}
+
+// bindAttributes sets up the attribute pointers of the bound vertex array,
+// vbos holds the single interleaved buffer or one buffer per attribute.
+func (f VertexFormat) bindAttributes(vbos []uint32) {
+	offset := 0
+	for i, attribute := range f.Attributes {
+		stride := f.Stride()
+		if f.Layout == VL_SEPARATE {
+			gl.BindBuffer(gl.ARRAY_BUFFER, vbos[i])
+			stride, offset = attribute.Size(), 0
+		} else {
+			gl.BindBuffer(gl.ARRAY_BUFFER, vbos[0])
+		}
+
+		if attribute.Integer {
+			gl.VertexAttribIPointer(attribute.Location, attribute.Components, attribute.Type.glType(), int32(stride), gl.PtrOffset(offset))
+		} else {
+			gl.VertexAttribPointer(attribute.Location, attribute.Components, attribute.Type.glType(), attribute.Normalized, int32(stride), gl.PtrOffset(offset))
+		}
+		gl.EnableVertexAttribArray(attribute.Location)
+		gl.VertexAttribDivisor(attribute.Location, attribute.Divisor)
+
+		offset += attribute.Size()
+	}
+}
+
+// enableAttributes enables or disables the attributes of the bound vertex array.
+func (f VertexFormat) enableAttributes(enabled bool) {
+	for _, attribute := range f.Attributes {
+		if enabled {
+			gl.EnableVertexAttribArray(attribute.Location)
+		} else {
+			gl.DisableVertexAttribArray(attribute.Location)
+		}
+	}
+}
+
+// Float32Bytes returns the float slice memory as bytes, without copying it.
+func Float32Bytes(data []float32) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return (*[1 << 30]byte)(unsafe.Pointer(&data[0]))[: len(data)*4 : len(data)*4]
+}
+
+func Uint16Bytes(data []uint16) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return (*[1 << 30]byte)(unsafe.Pointer(&data[0]))[: len(data)*2 : len(data)*2]
+}
+
+func Uint32Bytes(data []uint32) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return (*[1 << 30]byte)(unsafe.Pointer(&data[0]))[: len(data)*4 : len(data)*4]
+}
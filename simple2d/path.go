@@ -0,0 +1,53 @@
+package simple2d
+
+import (
+	"math"
+
+	"github.com/ddomurad/goCraft/core"
+	"github.com/ddomurad/goCraft/geometry"
+)
+
+// Path is a shape made of lines, bezier curves and arcs, drawn with FillPath and StrokePath.
+type Path = geometry.Path
+
+// pathTolerance is the max distance between the flattened path and its curves, in pixels
+const pathTolerance = 0.25
+
+func NewPath() *Path {
+	return geometry.NewPath()
+}
+
+// ParsePath builds the path from SVG path data, the "d" attribute. SVG has the y axis pointing down,
+// flip the path with Transformed to draw it as designed.
+func ParsePath(d string) (*Path, error) {
+	return geometry.ParseSVGPath(d)
+}
+
+// FillPath fills the path given in world coordinates, with the even-odd rule.
+// The curves are flattened for the camera zoom, so they stay smooth when zoomed in.
+func (r *Renderer2d) FillPath(path *Path, color core.Color) error {
+	for _, polygon := range path.Polygons(r.flatteningTolerance(), geometry.FR_EVEN_ODD) {
+		if err := r.DrawPolygon(polygon, color); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StrokePath strokes the path given in world coordinates, the style lengths are in world units.
+func (r *Renderer2d) StrokePath(path *Path, style geometry.StrokeStyle, color core.Color) error {
+	vertices, indices := path.Stroke(r.flatteningTolerance(), style)
+	return r.drawStroke(vertices, indices, color)
+}
+
+// flatteningTolerance returns the flattening tolerance in world units, rounded down to a power of two
+// so zooming doesn't produce a new cached polygon mesh every frame.
+func (r *Renderer2d) flatteningTolerance() float32 {
+	tolerance := float64(pathTolerance * r.PixelSize())
+	if tolerance <= 0 {
+		return 0
+	}
+
+	return float32(math.Exp2(math.Floor(math.Log2(tolerance))))
+}
@@ -0,0 +1,138 @@
+package simple2d
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+
+	"github.com/ddomurad/goCraft/geometry"
+	"github.com/ddomurad/goCraft/resource"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// polygonCacheFrames is the number of frames a cached polygon mesh is kept without being drawn
+const polygonCacheFrames = 120
+
+// polygonCacheEntry holds the mesh of a polygon, or the error of its triangulation
+// so failing polygons aren't triangulated again every frame.
+type polygonCacheEntry struct {
+	polygon  geometry.Polygon
+	mesh     resource.MeshData
+	err      error
+	lastUsed uint64
+}
+
+// polygonCache keeps the meshes of the drawn polygons, keyed by a hash of the polygon points.
+// Polygons with the same hash share the key, they are told apart by their points.
+type polygonCache struct {
+	entries map[uint64][]*polygonCacheEntry
+	frame   uint64
+}
+
+func newPolygonCache() polygonCache {
+	return polygonCache{
+		entries: make(map[uint64][]*polygonCacheEntry),
+	}
+}
+
+func (c *polygonCache) get(polygon geometry.Polygon) (resource.MeshData, error) {
+	key := hashPolygon(polygon)
+	for _, entry := range c.entries[key] {
+		if equalPolygons(entry.polygon, polygon) {
+			entry.lastUsed = c.frame
+			return entry.mesh, entry.err
+		}
+	}
+
+	entry := &polygonCacheEntry{polygon: copyPolygon(polygon), lastUsed: c.frame}
+	c.entries[key] = append(c.entries[key], entry)
+
+	vertices, indices, err := polygon.Mesh2d()
+	if err != nil {
+		entry.err = err
+		return resource.MeshData{}, err
+	}
+
+	entry.mesh, entry.err = resource.CreateMesh(resource.VF_POS3_UV2, [][]byte{resource.Float32Bytes(vertices)}, indices, gl.TRIANGLES)
+	return entry.mesh, entry.err
+}
+
+// evictUnused unloads the meshes not drawn recently, it's called once per frame.
+func (c *polygonCache) evictUnused() {
+	for key, entries := range c.entries {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if c.frame-entry.lastUsed <= polygonCacheFrames {
+				kept = append(kept, entry)
+			} else if entry.err == nil {
+				entry.mesh.Unload()
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(c.entries, key)
+		} else {
+			c.entries[key] = kept
+		}
+	}
+
+	c.frame++
+}
+
+// copyPolygon copies the rings, the cached polygon can't change with the one passed by the caller.
+func copyPolygon(polygon geometry.Polygon) geometry.Polygon {
+	copied := geometry.Polygon{Outline: append([]mgl32.Vec2{}, polygon.Outline...)}
+	for _, hole := range polygon.Holes {
+		copied.Holes = append(copied.Holes, append([]mgl32.Vec2{}, hole...))
+	}
+
+	return copied
+}
+
+func equalPolygons(a, b geometry.Polygon) bool {
+	equalRings := func(a, b []mgl32.Vec2) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	if len(a.Holes) != len(b.Holes) || !equalRings(a.Outline, b.Outline) {
+		return false
+	}
+	for i := range a.Holes {
+		if !equalRings(a.Holes[i], b.Holes[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hashPolygon(polygon geometry.Polygon) uint64 {
+	hash := fnv.New64a()
+	var buffer [8]byte
+
+	writeRing := func(ring []mgl32.Vec2) {
+		binary.LittleEndian.PutUint64(buffer[:], uint64(len(ring)))
+		hash.Write(buffer[:])
+		for _, point := range ring {
+			binary.LittleEndian.PutUint32(buffer[:4], math.Float32bits(point[0]))
+			binary.LittleEndian.PutUint32(buffer[4:], math.Float32bits(point[1]))
+			hash.Write(buffer[:])
+		}
+	}
+
+	writeRing(polygon.Outline)
+	for _, hole := range polygon.Holes {
+		writeRing(hole)
+	}
+
+	return hash.Sum64()
+}
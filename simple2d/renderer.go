@@ -1,9 +1,11 @@
 package simple2d
 
 import (
-	"unsafe"
+	"log"
+	"math"
 
 	"github.com/ddomurad/goCraft/core"
+	"github.com/ddomurad/goCraft/geometry"
 	"github.com/ddomurad/goCraft/resource"
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
@@ -12,10 +14,15 @@ import (
 const (
 	DRI_MESH_QUAD             = "default_quad_mesh"
 	DRI_MESH_CIRCLE           = "default_circle_mesh"
-	DRI_MESH_QUAD_BORDER      = "default_quad_border_mesh"
-	DRI_MESH_CIRCLE_BORDER    = "default_circle_border_mesh"
+	DRI_MESH_STROKE           = "default_stroke_mesh"
 	DRI_SHADER_SIMPLE         = "default_simple_shader_program"
 	DRI_SHADER_SIMPLE_TEXTURE = "default_simple_texture_shader_program"
+	DRI_SHADER_STROKE         = "default_stroke_shader_program"
+	DRI_SHADER_VERTEX_COLOR   = "default_vertex_color_shader_program"
+	// the instanced variants of the simple shaders, used by DrawInstanced
+	DRI_SHADER_SIMPLE_INSTANCED         = "default_simple_instanced_shader_program"
+	DRI_SHADER_SIMPLE_TEXTURE_INSTANCED = "default_simple_texture_instanced_shader_program"
+	DRI_TEXTURE_WHITE                   = "default_white_texture"
 )
 
 type Scene2d interface {
@@ -29,32 +36,56 @@ type Renderer2d struct {
 	activeShaderProgram resource.ShaderData
 	quadMesh            resource.MeshData
 	circleMesh          resource.MeshData
-	quadBorderMesh      resource.MeshData
-	circleBorderMesh    resource.MeshData
-	projectionMatrix    mgl32.Mat4
-	activeViewMatrix    mgl32.Mat4
-	alphaEnabled        bool
-	updateNeeded        bool
-	app                 *core.App
+	strokeShader        resource.ShaderData
+	vertexColorShader   resource.ShaderData
+	// instancedShaders maps the default shader programs to their instanced variants
+	instancedShaders   map[uint32]resource.ShaderData
+	instanceBuffer     *resource.InstanceBuffer
+	instanceData       []float32
+	circleSegments     uint
+	projectionMatrix   mgl32.Mat4
+	activeViewMatrix   mgl32.Mat4
+	activeUVRect       [4]float32
+	alphaEnabled       bool
+	premultipliedAlpha bool
+	// texturePremultiplied is the alpha mode of the bound texture, used by the textured draws only
+	texturePremultiplied bool
+	updateNeeded         bool
+	app                  *core.App
+	scope                *core.ResourceScope
+	polygonCache         polygonCache
 }
 
 func (r *Renderer2d) Render(dt float64, app *core.App) {
 	if r.updateNeeded {
-		gl.ClearColor(r.clearColor[0], r.clearColor[1], r.clearColor[2], r.clearColor[3])
+		clearColor := r.clearColor
+		if app.Window.SRGB {
+			clearColor = clearColor.ToLinear()
+		}
+		gl.ClearColor(clearColor[0], clearColor[1], clearColor[2], clearColor[3])
 
 		if r.activeShaderProgram.ProgramId == 0 {
-			r.activeShaderProgram = app.ResourceManager.GetResource(DRI_SHADER_SIMPLE).Data.(resource.ShaderData)
+			r.activeShaderProgram = r.getShader(DRI_SHADER_SIMPLE)
 			gl.UseProgram(r.activeShaderProgram.ProgramId)
 		}
 
-		r.quadMesh = app.ResourceManager.GetResource(DRI_MESH_QUAD).Data.(resource.MeshData)
-		r.circleMesh = app.ResourceManager.GetResource(DRI_MESH_CIRCLE).Data.(resource.MeshData)
-		r.quadBorderMesh = app.ResourceManager.GetResource(DRI_MESH_QUAD_BORDER).Data.(resource.MeshData)
-		r.circleBorderMesh = app.ResourceManager.GetResource(DRI_MESH_CIRCLE_BORDER).Data.(resource.MeshData)
+		r.quadMesh = r.getMesh(DRI_MESH_QUAD)
+		r.circleMesh = r.getMesh(DRI_MESH_CIRCLE)
+		r.strokeShader = r.getShader(DRI_SHADER_STROKE)
+		r.vertexColorShader = r.getShader(DRI_SHADER_VERTEX_COLOR)
+		// the vertex colors are converted to linear light like the tint on sRGB framebuffers
+		gl.UseProgram(r.vertexColorShader.ProgramId)
+		gl.Uniform1i(r.vertexColorShader.GetUniformLocation("uLinear"), core.IfThenElse(app.Window.SRGB, int32(1), int32(0)).(int32))
+		gl.UseProgram(r.activeShaderProgram.ProgramId)
+
+		r.instancedShaders = map[uint32]resource.ShaderData{
+			r.getShader(DRI_SHADER_SIMPLE).ProgramId:         r.getShader(DRI_SHADER_SIMPLE_INSTANCED),
+			r.getShader(DRI_SHADER_SIMPLE_TEXTURE).ProgramId: r.getShader(DRI_SHADER_SIMPLE_TEXTURE_INSTANCED),
+		}
 
 		if r.alphaEnabled {
 			gl.Enable(gl.BLEND)
-			gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+			r.applyBlendFunc()
 		} else {
 			gl.Disable(gl.BLEND)
 		}
@@ -69,13 +100,17 @@ func (r *Renderer2d) Render(dt float64, app *core.App) {
 
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 	r.scene.Render(dt, r, app)
+	r.polygonCache.evictUnused()
 }
 
 func NewRenderer2d(app *core.App, scene Scene2d) *Renderer2d {
 	return &Renderer2d{
-		scene:        scene,
-		updateNeeded: true,
-		app:          app,
+		scene:          scene,
+		updateNeeded:   true,
+		app:            app,
+		activeUVRect:   [4]float32{0, 0, 1, 1},
+		circleSegments: defaultCircleSegments,
+		polygonCache:   newPolygonCache(),
 	}
 }
 
@@ -88,20 +123,62 @@ func (r *Renderer2d) HandleEvent(e core.Event) bool {
 }
 
 func (r *Renderer2d) Init() {
-	r.app.ResourceManager.
-		AddLoader(resource.NewFileTextureLoader()).
-		AddLoader(resource.NewShaderLoader()).
+	resource.MountEmbededResources(r.app.VFS)
+
+	resource.RegisterDefaultFormats(r.app.ResourceManager).
+		SetPlaceholder(resource.RT_TEXTURE, resource.CreateErrorTexture).
+		SetPlaceholder(resource.RT_SHADER, resource.CreateErrorShader).
+		SetPlaceholder(resource.RT_MESH, resource.CreateErrorMesh).
+		AddLoader(resource.NewFileTextureLoader(r.app.VFS)).
+		AddLoader(resource.NewDynamicTextureLoader()).
+		AddLoader(resource.NewMemoryTextureLoader()).
+		AddLoader(resource.NewProceduralTextureLoader()).
+		AddLoader(resource.NewTextureArrayLoader(r.app.VFS)).
+		AddLoader(resource.NewCubemapLoader(r.app.VFS)).
+		AddLoader(resource.NewShaderLoader(r.app.VFS)).
 		AddLoader(resource.NewProceduralMesh2dLoader()).
+		AddLoader(resource.NewDynamicMeshLoader(r.app.ResourceManager)).
+		AddLoader(resource.NewObjMeshLoader(r.app.VFS)).
+		AddLoader(resource.NewSvgMeshLoader(r.app.VFS)).
+		AddLoader(resource.NewSubTextureLoader(r.app.ResourceManager)).
+		AddLoader(resource.NewSpriteSheetLoader(r.app.ResourceManager)).
 		PreloadReource(resource.RT_MESH, DRI_MESH_QUAD, resource.PMT_QUAD).
-		PreloadReource(resource.RT_MESH, DRI_MESH_CIRCLE, resource.PMT_CIRCLE).
-		PreloadReource(resource.RT_MESH, DRI_MESH_QUAD_BORDER, resource.PMT_QUAD_BORDER).
-		PreloadReource(resource.RT_MESH, DRI_MESH_CIRCLE_BORDER, resource.PMT_CIRCLE_BORDER).
+		PreloadReource(resource.RT_MESH, DRI_MESH_CIRCLE, circleMeshParams(defaultCircleSegments)).
+		PreloadReource(resource.RT_MESH, DRI_MESH_STROKE, resource.DynamicMeshParams{
+			Format:         resource.VF_POS2_UV2_COVERAGE,
+			Drawing:        gl.TRIANGLES,
+			VertexCapacity: 256,
+			IndexCapacity:  1024,
+		}).
 		PreloadReource(resource.RT_SHADER, DRI_SHADER_SIMPLE, resource.EmbededShaderSource{
 			ShaderName: "simple",
 		}).
 		PreloadReource(resource.RT_SHADER, DRI_SHADER_SIMPLE_TEXTURE, resource.EmbededShaderSource{
 			ShaderName: "simple_texture",
-		})
+		}).
+		PreloadReource(resource.RT_SHADER, DRI_SHADER_STROKE, resource.EmbededShaderSource{
+			ShaderName: "stroke",
+		}).
+		PreloadReource(resource.RT_SHADER, DRI_SHADER_VERTEX_COLOR, resource.EmbededShaderSource{
+			ShaderName: "vertex_color",
+		}).
+		PreloadReource(resource.RT_SHADER, DRI_SHADER_SIMPLE_INSTANCED, resource.EmbededShaderSource{
+			ShaderName: "simple_instanced",
+		}).
+		PreloadReource(resource.RT_SHADER, DRI_SHADER_SIMPLE_TEXTURE_INSTANCED, resource.EmbededShaderSource{
+			ShaderName: "simple_texture_instanced",
+		}).
+		PreloadReource(resource.RT_TEXTURE, DRI_TEXTURE_WHITE, resource.WhiteTextureParams{}).
+		Pin(DRI_MESH_QUAD).
+		Pin(DRI_MESH_CIRCLE).
+		Pin(DRI_MESH_STROKE).
+		Pin(DRI_SHADER_SIMPLE).
+		Pin(DRI_SHADER_SIMPLE_TEXTURE).
+		Pin(DRI_SHADER_STROKE).
+		Pin(DRI_SHADER_VERTEX_COLOR).
+		Pin(DRI_SHADER_SIMPLE_INSTANCED).
+		Pin(DRI_SHADER_SIMPLE_TEXTURE_INSTANCED).
+		Pin(DRI_TEXTURE_WHITE)
 
 	// PreloadReource(resource.RT_SHADER, DRI_SHADER_PROGRAM, resource.ShaderFileSource{
 	// 	VertexShaderPath:   "/home/work/Projects/goCraftProject/goCraftTestApp/res/shader.vs",
@@ -119,21 +196,34 @@ func (r *Renderer2d) SetWirframe(enable bool) {
 	}
 }
 
+// SetCrictleSegments changes the tessellation of the ellipses, the meshes are updated in place.
 func (r *Renderer2d) SetCrictleSegments(segments uint) {
-	resourceManager := r.app.ResourceManager
-	mesh := resourceManager.GetResource(DRI_MESH_CIRCLE)
-	mesh.Unload()
+	if segments < 3 {
+		segments = 3
+	}
+	r.circleSegments = segments
 
-	borderMesh := resourceManager.GetResource(DRI_MESH_CIRCLE_BORDER)
-	borderMesh.Unload()
+	if mesh, err := resource.GetDynamicMesh(r.app.ResourceManager, DRI_MESH_CIRCLE); err == nil {
+		vertexData, indexData, _ := resource.GetCircleVertices(segments)
+		if err := mesh.Update(resource.Float32Bytes(vertexData), indexData); err != nil {
+			log.Printf("failed to update the circle mesh: %q\n", err)
+		}
+	}
 
-	vertexData, indexData, drawingType := resource.GetCircleVertices(segments)
-	meshReshource, _ := resource.CreateMesh2dResource(DRI_MESH_CIRCLE, vertexData, indexData, drawingType)
-	resourceManager.AddResource(meshReshource)
+	// the renderer holds copies of the mesh data
+	r.updateNeeded = true
+}
 
-	vertexBorderData, indexBorderData, drawingBorderType := resource.GetCircleBorderVertices(segments)
-	meshBorderReshource, _ := resource.CreateMesh2dResource(DRI_MESH_CIRCLE_BORDER, vertexBorderData, indexBorderData, drawingBorderType)
-	resourceManager.AddResource(meshBorderReshource)
+const defaultCircleSegments = 24
+
+func circleMeshParams(segments uint) resource.DynamicMeshParams {
+	vertexData, indexData, drawingType := resource.GetCircleVertices(segments)
+	return resource.DynamicMeshParams{
+		Format:   resource.VF_POS3_UV2,
+		Drawing:  drawingType,
+		Vertices: resource.Float32Bytes(vertexData),
+		Indices:  indexData,
+	}
 }
 
 func (r *Renderer2d) SetClearColor(color core.Color) {
@@ -141,27 +231,128 @@ func (r *Renderer2d) SetClearColor(color core.Color) {
 	r.updateNeeded = true
 }
 
-func (r *Renderer2d) SetShader(uri string) {
-	r.activeShaderProgram = r.app.ResourceManager.GetResource(uri).Data.(resource.ShaderData)
+// SetShader activates the shader program. If the shader can't be found,
+// the shader placeholder is activated instead and the error is returned.
+func (r *Renderer2d) SetShader(uri string) error {
+	shaderData, err := resource.GetShader(r.app.ResourceManager, r.lookup(uri))
+
+	r.useShader(shaderData)
+	return err
+}
+
+// SetShaderHandle is the handle based variant of SetShader, meant for hot paths.
+func (r *Renderer2d) SetShaderHandle(handle resource.ShaderHandle) error {
+	shaderData, err := resource.ResolveShader(r.app.ResourceManager, handle)
+
+	r.useShader(shaderData)
+	return err
+}
+
+func (r *Renderer2d) useShader(shaderData resource.ShaderData) {
+	r.activeShaderProgram = shaderData
 	gl.UseProgram(r.activeShaderProgram.ProgramId)
 	r.activeShaderProgram.SetProjectionMat(r.projectionMatrix)
 	r.activeShaderProgram.SetViewMat(r.activeViewMatrix)
+	r.activeShaderProgram.SetUVRect(r.activeUVRect)
 }
 
 func (r *Renderer2d) GetShader() resource.ShaderData {
 	return r.activeShaderProgram
 }
 
-func (r *Renderer2d) SetTexture(uri string) {
-	textureData := r.app.ResourceManager.GetResource(uri).Data.(resource.TextureData)
+// SetTexture binds the texture. If the texture can't be found,
+// the texture placeholder is bound instead and the error is returned.
+func (r *Renderer2d) SetTexture(uri string) error {
+	textureData, err := resource.GetTexture(r.app.ResourceManager, r.lookup(uri))
+	r.bindTexture(textureData)
+	return err
+}
+
+// SetTextureHandle is the handle based variant of SetTexture, meant for hot paths.
+func (r *Renderer2d) SetTextureHandle(handle resource.TextureHandle) error {
+	textureData, err := resource.ResolveTexture(r.app.ResourceManager, handle)
+	r.bindTexture(textureData)
+	return err
+}
+
+// SetSpriteFrame binds the sprite sheet texture and maps the texture coordinates to the frame.
+func (r *Renderer2d) SetSpriteFrame(frame resource.SpriteFrame) {
+	r.bindTexture(frame.Texture)
+}
+
+// bindTexture binds the texture and maps the texture coordinates to its region,
+// so atlas sub textures draw like standalone textures.
+func (r *Renderer2d) bindTexture(textureData resource.TextureData) {
 	gl.BindTexture(gl.TEXTURE_2D, textureData.Id)
+	r.activeUVRect = textureData.GetUVRect()
+	r.activeShaderProgram.SetUVRect(r.activeUVRect)
+
+	r.texturePremultiplied = textureData.Premultiplied
+}
+
+// useAlphaMode switches to the alpha mode of the bound texture for textured draws,
+// untextured draws always use straight alpha.
+func (r *Renderer2d) useAlphaMode(textured bool) {
+	premultiplied := textured && r.texturePremultiplied
+	if r.premultipliedAlpha != premultiplied {
+		r.premultipliedAlpha = premultiplied
+		if r.alphaEnabled {
+			r.applyBlendFunc()
+		}
+	}
 }
 
+// activeShaderTextured reports whether the active shader samples the bound texture.
+func (r *Renderer2d) activeShaderTextured() bool {
+	return r.activeShaderProgram.Textured()
+}
+
+// applyBlendFunc selects the blend function matching the current alpha mode.
+func (r *Renderer2d) applyBlendFunc() {
+	if r.premultipliedAlpha {
+		gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+	} else {
+		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	}
+}
+
+// setColor sets the draw color of the active shader, converting the sRGB color to linear light when
+// the framebuffer is sRGB and premultiplying it when the shader samples a texture with premultiplied alpha.
+func (r *Renderer2d) setColor(color core.Color) {
+	r.useAlphaMode(r.activeShaderTextured())
+	r.activeShaderProgram.SetColor(r.drawColor(color))
+}
+
+func (r *Renderer2d) drawColor(color core.Color) core.Color {
+	if r.app.Window.SRGB {
+		color = color.ToLinear()
+	}
+	if r.premultipliedAlpha {
+		color = color.Premultiplied()
+	}
+	return color
+}
+
+// SetScope makes resource uris passed to the renderer resolve through the scope.
+// Pass nil to use the global namespace only.
+func (r *Renderer2d) SetScope(scope *core.ResourceScope) {
+	r.scope = scope
+}
+
+// SetAlpha enables blending. Textured draws follow the alpha mode of the bound texture,
+// untextured draws use straight alpha.
 func (r *Renderer2d) SetAlpha(enabled bool) {
 	r.alphaEnabled = enabled
 	r.updateNeeded = true
 }
 
+// SetSRGB enables the sRGB framebuffer. Colors passed to the renderer are still sRGB,
+// they are converted to linear light so blending happens in linear space.
+func (r *Renderer2d) SetSRGB(enabled bool) {
+	r.app.SetSRGBFramebuffer(enabled)
+	r.updateNeeded = true
+}
+
 func (r *Renderer2d) ApplyCamera(camera *Camera2d) {
 	r.activeViewMatrix = camera.GetViewMatrix()
 	r.activeShaderProgram.SetViewMat(r.activeViewMatrix)
@@ -174,26 +365,21 @@ func (r *Renderer2d) DrawRectV(pos, size mgl32.Vec2, rot float32, color core.Col
 func (r *Renderer2d) DrawRect(x, y, w, h, rot float32, color core.Color) {
 	var transformMat = getTransformMattrix(x, y, w, h, rot)
 
-	r.activeShaderProgram.SetColor(color)
+	r.setColor(color)
 	r.activeShaderProgram.SetTransformationMat(transformMat)
 
 	gl.BindVertexArray(r.quadMesh.VAO)
-	gl.DrawElements(r.quadMesh.Drawing, int32(r.quadMesh.VCount), gl.UNSIGNED_INT, unsafe.Pointer(nil))
+	r.quadMesh.DrawElements()
 }
 
 func (r *Renderer2d) DrawRectBorderV(pos, size mgl32.Vec2, rot, width float32, color core.Color) {
 	r.DrawRectBorder(pos.X(), pos.Y(), size.X(), size.Y(), rot, width, color)
 }
 
+// DrawRectBorder strokes the rect outline, the width is in pixels.
 func (r *Renderer2d) DrawRectBorder(x, y, w, h, rot, width float32, color core.Color) {
-	var transformMat = getTransformMattrix(x, y, w, h, rot)
-
-	r.activeShaderProgram.SetColor(color)
-	r.activeShaderProgram.SetTransformationMat(transformMat)
-
-	gl.LineWidth(width)
-	gl.BindVertexArray(r.quadBorderMesh.VAO)
-	gl.DrawElements(r.quadBorderMesh.Drawing, int32(r.quadBorderMesh.VCount), gl.UNSIGNED_INT, unsafe.Pointer(nil))
+	outline := []mgl32.Vec2{{-0.5, -0.5}, {0.5, -0.5}, {0.5, 0.5}, {-0.5, 0.5}}
+	r.drawBorder(outline, getTransformMattrix(x, y, w, h, rot), width, color)
 }
 
 func (r *Renderer2d) DrawElipseV(pos, size mgl32.Vec2, rot float32, color core.Color) {
@@ -203,22 +389,267 @@ func (r *Renderer2d) DrawElipseV(pos, size mgl32.Vec2, rot float32, color core.C
 func (r *Renderer2d) DrawElipse(x, y, w, h, rot float32, color core.Color) {
 	var transformMat = getTransformMattrix(x, y, w, h, rot)
 
-	r.activeShaderProgram.SetColor(color)
+	r.setColor(color)
 	r.activeShaderProgram.SetTransformationMat(transformMat)
 
 	gl.BindVertexArray(r.circleMesh.VAO)
-	gl.DrawElements(r.circleMesh.Drawing, int32(r.circleMesh.VCount), gl.UNSIGNED_INT, unsafe.Pointer(nil))
+	r.circleMesh.DrawElements()
 }
 
+// DrawElipseBorder strokes the ellipse outline, the width is in pixels.
 func (r *Renderer2d) DrawElipseBorder(x, y, w, h, rot, width float32, color core.Color) {
+	outline := make([]mgl32.Vec2, r.circleSegments)
+	for i := range outline {
+		angle := 2 * math.Pi * float64(i) / float64(len(outline))
+		outline[i] = mgl32.Vec2{0.5 * float32(math.Cos(angle)), 0.5 * float32(math.Sin(angle))}
+	}
+
+	r.drawBorder(outline, getTransformMattrix(x, y, w, h, rot), width, color)
+}
+
+func (r *Renderer2d) DrawMeshV(uri string, pos, size mgl32.Vec2, rot float32, color core.Color) error {
+	return r.DrawMesh(uri, pos.X(), pos.Y(), size.X(), size.Y(), rot, color)
+}
+
+// DrawMesh draws a unit sized mesh scaled to the size, like the shapes built from resource.ProceduralMesh2dParams.
+// If the mesh can't be found, the mesh placeholder is drawn instead and the error is returned.
+// Border shape meshes are drawn as 1 pixel hairlines, DrawShapeBorder strokes them with a width.
+func (r *Renderer2d) DrawMesh(uri string, x, y, w, h, rot float32, color core.Color) error {
+	meshData, err := resource.GetMesh(r.app.ResourceManager, r.lookup(uri))
 	var transformMat = getTransformMattrix(x, y, w, h, rot)
 
-	r.activeShaderProgram.SetColor(color)
+	r.setColor(color)
 	r.activeShaderProgram.SetTransformationMat(transformMat)
 
-	gl.LineWidth(width)
-	gl.BindVertexArray(r.circleBorderMesh.VAO)
-	gl.DrawElements(r.circleBorderMesh.Drawing, int32(r.circleBorderMesh.VCount), gl.UNSIGNED_INT, unsafe.Pointer(nil))
+	gl.BindVertexArray(meshData.VAO)
+	meshData.DrawElements()
+	return err
+}
+
+// DrawShapeBorder strokes the outlines of the parameterized shape scaled to the size, the width is in pixels.
+func (r *Renderer2d) DrawShapeBorder(params resource.ProceduralMesh2dParams, x, y, w, h, rot, width float32, color core.Color) error {
+	outlines, err := resource.GetShapeOutlines(params)
+	if err != nil {
+		return err
+	}
+
+	transformMat := getTransformMattrix(x, y, w, h, rot)
+	for _, outline := range outlines {
+		if err := r.drawBorder(outline, transformMat, width, color); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drawBorder strokes the closed outline after transforming it to world space,
+// so the border width doesn't follow the shape scale.
+func (r *Renderer2d) drawBorder(outline []mgl32.Vec2, transformMat mgl32.Mat4, width float32, color core.Color) error {
+	points := make([]mgl32.Vec2, len(outline))
+	for i, p := range outline {
+		points[i] = transformMat.Mul4x1(mgl32.Vec4{p[0], p[1], 0, 1}).Vec2()
+	}
+
+	pixel := r.PixelSize()
+	style := geometry.StrokeStyle{Width: width * pixel}
+	if r.alphaEnabled {
+		// the feather fades out, without blending it would just make the border wider
+		style.Feather = pixel
+	}
+
+	return r.DrawPolyline(points, true, style, color)
+}
+
+// DrawPolyline strokes the polyline given in world coordinates, the style lengths are in world units.
+// Use PixelSize to get pixel sized widths and, with alpha enabled, a one pixel Feather for anti-aliased lines.
+func (r *Renderer2d) DrawPolyline(points []mgl32.Vec2, closed bool, style geometry.StrokeStyle, color core.Color) error {
+	vertices, indices := geometry.Stroke(points, closed, style)
+	return r.drawStroke(vertices, indices, color)
+}
+
+// drawStroke draws the stroke vertices built by geometry.Stroke.
+func (r *Renderer2d) drawStroke(vertices []float32, indices []uint32, color core.Color) error {
+	if len(indices) == 0 {
+		return nil
+	}
+
+	mesh, err := resource.GetDynamicMesh(r.app.ResourceManager, DRI_MESH_STROKE)
+	if err != nil {
+		return err
+	}
+
+	if err := mesh.Update(resource.Float32Bytes(vertices), indices); err != nil {
+		return err
+	}
+
+	// strokes have their own shader, for the coverage
+	r.useAlphaMode(false)
+	r.useBuiltinShader(&r.strokeShader, mgl32.Ident4(), r.drawColor(color))
+	gl.BindVertexArray(mesh.VAO)
+	mesh.DrawElements()
+
+	gl.UseProgram(r.activeShaderProgram.ProgramId)
+	return nil
+}
+
+func (r *Renderer2d) DrawColoredMeshV(uri string, pos, size mgl32.Vec2, rot float32, tint core.Color) error {
+	return r.DrawColoredMesh(uri, pos.X(), pos.Y(), size.X(), size.Y(), rot, tint)
+}
+
+// DrawColoredMesh draws a unit sized mesh with vertex colors, like the meshes loaded from SVG files,
+// multiplied by the tint. If the mesh can't be found, the mesh placeholder is drawn instead and the error is returned.
+func (r *Renderer2d) DrawColoredMesh(uri string, x, y, w, h, rot float32, tint core.Color) error {
+	meshData, err := resource.GetMesh(r.app.ResourceManager, r.lookup(uri))
+
+	r.useAlphaMode(false)
+	r.useBuiltinShader(&r.vertexColorShader, getTransformMattrix(x, y, w, h, rot), r.drawColor(tint))
+
+	gl.BindVertexArray(meshData.VAO)
+	meshData.DrawElements()
+
+	gl.UseProgram(r.activeShaderProgram.ProgramId)
+	return err
+}
+
+// Instance2d is a single instance drawn by DrawInstanced, placed like the DrawRect arguments.
+type Instance2d struct {
+	Position mgl32.Vec2
+	Size     mgl32.Vec2
+	Rotation float32
+	Color    core.Color
+	// UVRect is the (u, v, width, height) texture region, the bound texture region when zero
+	UVRect [4]float32
+}
+
+// DrawInstanced draws the unit sized mesh once per instance, with a single draw call.
+// The default shaders are replaced by their instanced variants, custom shaders are used as they are
+// and can read the resource.VF_INSTANCE_2D attributes. If the mesh can't be found,
+// the mesh placeholder is drawn instead and the error is returned.
+func (r *Renderer2d) DrawInstanced(uri string, instances []Instance2d) error {
+	meshData, err := resource.GetMesh(r.app.ResourceManager, r.lookup(uri))
+	if len(instances) == 0 {
+		return err
+	}
+
+	if r.instanceBuffer == nil {
+		buffer, err := resource.NewInstanceBuffer(resource.VF_INSTANCE_2D)
+		if err != nil {
+			return err
+		}
+		r.instanceBuffer = buffer
+	}
+
+	r.useAlphaMode(r.activeShaderTextured())
+	data := r.instanceData[:0]
+	for _, instance := range instances {
+		sin, cos := math.Sincos(float64(instance.Rotation))
+		w, h := instance.Size[0], instance.Size[1]
+		color := r.drawColor(instance.Color)
+		uvRect := instance.UVRect
+		if uvRect == [4]float32{} {
+			uvRect = r.activeUVRect
+		}
+
+		// the rows of the translation * rotation * scale matrix
+		data = append(data,
+			w*float32(cos), -h*float32(sin), instance.Position[0],
+			w*float32(sin), h*float32(cos), instance.Position[1],
+			color[0], color[1], color[2], color[3],
+			uvRect[0], uvRect[1], uvRect[2], uvRect[3])
+	}
+	r.instanceData = data
+
+	if err := r.instanceBuffer.Update(resource.Float32Bytes(data)); err != nil {
+		return err
+	}
+
+	// sets up the instance attributes on the first draw of the mesh, or after it's reloaded
+	if err := meshData.AttachInstances(r.instanceBuffer); err != nil {
+		return err
+	}
+
+	instancedShader, ok := r.instancedShaders[r.activeShaderProgram.ProgramId]
+	if ok {
+		gl.UseProgram(instancedShader.ProgramId)
+		instancedShader.SetProjectionMat(r.projectionMatrix)
+		instancedShader.SetViewMat(r.activeViewMatrix)
+	}
+
+	gl.BindVertexArray(meshData.VAO)
+	meshData.DrawElementsInstanced()
+
+	if ok {
+		gl.UseProgram(r.activeShaderProgram.ProgramId)
+	}
+	return err
+}
+
+// useBuiltinShader activates one of the renderer shaders for a single draw,
+// the active shader has to be restored after it.
+func (r *Renderer2d) useBuiltinShader(shader *resource.ShaderData, transformMat mgl32.Mat4, color core.Color) {
+	gl.UseProgram(shader.ProgramId)
+	shader.SetProjectionMat(r.projectionMatrix)
+	shader.SetViewMat(r.activeViewMatrix)
+	shader.SetTransformationMat(transformMat)
+	shader.SetColor(color)
+}
+
+// PixelSize returns the size of a screen pixel in world units, with the applied camera.
+func (r *Renderer2d) PixelSize() float32 {
+	// the projection maps the window height to 2 units
+	size := 2 / float32(r.app.Window.Height)
+
+	scale := r.activeViewMatrix.Col(0).Vec2().Len()
+	if scale == 0 {
+		return size
+	}
+
+	return size / scale
+}
+
+// DrawPolygon fills a concave polygon, with holes, given in world coordinates.
+// The triangulation is cached, so redrawing the same polygon every frame is cheap.
+// Texture coordinates map the polygon bounding box to the whole texture.
+func (r *Renderer2d) DrawPolygon(polygon geometry.Polygon, color core.Color) error {
+	meshData, err := r.polygonCache.get(polygon)
+	if err != nil {
+		return err
+	}
+
+	r.setColor(color)
+	r.activeShaderProgram.SetTransformationMat(mgl32.Ident4())
+
+	gl.BindVertexArray(meshData.VAO)
+	meshData.DrawElements()
+	return nil
+}
+
+func (r *Renderer2d) lookup(uri string) string {
+	if r.scope == nil {
+		return uri
+	}
+
+	scopedUri, _ := r.scope.Lookup(uri)
+	return scopedUri
+}
+
+func (r *Renderer2d) getShader(uri string) resource.ShaderData {
+	shaderData, err := resource.GetShader(r.app.ResourceManager, uri)
+	if err != nil {
+		log.Printf("FAILED! default shader not available: %q\n", err)
+	}
+
+	return shaderData
+}
+
+func (r *Renderer2d) getMesh(uri string) resource.MeshData {
+	meshData, err := resource.GetMesh(r.app.ResourceManager, uri)
+	if err != nil {
+		log.Printf("FAILED! default mesh not available: %q\n", err)
+	}
+
+	return meshData
 }
 
 func getTransformMattrix(x, y, sx, sy, rot float32) mgl32.Mat4 {
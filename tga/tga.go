@@ -0,0 +1,286 @@
+// Package tga implements a Truevision TGA image decoder.
+// Color mapped, true color and grayscale images are supported, raw or RLE compressed.
+//
+// TGA files have no magic number, so the format is registered with the image package
+// using the image type byte of the header, after the formats with a stronger signature.
+package tga
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+const (
+	typeColorMapped    = 1
+	typeTrueColor      = 2
+	typeGrayscale      = 3
+	typeRleColorMapped = 9
+	typeRleTrueColor   = 10
+	typeRleGrayscale   = 11
+)
+
+const (
+	descriptorRightToLeft = 1 << 4
+	descriptorTopToBottom = 1 << 5
+)
+
+type header struct {
+	IdLength        uint8
+	ColorMapType    uint8
+	ImageType       uint8
+	ColorMapOrigin  uint16
+	ColorMapLength  uint16
+	ColorMapDepth   uint8
+	XOrigin         uint16
+	YOrigin         uint16
+	Width           uint16
+	Height          uint16
+	PixelDepth      uint8
+	ImageDescriptor uint8
+}
+
+func init() {
+	image.RegisterFormat("tga", "?\x00\x02", Decode, DecodeConfig)
+	image.RegisterFormat("tga", "?\x00\x03", Decode, DecodeConfig)
+	image.RegisterFormat("tga", "?\x00\x0a", Decode, DecodeConfig)
+	image.RegisterFormat("tga", "?\x00\x0b", Decode, DecodeConfig)
+	image.RegisterFormat("tga", "?\x01\x01", Decode, DecodeConfig)
+	image.RegisterFormat("tga", "?\x01\x09", Decode, DecodeConfig)
+}
+
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	hdr, err := readHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+
+	colorModel := color.NRGBAModel
+	if hdr.baseType() == typeGrayscale {
+		colorModel = color.GrayModel
+	}
+
+	return image.Config{
+		ColorModel: colorModel,
+		Width:      int(hdr.Width),
+		Height:     int(hdr.Height),
+	}, nil
+}
+
+// Decode reads a TGA image. Grayscale images are returned as *image.Gray, all the others as *image.NRGBA.
+func Decode(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+
+	hdr, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := br.Discard(int(hdr.IdLength)); err != nil {
+		return nil, err
+	}
+
+	var palette []color.NRGBA
+	if hdr.ColorMapType == 1 {
+		if palette, err = readColorMap(br, hdr); err != nil {
+			return nil, err
+		}
+	}
+
+	bytesPerPixel := (int(hdr.PixelDepth) + 7) / 8
+	width, height := int(hdr.Width), int(hdr.Height)
+
+	pixels := make([]byte, width*height*bytesPerPixel)
+	if hdr.ImageType >= typeRleColorMapped {
+		err = readRle(br, pixels, bytesPerPixel)
+	} else {
+		_, err = io.ReadFull(br, pixels)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var img image.Image
+	var set func(x, y int, pixel []byte)
+
+	switch hdr.baseType() {
+	case typeGrayscale:
+		gray := image.NewGray(image.Rect(0, 0, width, height))
+		set = func(x, y int, pixel []byte) {
+			gray.Pix[y*gray.Stride+x] = pixel[0]
+		}
+		img = gray
+	case typeColorMapped:
+		nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+		set = func(x, y int, pixel []byte) {
+			index := int(pixel[0])
+			if bytesPerPixel == 2 {
+				index = int(binary.LittleEndian.Uint16(pixel))
+			}
+
+			index -= int(hdr.ColorMapOrigin)
+			if index >= 0 && index < len(palette) {
+				nrgba.SetNRGBA(x, y, palette[index])
+			}
+		}
+		img = nrgba
+	default:
+		nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+		set = func(x, y int, pixel []byte) {
+			nrgba.SetNRGBA(x, y, decodeColor(pixel, int(hdr.PixelDepth), hdr.hasAlpha()))
+		}
+		img = nrgba
+	}
+
+	for row := 0; row < height; row++ {
+		y := height - 1 - row
+		if hdr.ImageDescriptor&descriptorTopToBottom != 0 {
+			y = row
+		}
+
+		for column := 0; column < width; column++ {
+			x := column
+			if hdr.ImageDescriptor&descriptorRightToLeft != 0 {
+				x = width - 1 - column
+			}
+
+			offset := (row*width + column) * bytesPerPixel
+			set(x, y, pixels[offset:offset+bytesPerPixel])
+		}
+	}
+
+	return img, nil
+}
+
+func readHeader(r io.Reader) (header, error) {
+	var hdr header
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return hdr, err
+	}
+
+	if hdr.ColorMapType > 1 {
+		return hdr, errors.New("tga: invalid color map type")
+	}
+
+	switch hdr.baseType() {
+	case typeColorMapped:
+		if hdr.ColorMapType != 1 || (hdr.PixelDepth != 8 && hdr.PixelDepth != 16) {
+			return hdr, fmt.Errorf("tga: unsuported color mapped pixel depth: %d", hdr.PixelDepth)
+		}
+	case typeTrueColor:
+		if hdr.PixelDepth != 15 && hdr.PixelDepth != 16 && hdr.PixelDepth != 24 && hdr.PixelDepth != 32 {
+			return hdr, fmt.Errorf("tga: unsuported true color pixel depth: %d", hdr.PixelDepth)
+		}
+	case typeGrayscale:
+		if hdr.PixelDepth != 8 {
+			return hdr, fmt.Errorf("tga: unsuported grayscale pixel depth: %d", hdr.PixelDepth)
+		}
+	default:
+		return hdr, fmt.Errorf("tga: unsuported image type: %d", hdr.ImageType)
+	}
+
+	if hdr.Width == 0 || hdr.Height == 0 {
+		return hdr, errors.New("tga: empty image")
+	}
+
+	return hdr, nil
+}
+
+func (h header) baseType() uint8 {
+	if h.ImageType >= typeRleColorMapped {
+		return h.ImageType - (typeRleColorMapped - typeColorMapped)
+	}
+
+	return h.ImageType
+}
+
+func (h header) hasAlpha() bool {
+	return h.ImageDescriptor&0x0f != 0
+}
+
+func readColorMap(r io.Reader, hdr header) ([]color.NRGBA, error) {
+	entrySize := (int(hdr.ColorMapDepth) + 7) / 8
+	if entrySize < 2 || entrySize > 4 {
+		return nil, fmt.Errorf("tga: unsuported color map depth: %d", hdr.ColorMapDepth)
+	}
+
+	data := make([]byte, int(hdr.ColorMapLength)*entrySize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	palette := make([]color.NRGBA, hdr.ColorMapLength)
+	for i := range palette {
+		palette[i] = decodeColor(data[i*entrySize:(i+1)*entrySize], int(hdr.ColorMapDepth), hdr.hasAlpha())
+	}
+
+	return palette, nil
+}
+
+// readRle expands run length encoded packets, which may cross scanlines.
+func readRle(r *bufio.Reader, pixels []byte, bytesPerPixel int) error {
+	for offset := 0; offset < len(pixels); {
+		packet, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		count := int(packet&0x7f) + 1
+		if offset+count*bytesPerPixel > len(pixels) {
+			return errors.New("tga: rle packet overflows the image")
+		}
+
+		if packet&0x80 == 0 {
+			if _, err := io.ReadFull(r, pixels[offset:offset+count*bytesPerPixel]); err != nil {
+				return err
+			}
+			offset += count * bytesPerPixel
+			continue
+		}
+
+		pixel := pixels[offset : offset+bytesPerPixel]
+		if _, err := io.ReadFull(r, pixel); err != nil {
+			return err
+		}
+		offset += bytesPerPixel
+
+		for i := 1; i < count; i++ {
+			copy(pixels[offset:], pixel)
+			offset += bytesPerPixel
+		}
+	}
+
+	return nil
+}
+
+// decodeColor converts a little endian BGR(A) pixel.
+// The attribute bit of 16 bit pixels is used as alpha only if the header declares alpha bits.
+func decodeColor(pixel []byte, depth int, alpha bool) color.NRGBA {
+	switch depth {
+	case 15, 16:
+		value := binary.LittleEndian.Uint16(pixel)
+		c := color.NRGBA{
+			R: expand5(value >> 10),
+			G: expand5(value >> 5),
+			B: expand5(value),
+			A: 255,
+		}
+		if alpha && value&0x8000 == 0 {
+			c.A = 0
+		}
+		return c
+	case 24:
+		return color.NRGBA{R: pixel[2], G: pixel[1], B: pixel[0], A: 255}
+	default:
+		return color.NRGBA{R: pixel[2], G: pixel[1], B: pixel[0], A: pixel[3]}
+	}
+}
+
+func expand5(value uint16) uint8 {
+	value &= 0x1f
+	return uint8(value<<3 | value>>2)
+}